@@ -10,14 +10,36 @@ import (
 func (a *Api) ToAzureApiVersionSetContract() *apim.APIVersionSetContract {
 	return &apim.APIVersionSetContract{
 		Properties: &apim.APIVersionSetContractProperties{
-			DisplayName:      &a.Spec.DisplayName,
-			VersioningScheme: a.Spec.VersioningScheme.AzureAPIVersionScheme(),
-			Description:      a.Spec.Description,
+			DisplayName:       &a.Spec.DisplayName,
+			VersioningScheme:  a.Spec.VersioningScheme.AzureAPIVersionScheme(),
+			Description:       a.Spec.Description,
+			VersionHeaderName: a.Spec.VersionHeaderName,
+			VersionQueryName:  a.Spec.VersionQueryName,
 		},
 		Name: a.GetAzureApiName(),
 	}
 }
 
+// RequireVersionSetUpdate returns true if the version set in Azure differs from the Api spec
+func (a *Api) RequireVersionSetUpdate(current *apim.APIVersionSetContractProperties) bool {
+	if current == nil {
+		return true
+	}
+	desired := a.ToAzureApiVersionSetContract().Properties
+	return stringValue(current.DisplayName) != stringValue(desired.DisplayName) ||
+		stringValue(current.Description) != stringValue(desired.Description) ||
+		!pointerValueEqual(current.VersioningScheme, desired.VersioningScheme) ||
+		stringValue(current.VersionHeaderName) != stringValue(desired.VersionHeaderName) ||
+		stringValue(current.VersionQueryName) != stringValue(desired.VersionQueryName)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func (a *Api) GetAzureApiName() *string {
 	name := fmt.Sprintf("%s-%s", a.Namespace, a.Name)
 	return &name
@@ -11,6 +11,16 @@ type ContentFormat string
 const (
 	// ContentFormatGraphqlLink - The GraphQL API endpoint hosted on a publicly accessible internet address.
 	ContentFormatGraphqlLink ContentFormat = "graphql-link"
+	// ContentFormatGrpc - The contents are inline and Content type is a gRPC protobuf file.
+	ContentFormatGrpc ContentFormat = "grpc"
+	// ContentFormatGrpcLink - The gRPC protobuf file is hosted on a publicly accessible internet address.
+	ContentFormatGrpcLink ContentFormat = "grpc-link"
+	// ContentFormatNone - The API is created blank and its operations are defined with ApiOperation resources.
+	ContentFormatNone ContentFormat = "none"
+	// ContentFormatOdata - The contents are inline and Content type is an OData XML document ($metadata).
+	ContentFormatOdata ContentFormat = "odata"
+	// ContentFormatOdataLink - The OData $metadata document is hosted on a publicly accessible internet address.
+	ContentFormatOdataLink ContentFormat = "odata-link"
 	// ContentFormatOpenapi - The contents are inline and Content Type is a OpenAPI 3.0 YAML Document.
 	ContentFormatOpenapi ContentFormat = "openapi"
 	// ContentFormatOpenapiJSON - The contents are inline and Content Type is a OpenAPI 3.0 JSON Document.
@@ -27,6 +37,10 @@ const (
 	ContentFormatWadlLinkJSON ContentFormat = "wadl-link-json"
 	// ContentFormatWadlXML - The contents are inline and Content type is a WADL document.
 	ContentFormatWadlXML ContentFormat = "wadl-xml"
+	// ContentFormatWsdl - The contents are inline and the document is a WSDL/Soap document.
+	ContentFormatWsdl ContentFormat = "wsdl"
+	// ContentFormatWsdlLink - The WSDL document is hosted on a publicly accessible internet address.
+	ContentFormatWsdlLink ContentFormat = "wsdl-link"
 )
 
 func (c ContentFormat) AzureContentFormat() *apim.ContentFormat {
@@ -83,6 +97,7 @@ func (a *APIVersionScheme) AzureAPIVersionSetContractDetailsVersioningScheme() *
 	return &apiVersionScheme
 }
 
+// +kubebuilder:validation:Enum:=http;https;ws;wss
 type Protocol string
 
 const (
@@ -135,7 +150,10 @@ type APIType string
 
 const (
 	APITypeGraphql   APIType = "graphql"
+	APITypeGrpc      APIType = "grpc"
 	APITypeHTTP      APIType = "http"
+	APITypeOdata     APIType = "odata"
+	APITypeSoap      APIType = "soap"
 	APITypeWebsocket APIType = "websocket"
 )
 
@@ -143,3 +161,210 @@ func (a APIType) AzureApiType() *apim.APIType {
 	apiType := apim.APIType(a)
 	return &apiType
 }
+
+// SoapMode - How a SOAP API imported from a WSDL document is exposed.
+type SoapMode string
+
+const (
+	// SoapModePassthrough - The API has a SOAP front end and requests are passed through to the backend.
+	SoapModePassthrough SoapMode = "passthrough"
+	// SoapModeToRest - The API has a RESTful front end and requests are transformed to SOAP by generated policies.
+	SoapModeToRest SoapMode = "toRest"
+)
+
+func (s *SoapMode) AzureSoapApiType() *apim.SoapAPIType {
+	if s == nil {
+		return nil
+	}
+	soapApiType := apim.SoapAPITypeSoapToRest
+	if *s == SoapModePassthrough {
+		soapApiType = apim.SoapAPITypeSoapPassThrough
+	}
+	return &soapApiType
+}
+
+// WsdlSelector - Criteria to limit import of a WSDL document to a single service and endpoint.
+type WsdlSelector struct {
+	//ServiceName - Name of the service to import from the WSDL document.
+	//+kubebuilder:validation:Optional
+	ServiceName *string `json:"serviceName,omitempty"`
+	//EndpointName - Name of the endpoint (port) of the service to import from the WSDL document.
+	//+kubebuilder:validation:Optional
+	EndpointName *string `json:"endpointName,omitempty"`
+}
+
+func (w *WsdlSelector) AzureWsdlSelector() *apim.APICreateOrUpdatePropertiesWsdlSelector {
+	if w == nil {
+		return nil
+	}
+	return &apim.APICreateOrUpdatePropertiesWsdlSelector{
+		WsdlServiceName:  w.ServiceName,
+		WsdlEndpointName: w.EndpointName,
+	}
+}
+
+// APILicenseInformation - License information for the API.
+type APILicenseInformation struct {
+	//Name - The license name used for the API.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:MinLength:=1
+	Name *string `json:"name,omitempty"`
+	//URL - A URL to the license used for the API.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:Pattern:=`^https?://`
+	URL *string `json:"url,omitempty"`
+}
+
+func (l *APILicenseInformation) AzureAPILicenseInformation() *apim.APILicenseInformation {
+	if l == nil {
+		return nil
+	}
+	return &apim.APILicenseInformation{
+		Name: l.Name,
+		URL:  l.URL,
+	}
+}
+
+// AuthenticationSettings - The authorization server the developer portal uses to get tokens for the API.
+// +kubebuilder:validation:XValidation:rule="has(self.oAuth2) != has(self.openId)",message="exactly one of oAuth2 and openId must be set"
+type AuthenticationSettings struct {
+	//OAuth2 - Use an OAuth 2.0 authorization server of the API Management service.
+	//+kubebuilder:validation:Optional
+	OAuth2 *OAuth2AuthenticationSettings `json:"oAuth2,omitempty"`
+	//OpenId - Use an OpenID Connect provider of the API Management service.
+	//+kubebuilder:validation:Optional
+	OpenId *OpenIdAuthenticationSettings `json:"openId,omitempty"`
+}
+
+// OAuth2AuthenticationSettings - Reference to an OAuth 2.0 authorization server.
+type OAuth2AuthenticationSettings struct {
+	//AuthorizationServerId - Identifier of the OAuth 2.0 authorization server in the API Management service.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:MinLength:=1
+	AuthorizationServerId string `json:"authorizationServerId,omitempty"`
+	//Scope - The scope requested for the API.
+	//+kubebuilder:validation:Optional
+	Scope *string `json:"scope,omitempty"`
+}
+
+// OpenIdAuthenticationSettings - Reference to an OpenID Connect provider.
+type OpenIdAuthenticationSettings struct {
+	//OpenIdProviderId - Identifier of the OpenID Connect provider in the API Management service.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:MinLength:=1
+	OpenIdProviderId string `json:"openIdProviderId,omitempty"`
+	//BearerTokenSendingMethods - How the token is sent to the API.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxItems:=2
+	BearerTokenSendingMethods []BearerTokenSendingMethod `json:"bearerTokenSendingMethods,omitempty"`
+}
+
+// +kubebuilder:validation:Enum:=authorizationHeader;query
+type BearerTokenSendingMethod string
+
+const (
+	// BearerTokenSendingMethodAuthorizationHeader - The token is sent in the Authorization header using the Bearer schema.
+	BearerTokenSendingMethodAuthorizationHeader BearerTokenSendingMethod = "authorizationHeader"
+	// BearerTokenSendingMethodQuery - The token is sent as a query parameter.
+	BearerTokenSendingMethodQuery BearerTokenSendingMethod = "query"
+)
+
+func (a *AuthenticationSettings) AzureAuthenticationSettings() *apim.AuthenticationSettingsContract {
+	if a == nil {
+		return nil
+	}
+	settings := &apim.AuthenticationSettingsContract{}
+	if a.OAuth2 != nil {
+		settings.OAuth2 = &apim.OAuth2AuthenticationSettingsContract{
+			AuthorizationServerID: utils.ToPointer(a.OAuth2.AuthorizationServerId),
+			Scope:                 a.OAuth2.Scope,
+		}
+	}
+	if a.OpenId != nil {
+		settings.Openid = &apim.OpenIDAuthenticationSettingsContract{
+			OpenidProviderID: utils.ToPointer(a.OpenId.OpenIdProviderId),
+		}
+		for _, method := range a.OpenId.BearerTokenSendingMethods {
+			settings.Openid.BearerTokenSendingMethods = append(settings.Openid.BearerTokenSendingMethods, utils.ToPointer(apim.BearerTokenSendingMethods(method)))
+		}
+	}
+	return settings
+}
+
+// SubscriptionKeyParameterNames - Names of the header and query parameter the subscription key is read from.
+type SubscriptionKeyParameterNames struct {
+	//Header - Name of the header carrying the subscription key. Default in APIM is Ocp-Apim-Subscription-Key.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:Pattern:=`^[A-Za-z0-9!#$%&'*+.^_|~-]+$`
+	Header string `json:"header,omitempty"`
+	//Query - Name of the query parameter carrying the subscription key. Default in APIM is subscription-key.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:Pattern:=`^[A-Za-z0-9._~-]+$`
+	Query string `json:"query,omitempty"`
+}
+
+func (s *SubscriptionKeyParameterNames) AzureSubscriptionKeyParameterNames() *apim.SubscriptionKeyParameterNamesContract {
+	if s == nil {
+		return nil
+	}
+	return &apim.SubscriptionKeyParameterNamesContract{
+		Header: utils.ToPointer(s.Header),
+		Query:  utils.ToPointer(s.Query),
+	}
+}
+
+// RevisionPromotion - How a pending APIM revision is promoted to the current revision.
+type RevisionPromotion string
+
+const (
+	// RevisionPromotionAutomatic - The revision is promoted when the health check succeeds.
+	RevisionPromotionAutomatic RevisionPromotion = "Automatic"
+	// RevisionPromotionManual - The revision is promoted when it is approved.
+	RevisionPromotionManual RevisionPromotion = "Manual"
+)
+
+// SunsetAction - What happens to a deprecated API version after its sunset.
+type SunsetAction string
+
+const (
+	// SunsetActionKeep - The version is served as before.
+	SunsetActionKeep SunsetAction = "Keep"
+	// SunsetActionGone - The version responds with 410 Gone.
+	SunsetActionGone SunsetAction = "Gone"
+	// SunsetActionRemove - The API of the version is deleted in APIM.
+	SunsetActionRemove SunsetAction = "Remove"
+)
+
+// DeprecationStage - How far the deprecation of an API version has progressed.
+type DeprecationStage string
+
+const (
+	// DeprecationStageDeprecated - The version is deprecated.
+	DeprecationStageDeprecated DeprecationStage = "Deprecated"
+	// DeprecationStageSunsetApproaching - The sunset of the version is close.
+	DeprecationStageSunsetApproaching DeprecationStage = "SunsetApproaching"
+	// DeprecationStageSunset - The sunset of the version has passed.
+	DeprecationStageSunset DeprecationStage = "Sunset"
+)
+
+// BreakingChangePolicy - What happens when a new OpenAPI document breaks clients of the last imported document.
+type BreakingChangePolicy string
+
+const (
+	// BreakingChangePolicyAllow - Breaking changes are imported and reported in status.
+	BreakingChangePolicyAllow BreakingChangePolicy = "Allow"
+	// BreakingChangePolicyWarn - Breaking changes are imported, reported in status and a warning event is emitted.
+	BreakingChangePolicyWarn BreakingChangePolicy = "Warn"
+	// BreakingChangePolicyBlock - Documents with breaking changes are not imported.
+	BreakingChangePolicyBlock BreakingChangePolicy = "Block"
+)
+
+// LintSeverity - How a violation of a lint rule is handled.
+type LintSeverity string
+
+const (
+	// LintSeverityError - The document is not imported.
+	LintSeverityError LintSeverity = "Error"
+	// LintSeverityWarning - The document is imported and the violation is reported in the LintWarnings condition.
+	LintSeverityWarning LintSeverity = "Warning"
+)
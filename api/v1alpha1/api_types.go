@@ -24,6 +24,15 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // ApiSpec defines the desired state of Api
+// +kubebuilder:validation:XValidation:rule="self.versioningScheme != 'Header' || (has(self.versionHeaderName) && size(self.versionHeaderName) > 0)",message="versionHeaderName is required when versioningScheme is Header"
+// +kubebuilder:validation:XValidation:rule="self.versioningScheme != 'Query' || (has(self.versionQueryName) && size(self.versionQueryName) > 0)",message="versionQueryName is required when versioningScheme is Query"
+// +kubebuilder:validation:XValidation:rule="!has(self.versionHeaderName) || self.versioningScheme == 'Header'",message="versionHeaderName can only be set when versioningScheme is Header"
+// +kubebuilder:validation:XValidation:rule="!has(self.versionQueryName) || self.versioningScheme == 'Query'",message="versionQueryName can only be set when versioningScheme is Query"
+// +kubebuilder:validation:XValidation:rule="!has(self.versions) || !self.versions.exists(v, has(v.graphQLSchema)) || (has(self.apiType) && self.apiType == 'graphql')",message="graphQLSchema requires apiType graphql"
+// +kubebuilder:validation:XValidation:rule="!has(self.versions) || self.versions.all(v, has(v.webSocket) == (has(self.apiType) && self.apiType == 'websocket'))",message="webSocket must be set on all versions when and only when apiType is websocket"
+// +kubebuilder:validation:XValidation:rule="!has(self.versions) || self.versions.all(v, (has(v.soapMode) && v.soapMode == 'passthrough') == (has(self.apiType) && self.apiType == 'soap'))",message="soapMode passthrough must be set on all versions when and only when apiType is soap"
+// +kubebuilder:validation:XValidation:rule="!has(self.versions) || self.versions.all(v, v.contentFormat.startsWith('grpc') == (has(self.apiType) && self.apiType == 'grpc') && v.contentFormat.startsWith('odata') == (has(self.apiType) && self.apiType == 'odata'))",message="contentFormat grpc and odata must be used on all versions when and only when apiType is grpc or odata respectively"
+// +kubebuilder:validation:XValidation:rule="!has(self.versions) || !self.versions.exists(v, v.contentFormat == 'none') || !has(self.apiType) || self.apiType == 'http'",message="contentFormat none requires apiType http"
 type ApiSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
@@ -39,6 +48,12 @@ type ApiSpec struct {
 	//+kubebuilder:default:="Segment"
 	//+kubebuilder:validation:Enum:=Header;Query;Segment
 	VersioningScheme APIVersionScheme `json:"versioningScheme,omitempty"`
+	//VersionHeaderName - Name of HTTP header parameter that indicates the API Version. Required when VersioningScheme is "Header".
+	//+kubebuilder:validation:Optional
+	VersionHeaderName *string `json:"versionHeaderName,omitempty"`
+	//VersionQueryName - Name of query parameter that indicates the API Version. Required when VersioningScheme is "Query".
+	//+kubebuilder:validation:Optional
+	VersionQueryName *string `json:"versionQueryName,omitempty"`
 	//Path - API prefix. The value is combined with the API version to form the URL of the API endpoint.
 	//+kubebuilder:validation:Required
 	Path string `json:"path,omitempty"`
@@ -46,7 +61,7 @@ type ApiSpec struct {
 	//+kubebuilder:validation:Optional
 	//+kubebuilder:default:="http"
 	//+default:value:"http"
-	//+kubebuilder:validation:Enum:=graphql;http;websocket
+	//+kubebuilder:validation:Enum:=graphql;grpc;http;odata;soap;websocket
 	ApiType *APIType `json:"apiType,omitempty"`
 	//Contact - Contact details of the API owner.
 	//+kubebuilder:validation:Optional
@@ -66,6 +81,12 @@ type ApiStatus struct {
 	//VersionStates - A list of API Version deployed in the API Management service.
 	//+kubebuilder:validation:Optional
 	VersionStates map[string]ApiVersionStatus `json:"versionStates,omitempty"`
+	//PendingVersioningScheme - The versioning scheme that is applied, or being applied, to the API version set but not yet to the ApiVersions of the API.
+	//+kubebuilder:validation:Optional
+	PendingVersioningScheme APIVersionScheme `json:"pendingVersioningScheme,omitempty"`
+	//AzureResourceStatus - The observed state of the Azure resource.
+	//+kubebuilder:validation:Optional
+	AzureResourceStatus `json:",inline"`
 }
 
 // +kubebuilder:object:root=true
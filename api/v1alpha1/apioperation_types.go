@@ -0,0 +1,161 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApiOperationSpec defines the desired state of ApiOperation
+type ApiOperationSpec struct {
+	//ApiVersionRef - Name of the ApiVersion in the same namespace the operation belongs to. The ApiVersion must have contentFormat none.
+	//+kubebuilder:validation:Required
+	ApiVersionRef string `json:"apiVersionRef,omitempty"`
+	//DisplayName - The display name of the operation.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:MaxLength:=300
+	DisplayName string `json:"displayName,omitempty"`
+	//Description - Description of the operation. May include HTML formatting tags.
+	//+kubebuilder:validation:Optional
+	Description *string `json:"description,omitempty"`
+	//Method - The HTTP method of the operation.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:Enum:=GET;HEAD;POST;PUT;PATCH;DELETE;OPTIONS;TRACE
+	Method string `json:"method,omitempty"`
+	//UrlTemplate - Relative URL template identifying the target resource of the operation, e.g. /users/{userId}. Every {parameter} must be described in TemplateParameters.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:MaxLength:=1000
+	//+kubebuilder:validation:Pattern:=`^/`
+	UrlTemplate string `json:"urlTemplate,omitempty"`
+	//TemplateParameters - The parameters of the URL template.
+	//+kubebuilder:validation:Optional
+	TemplateParameters []ApiOperationParameter `json:"templateParameters,omitempty"`
+	//Request - The request of the operation.
+	//+kubebuilder:validation:Optional
+	Request *ApiOperationRequest `json:"request,omitempty"`
+	//Responses - The responses of the operation.
+	//+kubebuilder:validation:Optional
+	Responses []ApiOperationResponse `json:"responses,omitempty"`
+}
+
+// ApiOperationParameter defines a template, query or header parameter of an operation
+type ApiOperationParameter struct {
+	//Name - The name of the parameter.
+	//+kubebuilder:validation:Required
+	Name string `json:"name,omitempty"`
+	//Type - The type of the parameter, e.g. string, number or boolean.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:default:=string
+	Type string `json:"type,omitempty"`
+	//Description - Description of the parameter.
+	//+kubebuilder:validation:Optional
+	Description *string `json:"description,omitempty"`
+	//Required - Specifies whether the parameter is required. Template parameters are always required.
+	//+kubebuilder:validation:Optional
+	Required *bool `json:"required,omitempty"`
+	//DefaultValue - The default value of the parameter.
+	//+kubebuilder:validation:Optional
+	DefaultValue *string `json:"defaultValue,omitempty"`
+	//Values - The allowed values of the parameter.
+	//+kubebuilder:validation:Optional
+	Values []string `json:"values,omitempty"`
+}
+
+// ApiOperationRequest defines the request of an operation
+type ApiOperationRequest struct {
+	//Description - Description of the request.
+	//+kubebuilder:validation:Optional
+	Description *string `json:"description,omitempty"`
+	//QueryParameters - The query parameters of the request.
+	//+kubebuilder:validation:Optional
+	QueryParameters []ApiOperationParameter `json:"queryParameters,omitempty"`
+	//Headers - The headers of the request.
+	//+kubebuilder:validation:Optional
+	Headers []ApiOperationParameter `json:"headers,omitempty"`
+	//Representations - The content types and examples of the request body.
+	//+kubebuilder:validation:Optional
+	Representations []ApiOperationRepresentation `json:"representations,omitempty"`
+}
+
+// ApiOperationResponse defines a response of an operation
+type ApiOperationResponse struct {
+	//StatusCode - The HTTP status code of the response.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:Minimum:=100
+	//+kubebuilder:validation:Maximum:=599
+	StatusCode int32 `json:"statusCode,omitempty"`
+	//Description - Description of the response.
+	//+kubebuilder:validation:Optional
+	Description *string `json:"description,omitempty"`
+	//Headers - The headers of the response.
+	//+kubebuilder:validation:Optional
+	Headers []ApiOperationParameter `json:"headers,omitempty"`
+	//Representations - The content types and examples of the response body.
+	//+kubebuilder:validation:Optional
+	Representations []ApiOperationRepresentation `json:"representations,omitempty"`
+}
+
+// ApiOperationRepresentation defines a content type of a request or response body
+type ApiOperationRepresentation struct {
+	//ContentType - The content type of the body, e.g. application/json.
+	//+kubebuilder:validation:Required
+	ContentType string `json:"contentType,omitempty"`
+	//Example - An example of the body, shown in the developer portal.
+	//+kubebuilder:validation:Optional
+	Example *string `json:"example,omitempty"`
+}
+
+// ApiOperationStatus defines the observed state of ApiOperation
+type ApiOperationStatus struct {
+	//OperationID - The identifier of the operation.
+	//+kubebuilder:validation:Optional
+	OperationID string `json:"operationID,omitempty"`
+	//ProvisioningState - The provisioning state of the operation.
+	//+kubebuilder:validation:Optional
+	ProvisioningState string `json:"provisioningState,omitempty"`
+	//LastAppliedSpecSha - The sha256 of the last applied operation.
+	//+kubebuilder:validation:Optional
+	LastAppliedSpecSha string `json:"lastAppliedSpecSha,omitempty"`
+	//AzureResourceStatus - The observed state of the Azure resource.
+	//+kubebuilder:validation:Optional
+	AzureResourceStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ApiOperation is the Schema for the apioperations API
+type ApiOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApiOperationSpec   `json:"spec,omitempty"`
+	Status ApiOperationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApiOperationList contains a list of ApiOperation
+type ApiOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApiOperation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ApiOperation{}, &ApiOperationList{})
+}
@@ -17,17 +17,41 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"reflect"
+	"time"
+)
+
+const (
+	// ApproveRevisionAnnotation - Set to the pending revision number to approve its promotion.
+	ApproveRevisionAnnotation = "apim.azure.stilas.418.cloud/approve-revision"
+	// RollbackRevisionAnnotation - Set to "true" to make the previous revision current again.
+	RollbackRevisionAnnotation = "apim.azure.stilas.418.cloud/rollback-revision"
+	// VersioningSchemeAnnotation - Set by the Api controller to the versioning scheme of the version set when it changes,
+	// so the ApiVersion is reconciled and applied again.
+	VersioningSchemeAnnotation = "apim.azure.stilas.418.cloud/versioning-scheme"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // ApiVersionSpec defines the desired state of ApiVersion
+// +kubebuilder:validation:XValidation:rule="!has(self.graphQLSchema) || (has(self.apiType) && self.apiType == 'graphql')",message="graphQLSchema requires apiType graphql"
+// +kubebuilder:validation:XValidation:rule="has(self.webSocket) == (has(self.apiType) && self.apiType == 'websocket')",message="webSocket must be set when and only when apiType is websocket"
+// +kubebuilder:validation:XValidation:rule="(has(self.soapMode) && self.soapMode == 'passthrough') == (has(self.apiType) && self.apiType == 'soap')",message="soapMode passthrough must be set when and only when apiType is soap"
+// +kubebuilder:validation:XValidation:rule="self.contentFormat.startsWith('grpc') == (has(self.apiType) && self.apiType == 'grpc')",message="contentFormat grpc or grpc-link must be used when and only when apiType is grpc"
+// +kubebuilder:validation:XValidation:rule="self.contentFormat.startsWith('odata') == (has(self.apiType) && self.apiType == 'odata')",message="contentFormat odata or odata-link must be used when and only when apiType is odata"
+// +kubebuilder:validation:XValidation:rule="self.contentFormat != 'none' || !has(self.apiType) || self.apiType == 'http'",message="contentFormat none requires apiType http"
 type ApiVersionSpec struct {
-	ApiVersionSetId   string                 `json:"apiVersionSetId,omitempty"`
-	ApiVersionScheme  APIVersionScheme       `json:"apiVersionScheme,omitempty"`
+	//ApiVersionSetId - The version set the API belongs to. Set by the Api controller for its versions, leave it unset to manage a standalone API without versioning.
+	//+kubebuilder:validation:Optional
+	ApiVersionSetId string `json:"apiVersionSetId,omitempty"`
+	//ApiVersionScheme - The versioning scheme of the version set. Only used together with ApiVersionSetId.
+	//+kubebuilder:validation:Optional
+	ApiVersionScheme APIVersionScheme `json:"apiVersionScheme,omitempty"`
+	//Path - Relative URL uniquely identifying this API within the API Management service instance.
+	//+kubebuilder:validation:Optional
 	Path              string                 `json:"path,omitempty"`
 	APIType           *APIType               `json:"apiType,omitempty"`
 	Contact           *APIContactInformation `json:"contact,omitempty"`
@@ -35,9 +59,20 @@ type ApiVersionSpec struct {
 }
 
 // ApiVersionSubSpec defines the desired state of ApiVersion
+// +kubebuilder:validation:XValidation:rule="has(self.content) || has(self.contentFrom) || has(self.graphQLSchema) || has(self.webSocket) || self.contentFormat == 'none'",message="content or contentFrom is required unless graphQLSchema or webSocket is set or contentFormat is none"
+// +kubebuilder:validation:XValidation:rule="self.contentFormat != 'none' || !(has(self.content) || has(self.contentFrom))",message="content and contentFrom can not be set when contentFormat is none"
+// +kubebuilder:validation:XValidation:rule="!(has(self.content) && has(self.contentFrom))",message="content and contentFrom are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.contentFrom) || self.contentFormat == 'grpc' || self.contentFormat == 'odata'",message="contentFrom requires contentFormat grpc or odata"
+// +kubebuilder:validation:XValidation:rule="!has(self.webSocket) || (has(self.serviceUrl) && (self.serviceUrl.startsWith('ws://') || self.serviceUrl.startsWith('wss://')))",message="webSocket requires a serviceUrl with ws or wss scheme"
+// +kubebuilder:validation:XValidation:rule="!has(self.webSocket) || (has(self.protocols) && size(self.protocols) > 0 && self.protocols.all(p, p == 'ws' || p == 'wss'))",message="webSocket requires protocols to be set to ws and/or wss"
+// +kubebuilder:validation:XValidation:rule="!has(self.webSocket) || !has(self.policies)",message="policies are not supported for webSocket, use webSocket.onHandshakePolicy"
+// +kubebuilder:validation:XValidation:rule="!(has(self.wsdlSelector) || has(self.soapMode)) || self.contentFormat == 'wsdl' || self.contentFormat == 'wsdl-link'",message="wsdlSelector and soapMode require contentFormat wsdl or wsdl-link"
+// +kubebuilder:validation:XValidation:rule="!has(self.deprecation) || !has(self.webSocket)",message="deprecation is not supported for webSocket"
+// +kubebuilder:validation:XValidation:rule="!has(self.deprecation) || !has(self.policies) || !has(self.policies.policyFormat) || !self.policies.policyFormat.endsWith('-link')",message="deprecation requires an inline policy format to add the deprecation headers to"
+// +kubebuilder:validation:XValidation:rule="!has(self.lint) || self.contentFormat in ['openapi', 'openapi+json', 'swagger-json']",message="lint requires contentFormat openapi, openapi+json or swagger-json"
+// +kubebuilder:validation:XValidation:rule="!has(self.breakingChangePolicy) || self.contentFormat.startsWith('openapi') || self.contentFormat.startsWith('swagger')",message="breakingChangePolicy requires an openapi or swagger contentFormat"
 type ApiVersionSubSpec struct {
-	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	//Name - The version identifier of the API in its version set. Not sent to Azure for standalone ApiVersions without ApiVersionSetId.
 	//+kubebuilder:validation:Optional
 	Name *string `json:"name,omitempty"`
 	//DisplayName - The display name of the API Version. This name is used by the developer portal as the API Version name.
@@ -46,25 +81,64 @@ type ApiVersionSubSpec struct {
 	//Description - Description of the API Version. May include its purpose, where to get more information, and other relevant information.
 	//+kubebuilder:validation:Optional
 	Description string `json:"description,omitempty"`
+	//VersionDescription - Description of the version, shown next to the version in the developer portal.
+	//+kubebuilder:validation:Optional
+	VersionDescription *string `json:"versionDescription,omitempty"`
+	//RevisionDescription - Description of the API revision.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxLength:=256
+	RevisionDescription *string `json:"revisionDescription,omitempty"`
+	//License - License information for the API.
+	//+kubebuilder:validation:Optional
+	License *APILicenseInformation `json:"license,omitempty"`
+	//TermsOfServiceUrl - A URL to the terms of service for the API.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxLength:=2000
+	//+kubebuilder:validation:Pattern:=`^https?://`
+	TermsOfServiceUrl *string `json:"termsOfServiceUrl,omitempty"`
+	//AuthenticationSettings - The OAuth 2.0 authorization server or OpenID Connect provider used by the developer portal to call the API.
+	//+kubebuilder:validation:Optional
+	AuthenticationSettings *AuthenticationSettings `json:"authenticationSettings,omitempty"`
+	//SubscriptionKeyParameterNames - Custom names of the header and query parameter carrying the subscription key.
+	//+kubebuilder:validation:Optional
+	SubscriptionKeyParameterNames *SubscriptionKeyParameterNames `json:"subscriptionKeyParameterNames,omitempty"`
 	//ServiceUrl - Absolute URL of the backend service implementing this API. Cannot be more than 2000 characters long.
 	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxLength:=2000
 	ServiceUrl *string `json:"serviceUrl,omitempty"`
 	//Products - Products that the API is associated with. Products are groups of APIs.
 	//+kubebuilder:validation:Optional
 	Products []string `json:"products,omitempty"`
-	//ContentFormat - Format of the Content in which the API is getting imported.
+	//ContentFormat - Format of the Content in which the API is getting imported. Use none to create a blank API with operations defined by ApiOperation resources.
 	//+kubebuilder:validation:Required
 	//+kubebuilder:default:=openapi+json
 	ContentFormat *ContentFormat `json:"contentFormat,omitempty"`
-	//Content - The contents of the API. The value is a string containing the content of the API.
-	//+kubebuilder:validation:Required
+	//Content - The contents of the API. The value is a string containing the content of the API. Required unless ContentFrom, GraphQLSchema or WebSocket is set or ContentFormat is none.
+	//+kubebuilder:validation:Optional
 	Content *string `json:"content,omitempty"`
+	//ContentFrom - Read the contents of the API from a ConfigMap instead of Content. Supported for the protobuf file of gRPC APIs and the $metadata document of OData APIs.
+	//+kubebuilder:validation:Optional
+	ContentFrom *ContentSource `json:"contentFrom,omitempty"`
+	//GraphQLSchema - The schema of a synthetic GraphQL API. The API is created without importing Content and fields are resolved by GraphQLResolver resources. Requires apiType graphql.
+	//+kubebuilder:validation:Optional
+	GraphQLSchema *GraphQLSchemaSource `json:"graphQLSchema,omitempty"`
+	//WsdlSelector - Import a single service and endpoint of the WSDL document. Only used with contentFormat wsdl and wsdl-link.
+	//+kubebuilder:validation:Optional
+	WsdlSelector *WsdlSelector `json:"wsdlSelector,omitempty"`
+	//SoapMode - How the SOAP API imported from a WSDL document is exposed. Passthrough keeps the SOAP front end and requires apiType soap, toRest exposes the operations as a REST API.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:Enum:=passthrough;toRest
+	SoapMode *SoapMode `json:"soapMode,omitempty"`
+	//WebSocket - Serve the API Version as a WebSocket pass-through API. Content is not imported, ServiceUrl must use the ws or wss scheme and Protocols may only contain ws and wss. Requires apiType websocket.
+	//+kubebuilder:validation:Optional
+	WebSocket *WebSocketSpec `json:"webSocket,omitempty"`
 	//SubscriptionRquired - Indicates if subscription is required to access the API. Default value is true.
 	//+kubebuilder:validation:Required
 	//+kubebuilder:default:=true
 	SubscriptionRequired *bool `json:"subscriptionRequired,omitempty"`
 	//Protocols - Describes protocols over which API is made available.
 	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxItems:=4
 	//+kubebuilder:default:={https}
 	Protocols []Protocol `json:"protocols,omitempty"`
 	//IsCurrent - Indicates if API Version is the current api version.
@@ -74,6 +148,110 @@ type ApiVersionSubSpec struct {
 	//Policy - The API Version Policy description.
 	//+kubebuilder:validation:Optional
 	Policy *ApiPolicySpec `json:"policies,omitempty"`
+	//ReleaseNotes - Notes for the API release created when new content is applied. Shown in the developer portal change log.
+	//+kubebuilder:validation:Optional
+	ReleaseNotes *string `json:"releaseNotes,omitempty"`
+	//Revision - Stage changes to the API Version as APIM revisions instead of updating the live API in place.
+	//+kubebuilder:validation:Optional
+	Revision *ApiRevisionSpec `json:"revision,omitempty"`
+	//Deprecation - Retire the API Version. Responses get Deprecation, Sunset and Link headers and the version description in APIM is marked as deprecated.
+	//+kubebuilder:validation:Optional
+	Deprecation *ApiDeprecationSpec `json:"deprecation,omitempty"`
+	//BreakingChangePolicy - Compare new OpenAPI documents with the last imported document before they are imported. Allow imports and reports breaking changes in status, Warn emits a warning event as well and Block does not import the document.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:Enum:=Allow;Warn;Block
+	BreakingChangePolicy *BreakingChangePolicy `json:"breakingChangePolicy,omitempty"`
+	//Lint - Lint the OpenAPI document before it is imported. Violations of Error rules block the import and violations of Warning rules are reported in the LintWarnings condition.
+	//+kubebuilder:validation:Optional
+	Lint *ApiLintSpec `json:"lint,omitempty"`
+}
+
+// ApiLintSpec defines the rules the OpenAPI document of an ApiVersion is linted with
+type ApiLintSpec struct {
+	//RuleSets - Names of LintRuleSets in the namespace of the ApiVersion applied after the built-in rules.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxItems:=10
+	RuleSets []string `json:"ruleSets,omitempty"`
+	//DisabledRules - Names of rules that are not applied. The built-in rules are operation-operationid and operation-operationid-unique with severity Error and operation-description and operation-summary-unique with severity Warning.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxItems:=50
+	DisabledRules []string `json:"disabledRules,omitempty"`
+}
+
+// ApiDeprecationSpec defines when an ApiVersion is deprecated and sunset
+// +kubebuilder:validation:XValidation:rule="!has(self.sunsetAt) || self.sunsetAt >= self.deprecatedAt",message="sunsetAt can not be before deprecatedAt"
+// +kubebuilder:validation:XValidation:rule="!has(self.afterSunset) || self.afterSunset == 'Keep' || has(self.sunsetAt)",message="afterSunset Gone and Remove require sunsetAt"
+type ApiDeprecationSpec struct {
+	//DeprecatedAt - When the version is deprecated. Sent in the Deprecation response header.
+	//+kubebuilder:validation:Required
+	DeprecatedAt metav1.Time `json:"deprecatedAt,omitempty"`
+	//SunsetAt - When the version stops being supported. Sent in the Sunset response header.
+	//+kubebuilder:validation:Optional
+	SunsetAt *metav1.Time `json:"sunsetAt,omitempty"`
+	//SuccessorVersion - The version replacing this version. Sent in the Link response header with relation successor-version when the versioning scheme is Segment.
+	//+kubebuilder:validation:Optional
+	SuccessorVersion *string `json:"successorVersion,omitempty"`
+	//AfterSunset - What happens to the version after SunsetAt. Keep serves the version as before, Gone responds with 410 Gone and Remove deletes the API in APIM.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:default:=Keep
+	//+kubebuilder:validation:Enum:=Keep;Gone;Remove
+	AfterSunset SunsetAction `json:"afterSunset,omitempty"`
+}
+
+// IsSunset returns true if the sunset of the version has passed at the given time
+func (d *ApiDeprecationSpec) IsSunset(now time.Time) bool {
+	return d != nil && d.SunsetAt != nil && !now.Before(d.SunsetAt.Time)
+}
+
+// GraphQLSchemaSource defines where the schema of a synthetic GraphQL API is read from
+// +kubebuilder:validation:XValidation:rule="has(self.sdl) != has(self.configMapKeyRef)",message="exactly one of sdl and configMapKeyRef must be set"
+type GraphQLSchemaSource struct {
+	//Sdl - The schema in GraphQL schema definition language.
+	//+kubebuilder:validation:Optional
+	Sdl *string `json:"sdl,omitempty"`
+	//ConfigMapKeyRef - A key of a ConfigMap in the same namespace holding the schema in GraphQL schema definition language.
+	//+kubebuilder:validation:Optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// ContentSource defines where the contents of an API are read from when they are not set inline
+type ContentSource struct {
+	//ConfigMapKeyRef - A key of a ConfigMap in the same namespace holding the contents of the API.
+	//+kubebuilder:validation:Required
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// WebSocketSpec defines the WebSocket mode of an ApiVersion
+type WebSocketSpec struct {
+	//OnHandshakePolicy - Policy applied to the onHandshake operation when a client opens a connection. APIM only runs the inbound, backend and on-error sections for WebSocket APIs.
+	//+kubebuilder:validation:Optional
+	OnHandshakePolicy *ApiPolicySpec `json:"onHandshakePolicy,omitempty"`
+}
+
+// ApiRevisionSpec defines how changes to an ApiVersion are staged and promoted as APIM revisions
+type ApiRevisionSpec struct {
+	//Enabled - Create a new APIM revision when the content changes. The revision is not visible to consumers until it is promoted.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:default:=false
+	Enabled bool `json:"enabled,omitempty"`
+	//Promotion - How a pending revision is promoted to the current revision. Automatic promotes after a successful health check, or as soon as the revision is staged when HealthCheckUrl is not set. Manual waits for approval.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:default:=Manual
+	//+kubebuilder:validation:Enum:=Automatic;Manual
+	Promotion RevisionPromotion `json:"promotion,omitempty"`
+	//HealthCheckUrl - http or https URL that must respond with a 2xx status code before a pending revision is promoted automatically. {revision} is replaced with the pending revision number. Ignored with Manual promotion.
+	//The URL is requested by the operator from inside the cluster, so anyone allowed to edit ApiVersions can make the operator send GET requests to internal endpoints. Restrict who can create ApiVersions or the egress of the operator accordingly. Redirects are not followed and count as a failed health check.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:Pattern:=`^https?://`
+	HealthCheckUrl *string `json:"healthCheckUrl,omitempty"`
+	//ApprovedRevision - The pending revision that is approved for promotion.
+	//+kubebuilder:validation:Optional
+	ApprovedRevision *string `json:"approvedRevision,omitempty"`
+}
+
+// IsEnabled returns true if changes should be staged as APIM revisions
+func (r *ApiRevisionSpec) IsEnabled() bool {
+	return r != nil && r.Enabled
 }
 
 // ApiPolicySpec defines the desired state of ApiVersion
@@ -86,6 +264,24 @@ type ApiPolicySpec struct {
 	//+kubebuilder:default:=xml
 	//+kubebuilder:validation:Enum:=xml;xml-link;rawxml;rawxml-link
 	PolicyFormat *PolicyFormat `json:"policyFormat,omitempty"`
+	//Templated - Render PolicyContent as a Go template before it is applied. Actions use [[ and ]] as delimiters so APIM named values ({{name}}) are left untouched. Only inline formats (xml, rawxml) are rendered. A template that fails to render is not retried until the spec changes.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:default:=false
+	Templated *bool `json:"templated,omitempty"`
+	//PolicyParameters - User defined values available to the policy template as .Parameters.
+	//+kubebuilder:validation:Optional
+	PolicyParameters map[string]string `json:"policyParameters,omitempty"`
+	//Backends - Names of Backend resources in the same namespace. Their APIM backend ids are available to the policy template as .Backends.<name>.
+	//+kubebuilder:validation:Optional
+	Backends []string `json:"backends,omitempty"`
+}
+
+// IsTemplated returns true if the policy content should be rendered as a template before it is applied.
+func (p *ApiPolicySpec) IsTemplated() bool {
+	if p == nil || p.Templated == nil || !*p.Templated {
+		return false
+	}
+	return p.PolicyFormat == nil || *p.PolicyFormat == PolicyContentFormatXML || *p.PolicyFormat == PolicyContentFormatRawxml
 }
 
 // ApiVersionStatus defines the observed state of ApiVersion
@@ -96,12 +292,81 @@ type ApiVersionStatus struct {
 	//ResumeToken - The token used to track long-running operations.
 	//+kubebuilder:validation:Optional
 	ResumeToken string `json:"pollerToken,omitempty"`
+	//ResumeSpecSha - The sha256 of the spec the import tracked by ResumeToken was started with. The import is started again when the spec changes before it completes.
+	//+kubebuilder:validation:Optional
+	ResumeSpecSha string `json:"resumeSpecSha,omitempty"`
 	//LastAppliedSpecSha - The sha256 of the last applied spec.
 	//+kubebuilder:validation:Optional
 	LastAppliedSpecSha string `json:"lastAppliedSpecSha,omitempty"`
-	//LastAppliedPolicySha - The sha256 of the last applied policy.
+	//LastReleaseId - The id of the last release created for the applied spec. The release is retried until it matches the LastAppliedSpecSha.
+	//+kubebuilder:validation:Optional
+	LastReleaseId string `json:"lastReleaseId,omitempty"`
+	//LastAppliedPolicySha - The sha256 of the last applied policy. For templated policies this is the sha of the rendered policy.
 	//+kubebuilder:validation:Optional
 	LastAppliedPolicySha string `json:"lastAppliedPolicySha,omitempty"`
+	//PolicyETag - The ETag of the API policy when it was last read or written.
+	//+kubebuilder:validation:Optional
+	PolicyETag string `json:"policyETag,omitempty"`
+	//LastAppliedOnHandshakePolicySha - The sha256 of the last applied onHandshake policy of a WebSocket API.
+	//+kubebuilder:validation:Optional
+	LastAppliedOnHandshakePolicySha string `json:"lastAppliedOnHandshakePolicySha,omitempty"`
+	//LastAppliedSchemaSha - The sha256 of the last applied GraphQL schema.
+	//+kubebuilder:validation:Optional
+	LastAppliedSchemaSha string `json:"lastAppliedSchemaSha,omitempty"`
+	//SchemaResumeToken - The token used to track the upload of the GraphQL schema.
+	//+kubebuilder:validation:Optional
+	SchemaResumeToken string `json:"schemaResumeToken,omitempty"`
+	//SchemaResumeSha - The sha256 of the GraphQL schema the upload tracked by SchemaResumeToken was started with. The upload is started again when the schema changes before it completes.
+	//+kubebuilder:validation:Optional
+	SchemaResumeSha string `json:"schemaResumeSha,omitempty"`
+	//LastOperationError - The error reported by Azure when the last import of the API failed.
+	//+kubebuilder:validation:Optional
+	LastOperationError *OperationErrorStatus `json:"lastOperationError,omitempty"`
+	//CurrentRevision - The APIM revision currently served to consumers.
+	//+kubebuilder:validation:Optional
+	CurrentRevision string `json:"currentRevision,omitempty"`
+	//PendingRevision - The APIM revision holding staged changes waiting for promotion.
+	//+kubebuilder:validation:Optional
+	PendingRevision string `json:"pendingRevision,omitempty"`
+	//PreviousRevision - The APIM revision that was current before the last promotion. Used for rollback.
+	//+kubebuilder:validation:Optional
+	PreviousRevision string `json:"previousRevision,omitempty"`
+	//DeprecationStage - How far the deprecation of the version has progressed. Possible values are: Deprecated, SunsetApproaching and Sunset.
+	//+kubebuilder:validation:Optional
+	DeprecationStage DeprecationStage `json:"deprecationStage,omitempty"`
+	//BreakingChanges - The breaking changes found in the OpenAPI document compared with the last imported document.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxItems:=100
+	BreakingChanges []BreakingChangeStatus `json:"breakingChanges,omitempty"`
+	//LintFindings - The lint rule violations found in the OpenAPI document when it was last linted.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxItems:=100
+	LintFindings []LintFindingStatus `json:"lintFindings,omitempty"`
+	//AzureResourceStatus - The observed state of the Azure resource.
+	//+kubebuilder:validation:Optional
+	AzureResourceStatus `json:",inline"`
+}
+
+// BreakingChangeStatus - A change to the OpenAPI document that breaks clients of the last imported document.
+type BreakingChangeStatus struct {
+	//Type - The kind of change. Possible values are: RemovedPath, RemovedOperation, RemovedRequiredResponseField and NewRequiredParameter.
+	Type string `json:"type"`
+	//Location - The path, or the method and path of the operation, that changed.
+	Location string `json:"location"`
+	//Message - A description of the change.
+	Message string `json:"message"`
+}
+
+// LintFindingStatus - A violation of a lint rule in the OpenAPI document.
+type LintFindingStatus struct {
+	//Rule - The name of the violated rule.
+	Rule string `json:"rule"`
+	//Severity - The severity of the violated rule.
+	Severity LintSeverity `json:"severity"`
+	//Location - The JSONPath of the value violating the rule.
+	Location string `json:"location"`
+	//Message - A description of the violation.
+	Message string `json:"message"`
 }
 
 // +kubebuilder:object:root=true
@@ -137,16 +402,58 @@ func (a *ApiVersion) RequireUpdate(new ApiVersion) bool {
 		!pointerValueEqual(a.Spec.ApiVersionSubSpec.Name, new.Spec.ApiVersionSubSpec.Name) ||
 		a.Spec.ApiVersionSubSpec.DisplayName != new.Spec.ApiVersionSubSpec.DisplayName ||
 		a.Spec.ApiVersionSubSpec.Description != new.Spec.ApiVersionSubSpec.Description ||
+		!pointerValueEqual(a.Spec.ApiVersionSubSpec.VersionDescription, new.Spec.ApiVersionSubSpec.VersionDescription) ||
+		!pointerValueEqual(a.Spec.ApiVersionSubSpec.RevisionDescription, new.Spec.ApiVersionSubSpec.RevisionDescription) ||
+		!reflect.DeepEqual(a.Spec.ApiVersionSubSpec.License, new.Spec.ApiVersionSubSpec.License) ||
+		!pointerValueEqual(a.Spec.ApiVersionSubSpec.TermsOfServiceUrl, new.Spec.ApiVersionSubSpec.TermsOfServiceUrl) ||
+		!reflect.DeepEqual(a.Spec.ApiVersionSubSpec.AuthenticationSettings, new.Spec.ApiVersionSubSpec.AuthenticationSettings) ||
+		!pointerValueEqual(a.Spec.ApiVersionSubSpec.SubscriptionKeyParameterNames, new.Spec.ApiVersionSubSpec.SubscriptionKeyParameterNames) ||
 		!pointerValueEqual(a.Spec.ApiVersionSubSpec.ServiceUrl, new.Spec.ApiVersionSubSpec.ServiceUrl) ||
 		!reflect.DeepEqual(a.Spec.ApiVersionSubSpec.Products, new.Spec.ApiVersionSubSpec.Products) ||
 		!pointerValueEqual(a.Spec.ApiVersionSubSpec.ContentFormat, new.Spec.ApiVersionSubSpec.ContentFormat) ||
 		!pointerValueEqual(a.Spec.ApiVersionSubSpec.Content, new.Spec.ApiVersionSubSpec.Content) ||
+		!reflect.DeepEqual(a.Spec.ApiVersionSubSpec.ContentFrom, new.Spec.ApiVersionSubSpec.ContentFrom) ||
+		!reflect.DeepEqual(a.Spec.ApiVersionSubSpec.WsdlSelector, new.Spec.ApiVersionSubSpec.WsdlSelector) ||
+		!pointerValueEqual(a.Spec.ApiVersionSubSpec.SoapMode, new.Spec.ApiVersionSubSpec.SoapMode) ||
+		!reflect.DeepEqual(a.Spec.ApiVersionSubSpec.GraphQLSchema, new.Spec.ApiVersionSubSpec.GraphQLSchema) ||
+		a.Spec.ApiVersionSubSpec.WebSocket.requireUpdate(new.Spec.ApiVersionSubSpec.WebSocket) ||
 		!pointerValueEqual(a.Spec.ApiVersionSubSpec.SubscriptionRequired, new.Spec.ApiVersionSubSpec.SubscriptionRequired) ||
 		!reflect.DeepEqual(a.Spec.ApiVersionSubSpec.Protocols, new.Spec.ApiVersionSubSpec.Protocols) ||
 		!pointerValueEqual(a.Spec.ApiVersionSubSpec.IsCurrent, new.Spec.ApiVersionSubSpec.IsCurrent) ||
-		(a.Spec.ApiVersionSubSpec.Policy == nil && new.Spec.ApiVersionSubSpec.Policy != nil) || (a.Spec.ApiVersionSubSpec.Policy != nil && new.Spec.ApiVersionSubSpec.Policy == nil) ||
-		!pointerValueEqual(a.Spec.ApiVersionSubSpec.Policy.PolicyContent, new.Spec.ApiVersionSubSpec.Policy.PolicyContent) ||
-		!pointerValueEqual(a.Spec.ApiVersionSubSpec.Policy.PolicyFormat, new.Spec.ApiVersionSubSpec.Policy.PolicyFormat)
+		a.Spec.ApiVersionSubSpec.Policy.requireUpdate(new.Spec.ApiVersionSubSpec.Policy) ||
+		!reflect.DeepEqual(a.Spec.ApiVersionSubSpec.Revision, new.Spec.ApiVersionSubSpec.Revision) ||
+		a.Spec.ApiVersionSubSpec.Deprecation.requireUpdate(new.Spec.ApiVersionSubSpec.Deprecation) ||
+		!pointerValueEqual(a.Spec.ApiVersionSubSpec.BreakingChangePolicy, new.Spec.ApiVersionSubSpec.BreakingChangePolicy) ||
+		!reflect.DeepEqual(a.Spec.ApiVersionSubSpec.Lint, new.Spec.ApiVersionSubSpec.Lint) ||
+		!pointerValueEqual(a.Spec.ApiVersionSubSpec.ReleaseNotes, new.Spec.ApiVersionSubSpec.ReleaseNotes)
+}
+
+func (w *WebSocketSpec) requireUpdate(new *WebSocketSpec) bool {
+	if w == nil || new == nil {
+		return w != new
+	}
+	return w.OnHandshakePolicy.requireUpdate(new.OnHandshakePolicy)
+}
+
+func (d *ApiDeprecationSpec) requireUpdate(new *ApiDeprecationSpec) bool {
+	if d == nil || new == nil {
+		return d != new
+	}
+	return !d.DeprecatedAt.Equal(&new.DeprecatedAt) ||
+		!d.SunsetAt.Equal(new.SunsetAt) ||
+		!pointerValueEqual(d.SuccessorVersion, new.SuccessorVersion) ||
+		d.AfterSunset != new.AfterSunset
+}
+
+func (p *ApiPolicySpec) requireUpdate(new *ApiPolicySpec) bool {
+	if p == nil || new == nil {
+		return p != new
+	}
+	return !pointerValueEqual(p.PolicyContent, new.PolicyContent) ||
+		!pointerValueEqual(p.PolicyFormat, new.PolicyFormat) ||
+		!pointerValueEqual(p.Templated, new.Templated) ||
+		!reflect.DeepEqual(p.PolicyParameters, new.PolicyParameters) ||
+		!reflect.DeepEqual(p.Backends, new.Backends)
 }
 
 func pointerValueEqual[T comparable](a *T, b *T) bool {
@@ -55,6 +55,9 @@ type BackendStatus struct {
 	//ProvisioningState - The provisioning state of the Backend.
 	//+kubebuilder:validation:Optional
 	ProvisioningState string `json:"provisioningState,omitempty"`
+	//AzureResourceStatus - The observed state of the Azure resource.
+	//+kubebuilder:validation:Optional
+	AzureResourceStatus `json:",inline"`
 }
 
 // +kubebuilder:object:root=true
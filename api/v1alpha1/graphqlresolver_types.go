@@ -0,0 +1,109 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GraphQLResolverSpec defines the desired state of GraphQLResolver
+// +kubebuilder:validation:XValidation:rule="has(self.httpDataSource) != has(self.policyContent)",message="exactly one of httpDataSource and policyContent must be set"
+type GraphQLResolverSpec struct {
+	//ApiVersionRef - Name of the ApiVersion in the same namespace serving the synthetic GraphQL API the resolver belongs to.
+	//+kubebuilder:validation:Required
+	ApiVersionRef string `json:"apiVersionRef,omitempty"`
+	//Type - The schema type holding the resolved field, e.g. Query or Mutation.
+	//+kubebuilder:validation:Required
+	Type string `json:"type,omitempty"`
+	//Field - The field of the type resolved by the resolver.
+	//+kubebuilder:validation:Required
+	Field string `json:"field,omitempty"`
+	//DisplayName - The display name of the resolver. Defaults to Type.Field.
+	//+kubebuilder:validation:Optional
+	DisplayName *string `json:"displayName,omitempty"`
+	//Description - Description of the resolver.
+	//+kubebuilder:validation:Optional
+	Description *string `json:"description,omitempty"`
+	//HttpDataSource - Resolve the field with an HTTP request.
+	//+kubebuilder:validation:Optional
+	HttpDataSource *GraphQLHttpDataSource `json:"httpDataSource,omitempty"`
+	//PolicyContent - The resolver policy as XML, e.g. an http-data-source, sql-data-source or cosmosdb-data-source element.
+	//+kubebuilder:validation:Optional
+	PolicyContent *string `json:"policyContent,omitempty"`
+}
+
+// GraphQLHttpDataSource defines the HTTP request used to resolve a GraphQL field
+type GraphQLHttpDataSource struct {
+	//Url - URL of the HTTP request. May contain policy expressions.
+	//+kubebuilder:validation:Required
+	Url string `json:"url,omitempty"`
+	//Method - HTTP method of the request.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:default:=GET
+	//+kubebuilder:validation:Enum:=GET;POST;PUT;PATCH;DELETE
+	Method string `json:"method,omitempty"`
+	//Headers - Headers set on the request.
+	//+kubebuilder:validation:Optional
+	Headers map[string]string `json:"headers,omitempty"`
+	//Body - Liquid template of the request body.
+	//+kubebuilder:validation:Optional
+	Body *string `json:"body,omitempty"`
+}
+
+// GraphQLResolverStatus defines the observed state of GraphQLResolver
+type GraphQLResolverStatus struct {
+	//ResolverID - The identifier of the resolver.
+	//+kubebuilder:validation:Optional
+	ResolverID string `json:"resolverID,omitempty"`
+	//ProvisioningState - The provisioning state of the resolver.
+	//+kubebuilder:validation:Optional
+	ProvisioningState string `json:"provisioningState,omitempty"`
+	//LastAppliedPolicySha - The sha256 of the last applied resolver policy.
+	//+kubebuilder:validation:Optional
+	LastAppliedPolicySha string `json:"lastAppliedPolicySha,omitempty"`
+	//PolicyETag - The ETag of the resolver policy when it was last read or written.
+	//+kubebuilder:validation:Optional
+	PolicyETag string `json:"policyETag,omitempty"`
+	//AzureResourceStatus - The observed state of the Azure resource.
+	//+kubebuilder:validation:Optional
+	AzureResourceStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// GraphQLResolver is the Schema for the graphqlresolvers API
+type GraphQLResolver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GraphQLResolverSpec   `json:"spec,omitempty"`
+	Status GraphQLResolverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GraphQLResolverList contains a list of GraphQLResolver
+type GraphQLResolverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GraphQLResolver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GraphQLResolver{}, &GraphQLResolverList{})
+}
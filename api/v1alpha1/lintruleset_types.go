@@ -0,0 +1,87 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LintRuleSetSpec defines the desired state of LintRuleSet
+type LintRuleSetSpec struct {
+	//Rules - The rules the OpenAPI documents of the ApiVersions referencing the rule set are checked with before they are imported.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:MinItems:=1
+	//+kubebuilder:validation:MaxItems:=100
+	//+listType=map
+	//+listMapKey=name
+	Rules []LintRule `json:"rules"`
+}
+
+// LintRule defines a check of the values selected by a JSONPath expression in an OpenAPI document
+// +kubebuilder:validation:XValidation:rule="has(self.field) || has(self.pattern)",message="at least one of field and pattern must be set"
+type LintRule struct {
+	//Name - The name of the rule. Reported with violations of the rule and used to disable the rule on an ApiVersion.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:MaxLength:=63
+	//+kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	//Severity - Violations of Error rules block the import of the document. Violations of Warning rules are reported in the LintWarnings condition.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:default:=Warning
+	//+kubebuilder:validation:Enum:=Error;Warning
+	Severity LintSeverity `json:"severity,omitempty"`
+	//Target - JSONPath expression selecting the values that are checked, e.g. $.paths.*.get or $..parameters[*].name. Child, wildcard, index, union of quoted names and recursive descent selectors are supported.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:MaxLength:=512
+	//+kubebuilder:validation:Pattern:=`^\$`
+	Target string `json:"target"`
+	//Field - A field the selected objects must have. When Pattern is set as well, the value of the field must match the pattern.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxLength:=256
+	Field *string `json:"field,omitempty"`
+	//Pattern - A regular expression in RE2 syntax the selected values must match.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxLength:=1024
+	Pattern *string `json:"pattern,omitempty"`
+	//Message - The message reported for violations of the rule. Defaults to a description of the violation.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxLength:=512
+	Message *string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LintRuleSet is the Schema for the lintrulesets API
+type LintRuleSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LintRuleSetSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LintRuleSetList contains a list of LintRuleSet
+type LintRuleSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LintRuleSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LintRuleSet{}, &LintRuleSetList{})
+}
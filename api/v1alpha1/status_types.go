@@ -0,0 +1,70 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionTypeConflict - The resource was repeatedly modified in Azure while it was being updated.
+const ConditionTypeConflict = "Conflict"
+
+// ConditionTypeLintWarnings - The imported OpenAPI document violates lint rules with severity Warning.
+const ConditionTypeLintWarnings = "LintWarnings"
+
+// AzureResourceStatus - The observed state of the Azure resource and the last error returned by Azure while reconciling it.
+type AzureResourceStatus struct {
+	//ETag - The ETag of the Azure resource when it was last read or written. Sent as If-Match on updates and deletes.
+	//+kubebuilder:validation:Optional
+	ETag string `json:"etag,omitempty"`
+	//ErrorClass - The classification of the last Azure error. Throttled and Transient errors are retried with a delay, Conflict errors are retried immediately and Permanent errors are not retried until the spec changes.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:Enum=Throttled;Transient;Conflict;Permanent
+	ErrorClass string `json:"errorClass,omitempty"`
+	//ErrorMessage - The message of the last Azure error.
+	//+kubebuilder:validation:Optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	//FailedGeneration - The generation that failed with a permanent error. Reconciliation is paused until the generation changes.
+	//+kubebuilder:validation:Optional
+	FailedGeneration int64 `json:"failedGeneration,omitempty"`
+	//ConflictCount - The number of consecutive writes rejected because the resource was modified in Azure.
+	//+kubebuilder:validation:Optional
+	ConflictCount int32 `json:"conflictCount,omitempty"`
+	//Conditions - The conditions of the resource.
+	//+kubebuilder:validation:Optional
+	//+listType=map
+	//+listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// PermanentlyFailed returns true if the given generation has already failed with a permanent error
+func (s AzureResourceStatus) PermanentlyFailed(generation int64) bool {
+	return s.ErrorClass == "Permanent" && s.FailedGeneration == generation
+}
+
+// OperationErrorStatus - The error reported by Azure for a failed long-running operation.
+type OperationErrorStatus struct {
+	//Code - The Azure error code.
+	//+kubebuilder:validation:Optional
+	Code string `json:"code,omitempty"`
+	//Message - The Azure error message.
+	//+kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+	//Target - The part of the request the error refers to.
+	//+kubebuilder:validation:Optional
+	Target string `json:"target,omitempty"`
+	//Details - Additional errors, typically one per validation failure of the imported content.
+	//+kubebuilder:validation:Optional
+	Details []OperationErrorDetailStatus `json:"details,omitempty"`
+}
+
+// OperationErrorDetailStatus - A detail of the error reported by Azure for a failed long-running operation.
+type OperationErrorDetailStatus struct {
+	//Code - The Azure error code.
+	//+kubebuilder:validation:Optional
+	Code string `json:"code,omitempty"`
+	//Message - The Azure error message.
+	//+kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+	//Target - The part of the request the error refers to.
+	//+kubebuilder:validation:Optional
+	Target string `json:"target,omitempty"`
+}
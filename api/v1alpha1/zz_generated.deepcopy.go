@@ -21,6 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -54,6 +56,31 @@ func (in *APIContactInformation) DeepCopy() *APIContactInformation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APILicenseInformation) DeepCopyInto(out *APILicenseInformation) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APILicenseInformation.
+func (in *APILicenseInformation) DeepCopy() *APILicenseInformation {
+	if in == nil {
+		return nil
+	}
+	out := new(APILicenseInformation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Api) DeepCopyInto(out *Api) {
 	*out = *in
@@ -63,18 +90,978 @@ func (in *Api) DeepCopyInto(out *Api) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Api.
-func (in *Api) DeepCopy() *Api {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Api.
+func (in *Api) DeepCopy() *Api {
+	if in == nil {
+		return nil
+	}
+	out := new(Api)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Api) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiDeprecationSpec) DeepCopyInto(out *ApiDeprecationSpec) {
+	*out = *in
+	in.DeprecatedAt.DeepCopyInto(&out.DeprecatedAt)
+	if in.SunsetAt != nil {
+		in, out := &in.SunsetAt, &out.SunsetAt
+		*out = (*in).DeepCopy()
+	}
+	if in.SuccessorVersion != nil {
+		in, out := &in.SuccessorVersion, &out.SuccessorVersion
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiDeprecationSpec.
+func (in *ApiDeprecationSpec) DeepCopy() *ApiDeprecationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiDeprecationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiLintSpec) DeepCopyInto(out *ApiLintSpec) {
+	*out = *in
+	if in.RuleSets != nil {
+		in, out := &in.RuleSets, &out.RuleSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisabledRules != nil {
+		in, out := &in.DisabledRules, &out.DisabledRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiLintSpec.
+func (in *ApiLintSpec) DeepCopy() *ApiLintSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiLintSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiList) DeepCopyInto(out *ApiList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Api, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiList.
+func (in *ApiList) DeepCopy() *ApiList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApiList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiOperation) DeepCopyInto(out *ApiOperation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiOperation.
+func (in *ApiOperation) DeepCopy() *ApiOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApiOperation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiOperationList) DeepCopyInto(out *ApiOperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApiOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiOperationList.
+func (in *ApiOperationList) DeepCopy() *ApiOperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiOperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApiOperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiOperationParameter) DeepCopyInto(out *ApiOperationParameter) {
+	*out = *in
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.Required != nil {
+		in, out := &in.Required, &out.Required
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DefaultValue != nil {
+		in, out := &in.DefaultValue, &out.DefaultValue
+		*out = new(string)
+		**out = **in
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiOperationParameter.
+func (in *ApiOperationParameter) DeepCopy() *ApiOperationParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiOperationParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiOperationRepresentation) DeepCopyInto(out *ApiOperationRepresentation) {
+	*out = *in
+	if in.Example != nil {
+		in, out := &in.Example, &out.Example
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiOperationRepresentation.
+func (in *ApiOperationRepresentation) DeepCopy() *ApiOperationRepresentation {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiOperationRepresentation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiOperationRequest) DeepCopyInto(out *ApiOperationRequest) {
+	*out = *in
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.QueryParameters != nil {
+		in, out := &in.QueryParameters, &out.QueryParameters
+		*out = make([]ApiOperationParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]ApiOperationParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Representations != nil {
+		in, out := &in.Representations, &out.Representations
+		*out = make([]ApiOperationRepresentation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiOperationRequest.
+func (in *ApiOperationRequest) DeepCopy() *ApiOperationRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiOperationRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiOperationResponse) DeepCopyInto(out *ApiOperationResponse) {
+	*out = *in
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]ApiOperationParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Representations != nil {
+		in, out := &in.Representations, &out.Representations
+		*out = make([]ApiOperationRepresentation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiOperationResponse.
+func (in *ApiOperationResponse) DeepCopy() *ApiOperationResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiOperationResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiOperationSpec) DeepCopyInto(out *ApiOperationSpec) {
+	*out = *in
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.TemplateParameters != nil {
+		in, out := &in.TemplateParameters, &out.TemplateParameters
+		*out = make([]ApiOperationParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(ApiOperationRequest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Responses != nil {
+		in, out := &in.Responses, &out.Responses
+		*out = make([]ApiOperationResponse, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiOperationSpec.
+func (in *ApiOperationSpec) DeepCopy() *ApiOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiOperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiOperationStatus) DeepCopyInto(out *ApiOperationStatus) {
+	*out = *in
+	in.AzureResourceStatus.DeepCopyInto(&out.AzureResourceStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiOperationStatus.
+func (in *ApiOperationStatus) DeepCopy() *ApiOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiPolicySpec) DeepCopyInto(out *ApiPolicySpec) {
+	*out = *in
+	if in.PolicyContent != nil {
+		in, out := &in.PolicyContent, &out.PolicyContent
+		*out = new(string)
+		**out = **in
+	}
+	if in.PolicyFormat != nil {
+		in, out := &in.PolicyFormat, &out.PolicyFormat
+		*out = new(PolicyFormat)
+		**out = **in
+	}
+	if in.Templated != nil {
+		in, out := &in.Templated, &out.Templated
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PolicyParameters != nil {
+		in, out := &in.PolicyParameters, &out.PolicyParameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiPolicySpec.
+func (in *ApiPolicySpec) DeepCopy() *ApiPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiRevisionSpec) DeepCopyInto(out *ApiRevisionSpec) {
+	*out = *in
+	if in.HealthCheckUrl != nil {
+		in, out := &in.HealthCheckUrl, &out.HealthCheckUrl
+		*out = new(string)
+		**out = **in
+	}
+	if in.ApprovedRevision != nil {
+		in, out := &in.ApprovedRevision, &out.ApprovedRevision
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiRevisionSpec.
+func (in *ApiRevisionSpec) DeepCopy() *ApiRevisionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiRevisionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiSpec) DeepCopyInto(out *ApiSpec) {
+	*out = *in
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.VersionHeaderName != nil {
+		in, out := &in.VersionHeaderName, &out.VersionHeaderName
+		*out = new(string)
+		**out = **in
+	}
+	if in.VersionQueryName != nil {
+		in, out := &in.VersionQueryName, &out.VersionQueryName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ApiType != nil {
+		in, out := &in.ApiType, &out.ApiType
+		*out = new(APIType)
+		**out = **in
+	}
+	if in.Contact != nil {
+		in, out := &in.Contact, &out.Contact
+		*out = new(APIContactInformation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]ApiVersionSubSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiSpec.
+func (in *ApiSpec) DeepCopy() *ApiSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiStatus) DeepCopyInto(out *ApiStatus) {
+	*out = *in
+	if in.VersionStates != nil {
+		in, out := &in.VersionStates, &out.VersionStates
+		*out = make(map[string]ApiVersionStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	in.AzureResourceStatus.DeepCopyInto(&out.AzureResourceStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiStatus.
+func (in *ApiStatus) DeepCopy() *ApiStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiVersion) DeepCopyInto(out *ApiVersion) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersion.
+func (in *ApiVersion) DeepCopy() *ApiVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApiVersion) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiVersionList) DeepCopyInto(out *ApiVersionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApiVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersionList.
+func (in *ApiVersionList) DeepCopy() *ApiVersionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiVersionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApiVersionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiVersionSpec) DeepCopyInto(out *ApiVersionSpec) {
+	*out = *in
+	if in.APIType != nil {
+		in, out := &in.APIType, &out.APIType
+		*out = new(APIType)
+		**out = **in
+	}
+	if in.Contact != nil {
+		in, out := &in.Contact, &out.Contact
+		*out = new(APIContactInformation)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ApiVersionSubSpec.DeepCopyInto(&out.ApiVersionSubSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersionSpec.
+func (in *ApiVersionSpec) DeepCopy() *ApiVersionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiVersionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiVersionStatus) DeepCopyInto(out *ApiVersionStatus) {
+	*out = *in
+	if in.LastOperationError != nil {
+		in, out := &in.LastOperationError, &out.LastOperationError
+		*out = new(OperationErrorStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BreakingChanges != nil {
+		in, out := &in.BreakingChanges, &out.BreakingChanges
+		*out = make([]BreakingChangeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LintFindings != nil {
+		in, out := &in.LintFindings, &out.LintFindings
+		*out = make([]LintFindingStatus, len(*in))
+		copy(*out, *in)
+	}
+	in.AzureResourceStatus.DeepCopyInto(&out.AzureResourceStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersionStatus.
+func (in *ApiVersionStatus) DeepCopy() *ApiVersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiVersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiVersionSubSpec) DeepCopyInto(out *ApiVersionSubSpec) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.VersionDescription != nil {
+		in, out := &in.VersionDescription, &out.VersionDescription
+		*out = new(string)
+		**out = **in
+	}
+	if in.RevisionDescription != nil {
+		in, out := &in.RevisionDescription, &out.RevisionDescription
+		*out = new(string)
+		**out = **in
+	}
+	if in.License != nil {
+		in, out := &in.License, &out.License
+		*out = new(APILicenseInformation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TermsOfServiceUrl != nil {
+		in, out := &in.TermsOfServiceUrl, &out.TermsOfServiceUrl
+		*out = new(string)
+		**out = **in
+	}
+	if in.AuthenticationSettings != nil {
+		in, out := &in.AuthenticationSettings, &out.AuthenticationSettings
+		*out = new(AuthenticationSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SubscriptionKeyParameterNames != nil {
+		in, out := &in.SubscriptionKeyParameterNames, &out.SubscriptionKeyParameterNames
+		*out = new(SubscriptionKeyParameterNames)
+		**out = **in
+	}
+	if in.ServiceUrl != nil {
+		in, out := &in.ServiceUrl, &out.ServiceUrl
+		*out = new(string)
+		**out = **in
+	}
+	if in.Products != nil {
+		in, out := &in.Products, &out.Products
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContentFormat != nil {
+		in, out := &in.ContentFormat, &out.ContentFormat
+		*out = new(ContentFormat)
+		**out = **in
+	}
+	if in.Content != nil {
+		in, out := &in.Content, &out.Content
+		*out = new(string)
+		**out = **in
+	}
+	if in.ContentFrom != nil {
+		in, out := &in.ContentFrom, &out.ContentFrom
+		*out = new(ContentSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GraphQLSchema != nil {
+		in, out := &in.GraphQLSchema, &out.GraphQLSchema
+		*out = new(GraphQLSchemaSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WsdlSelector != nil {
+		in, out := &in.WsdlSelector, &out.WsdlSelector
+		*out = new(WsdlSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SoapMode != nil {
+		in, out := &in.SoapMode, &out.SoapMode
+		*out = new(SoapMode)
+		**out = **in
+	}
+	if in.WebSocket != nil {
+		in, out := &in.WebSocket, &out.WebSocket
+		*out = new(WebSocketSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SubscriptionRequired != nil {
+		in, out := &in.SubscriptionRequired, &out.SubscriptionRequired
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Protocols != nil {
+		in, out := &in.Protocols, &out.Protocols
+		*out = make([]Protocol, len(*in))
+		copy(*out, *in)
+	}
+	if in.IsCurrent != nil {
+		in, out := &in.IsCurrent, &out.IsCurrent
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(ApiPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReleaseNotes != nil {
+		in, out := &in.ReleaseNotes, &out.ReleaseNotes
+		*out = new(string)
+		**out = **in
+	}
+	if in.Revision != nil {
+		in, out := &in.Revision, &out.Revision
+		*out = new(ApiRevisionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(ApiDeprecationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BreakingChangePolicy != nil {
+		in, out := &in.BreakingChangePolicy, &out.BreakingChangePolicy
+		*out = new(BreakingChangePolicy)
+		**out = **in
+	}
+	if in.Lint != nil {
+		in, out := &in.Lint, &out.Lint
+		*out = new(ApiLintSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersionSubSpec.
+func (in *ApiVersionSubSpec) DeepCopy() *ApiVersionSubSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiVersionSubSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthenticationSettings) DeepCopyInto(out *AuthenticationSettings) {
+	*out = *in
+	if in.OAuth2 != nil {
+		in, out := &in.OAuth2, &out.OAuth2
+		*out = new(OAuth2AuthenticationSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OpenId != nil {
+		in, out := &in.OpenId, &out.OpenId
+		*out = new(OpenIdAuthenticationSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthenticationSettings.
+func (in *AuthenticationSettings) DeepCopy() *AuthenticationSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthenticationSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureResourceStatus) DeepCopyInto(out *AzureResourceStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureResourceStatus.
+func (in *AzureResourceStatus) DeepCopy() *AzureResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Backend) DeepCopyInto(out *Backend) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backend.
+func (in *Backend) DeepCopy() *Backend {
+	if in == nil {
+		return nil
+	}
+	out := new(Backend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Backend) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendList) DeepCopyInto(out *BackendList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Backend, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendList.
+func (in *BackendList) DeepCopy() *BackendList {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackendList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendSpec) DeepCopyInto(out *BackendSpec) {
+	*out = *in
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.ValidateCertificateChain != nil {
+		in, out := &in.ValidateCertificateChain, &out.ValidateCertificateChain
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ValidateCertificateName != nil {
+		in, out := &in.ValidateCertificateName, &out.ValidateCertificateName
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendSpec.
+func (in *BackendSpec) DeepCopy() *BackendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendStatus) DeepCopyInto(out *BackendStatus) {
+	*out = *in
+	in.AzureResourceStatus.DeepCopyInto(&out.AzureResourceStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendStatus.
+func (in *BackendStatus) DeepCopy() *BackendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BreakingChangeStatus) DeepCopyInto(out *BreakingChangeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BreakingChangeStatus.
+func (in *BreakingChangeStatus) DeepCopy() *BreakingChangeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BreakingChangeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContentSource) DeepCopyInto(out *ContentSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContentSource.
+func (in *ContentSource) DeepCopy() *ContentSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ContentSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraphQLHttpDataSource) DeepCopyInto(out *GraphQLHttpDataSource) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Body != nil {
+		in, out := &in.Body, &out.Body
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphQLHttpDataSource.
+func (in *GraphQLHttpDataSource) DeepCopy() *GraphQLHttpDataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GraphQLHttpDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraphQLResolver) DeepCopyInto(out *GraphQLResolver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphQLResolver.
+func (in *GraphQLResolver) DeepCopy() *GraphQLResolver {
 	if in == nil {
 		return nil
 	}
-	out := new(Api)
+	out := new(GraphQLResolver)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Api) DeepCopyObject() runtime.Object {
+func (in *GraphQLResolver) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -82,31 +1069,31 @@ func (in *Api) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApiList) DeepCopyInto(out *ApiList) {
+func (in *GraphQLResolverList) DeepCopyInto(out *GraphQLResolverList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Api, len(*in))
+		*out = make([]GraphQLResolver, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiList.
-func (in *ApiList) DeepCopy() *ApiList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphQLResolverList.
+func (in *GraphQLResolverList) DeepCopy() *GraphQLResolverList {
 	if in == nil {
 		return nil
 	}
-	out := new(ApiList)
+	out := new(GraphQLResolverList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ApiList) DeepCopyObject() runtime.Object {
+func (in *GraphQLResolverList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -114,110 +1101,146 @@ func (in *ApiList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApiPolicySpec) DeepCopyInto(out *ApiPolicySpec) {
+func (in *GraphQLResolverSpec) DeepCopyInto(out *GraphQLResolverSpec) {
 	*out = *in
+	if in.DisplayName != nil {
+		in, out := &in.DisplayName, &out.DisplayName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.HttpDataSource != nil {
+		in, out := &in.HttpDataSource, &out.HttpDataSource
+		*out = new(GraphQLHttpDataSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PolicyContent != nil {
 		in, out := &in.PolicyContent, &out.PolicyContent
 		*out = new(string)
 		**out = **in
 	}
-	if in.PolicyFormat != nil {
-		in, out := &in.PolicyFormat, &out.PolicyFormat
-		*out = new(PolicyFormat)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphQLResolverSpec.
+func (in *GraphQLResolverSpec) DeepCopy() *GraphQLResolverSpec {
+	if in == nil {
+		return nil
 	}
+	out := new(GraphQLResolverSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiPolicySpec.
-func (in *ApiPolicySpec) DeepCopy() *ApiPolicySpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraphQLResolverStatus) DeepCopyInto(out *GraphQLResolverStatus) {
+	*out = *in
+	in.AzureResourceStatus.DeepCopyInto(&out.AzureResourceStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphQLResolverStatus.
+func (in *GraphQLResolverStatus) DeepCopy() *GraphQLResolverStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ApiPolicySpec)
+	out := new(GraphQLResolverStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApiSpec) DeepCopyInto(out *ApiSpec) {
+func (in *GraphQLSchemaSource) DeepCopyInto(out *GraphQLSchemaSource) {
 	*out = *in
-	if in.Description != nil {
-		in, out := &in.Description, &out.Description
+	if in.Sdl != nil {
+		in, out := &in.Sdl, &out.Sdl
 		*out = new(string)
 		**out = **in
 	}
-	if in.ApiType != nil {
-		in, out := &in.ApiType, &out.ApiType
-		*out = new(APIType)
-		**out = **in
-	}
-	if in.Contact != nil {
-		in, out := &in.Contact, &out.Contact
-		*out = new(APIContactInformation)
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Versions != nil {
-		in, out := &in.Versions, &out.Versions
-		*out = make([]ApiVersionSubSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphQLSchemaSource.
+func (in *GraphQLSchemaSource) DeepCopy() *GraphQLSchemaSource {
+	if in == nil {
+		return nil
 	}
+	out := new(GraphQLSchemaSource)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiSpec.
-func (in *ApiSpec) DeepCopy() *ApiSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LintFindingStatus) DeepCopyInto(out *LintFindingStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LintFindingStatus.
+func (in *LintFindingStatus) DeepCopy() *LintFindingStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ApiSpec)
+	out := new(LintFindingStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApiStatus) DeepCopyInto(out *ApiStatus) {
+func (in *LintRule) DeepCopyInto(out *LintRule) {
 	*out = *in
-	if in.VersionStates != nil {
-		in, out := &in.VersionStates, &out.VersionStates
-		*out = make(map[string]ApiVersionStatus, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Field != nil {
+		in, out := &in.Field, &out.Field
+		*out = new(string)
+		**out = **in
+	}
+	if in.Pattern != nil {
+		in, out := &in.Pattern, &out.Pattern
+		*out = new(string)
+		**out = **in
+	}
+	if in.Message != nil {
+		in, out := &in.Message, &out.Message
+		*out = new(string)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiStatus.
-func (in *ApiStatus) DeepCopy() *ApiStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LintRule.
+func (in *LintRule) DeepCopy() *LintRule {
 	if in == nil {
 		return nil
 	}
-	out := new(ApiStatus)
+	out := new(LintRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApiVersion) DeepCopyInto(out *ApiVersion) {
+func (in *LintRuleSet) DeepCopyInto(out *LintRuleSet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersion.
-func (in *ApiVersion) DeepCopy() *ApiVersion {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LintRuleSet.
+func (in *LintRuleSet) DeepCopy() *LintRuleSet {
 	if in == nil {
 		return nil
 	}
-	out := new(ApiVersion)
+	out := new(LintRuleSet)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ApiVersion) DeepCopyObject() runtime.Object {
+func (in *LintRuleSet) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -225,31 +1248,31 @@ func (in *ApiVersion) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApiVersionList) DeepCopyInto(out *ApiVersionList) {
+func (in *LintRuleSetList) DeepCopyInto(out *LintRuleSetList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ApiVersion, len(*in))
+		*out = make([]LintRuleSet, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersionList.
-func (in *ApiVersionList) DeepCopy() *ApiVersionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LintRuleSetList.
+func (in *LintRuleSetList) DeepCopy() *LintRuleSetList {
 	if in == nil {
 		return nil
 	}
-	out := new(ApiVersionList)
+	out := new(LintRuleSetList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ApiVersionList) DeepCopyObject() runtime.Object {
+func (in *LintRuleSetList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -257,206 +1280,158 @@ func (in *ApiVersionList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApiVersionSpec) DeepCopyInto(out *ApiVersionSpec) {
+func (in *LintRuleSetSpec) DeepCopyInto(out *LintRuleSetSpec) {
 	*out = *in
-	if in.APIType != nil {
-		in, out := &in.APIType, &out.APIType
-		*out = new(APIType)
-		**out = **in
-	}
-	if in.Contact != nil {
-		in, out := &in.Contact, &out.Contact
-		*out = new(APIContactInformation)
-		(*in).DeepCopyInto(*out)
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]LintRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	in.ApiVersionSubSpec.DeepCopyInto(&out.ApiVersionSubSpec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersionSpec.
-func (in *ApiVersionSpec) DeepCopy() *ApiVersionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LintRuleSetSpec.
+func (in *LintRuleSetSpec) DeepCopy() *LintRuleSetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ApiVersionSpec)
+	out := new(LintRuleSetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApiVersionStatus) DeepCopyInto(out *ApiVersionStatus) {
+func (in *OAuth2AuthenticationSettings) DeepCopyInto(out *OAuth2AuthenticationSettings) {
 	*out = *in
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersionStatus.
-func (in *ApiVersionStatus) DeepCopy() *ApiVersionStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2AuthenticationSettings.
+func (in *OAuth2AuthenticationSettings) DeepCopy() *OAuth2AuthenticationSettings {
 	if in == nil {
 		return nil
 	}
-	out := new(ApiVersionStatus)
+	out := new(OAuth2AuthenticationSettings)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApiVersionSubSpec) DeepCopyInto(out *ApiVersionSubSpec) {
+func (in *OpenIdAuthenticationSettings) DeepCopyInto(out *OpenIdAuthenticationSettings) {
 	*out = *in
-	if in.Name != nil {
-		in, out := &in.Name, &out.Name
-		*out = new(string)
-		**out = **in
-	}
-	if in.ServiceUrl != nil {
-		in, out := &in.ServiceUrl, &out.ServiceUrl
-		*out = new(string)
-		**out = **in
-	}
-	if in.Products != nil {
-		in, out := &in.Products, &out.Products
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.ContentFormat != nil {
-		in, out := &in.ContentFormat, &out.ContentFormat
-		*out = new(ContentFormat)
-		**out = **in
-	}
-	if in.Content != nil {
-		in, out := &in.Content, &out.Content
-		*out = new(string)
-		**out = **in
-	}
-	if in.SubscriptionRequired != nil {
-		in, out := &in.SubscriptionRequired, &out.SubscriptionRequired
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Protocols != nil {
-		in, out := &in.Protocols, &out.Protocols
-		*out = make([]Protocol, len(*in))
+	if in.BearerTokenSendingMethods != nil {
+		in, out := &in.BearerTokenSendingMethods, &out.BearerTokenSendingMethods
+		*out = make([]BearerTokenSendingMethod, len(*in))
 		copy(*out, *in)
 	}
-	if in.IsCurrent != nil {
-		in, out := &in.IsCurrent, &out.IsCurrent
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Policy != nil {
-		in, out := &in.Policy, &out.Policy
-		*out = new(ApiPolicySpec)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiVersionSubSpec.
-func (in *ApiVersionSubSpec) DeepCopy() *ApiVersionSubSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenIdAuthenticationSettings.
+func (in *OpenIdAuthenticationSettings) DeepCopy() *OpenIdAuthenticationSettings {
 	if in == nil {
 		return nil
 	}
-	out := new(ApiVersionSubSpec)
+	out := new(OpenIdAuthenticationSettings)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Backend) DeepCopyInto(out *Backend) {
+func (in *OperationErrorDetailStatus) DeepCopyInto(out *OperationErrorDetailStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backend.
-func (in *Backend) DeepCopy() *Backend {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationErrorDetailStatus.
+func (in *OperationErrorDetailStatus) DeepCopy() *OperationErrorDetailStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Backend)
+	out := new(OperationErrorDetailStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Backend) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BackendList) DeepCopyInto(out *BackendList) {
+func (in *OperationErrorStatus) DeepCopyInto(out *OperationErrorStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Backend, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Details != nil {
+		in, out := &in.Details, &out.Details
+		*out = make([]OperationErrorDetailStatus, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendList.
-func (in *BackendList) DeepCopy() *BackendList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationErrorStatus.
+func (in *OperationErrorStatus) DeepCopy() *OperationErrorStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(BackendList)
+	out := new(OperationErrorStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *BackendList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionKeyParameterNames) DeepCopyInto(out *SubscriptionKeyParameterNames) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionKeyParameterNames.
+func (in *SubscriptionKeyParameterNames) DeepCopy() *SubscriptionKeyParameterNames {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(SubscriptionKeyParameterNames)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BackendSpec) DeepCopyInto(out *BackendSpec) {
+func (in *WebSocketSpec) DeepCopyInto(out *WebSocketSpec) {
 	*out = *in
-	if in.Description != nil {
-		in, out := &in.Description, &out.Description
-		*out = new(string)
-		**out = **in
-	}
-	if in.ValidateCertificateChain != nil {
-		in, out := &in.ValidateCertificateChain, &out.ValidateCertificateChain
-		*out = new(bool)
-		**out = **in
-	}
-	if in.ValidateCertificateName != nil {
-		in, out := &in.ValidateCertificateName, &out.ValidateCertificateName
-		*out = new(bool)
-		**out = **in
+	if in.OnHandshakePolicy != nil {
+		in, out := &in.OnHandshakePolicy, &out.OnHandshakePolicy
+		*out = new(ApiPolicySpec)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendSpec.
-func (in *BackendSpec) DeepCopy() *BackendSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebSocketSpec.
+func (in *WebSocketSpec) DeepCopy() *WebSocketSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(BackendSpec)
+	out := new(WebSocketSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BackendStatus) DeepCopyInto(out *BackendStatus) {
+func (in *WsdlSelector) DeepCopyInto(out *WsdlSelector) {
 	*out = *in
+	if in.ServiceName != nil {
+		in, out := &in.ServiceName, &out.ServiceName
+		*out = new(string)
+		**out = **in
+	}
+	if in.EndpointName != nil {
+		in, out := &in.EndpointName, &out.EndpointName
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendStatus.
-func (in *BackendStatus) DeepCopy() *BackendStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WsdlSelector.
+func (in *WsdlSelector) DeepCopy() *WsdlSelector {
 	if in == nil {
 		return nil
 	}
-	out := new(BackendStatus)
+	out := new(WsdlSelector)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -17,10 +17,14 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"github.com/tjololo/stilas-az/internal/azure"
+	"github.com/tjololo/stilas-az/internal/tracing"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -32,6 +36,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -59,6 +64,15 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var otlpEndpoint string
+	var otlpInsecure bool
+	var azureQPS float64
+	var azureBurst int
+	var apiResyncPeriod time.Duration
+	var apiVersionResyncPeriod time.Duration
+	var backendResyncPeriod time.Duration
+	var graphQLResolverResyncPeriod time.Duration
+	var apiOperationResyncPeriod time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -70,6 +84,24 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"The host:port of an OTLP gRPC collector to export traces to. Tracing is disabled when empty.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false,
+		"If set, traces are exported to the OTLP collector without TLS.")
+	flag.Float64Var(&azureQPS, "azure-qps", 1,
+		"The number of requests per second sent to the APIM management API, shared by all controllers. Use 0 to disable limiting.")
+	flag.IntVar(&azureBurst, "azure-burst", 10,
+		"The number of requests that can be sent to the APIM management API in a burst above azure-qps.")
+	flag.DurationVar(&apiResyncPeriod, "api-resync-period", controller.DefaultResyncPeriod,
+		"How often Api resources are compared with Azure when nothing has changed.")
+	flag.DurationVar(&apiVersionResyncPeriod, "apiversion-resync-period", controller.DefaultResyncPeriod,
+		"How often ApiVersion resources are compared with Azure when nothing has changed.")
+	flag.DurationVar(&backendResyncPeriod, "backend-resync-period", controller.DefaultResyncPeriod,
+		"How often Backend resources are compared with Azure when nothing has changed.")
+	flag.DurationVar(&graphQLResolverResyncPeriod, "graphqlresolver-resync-period", controller.DefaultResyncPeriod,
+		"How often GraphQLResolver resources are compared with Azure when nothing has changed.")
+	flag.DurationVar(&apiOperationResyncPeriod, "apioperation-resync-period", controller.DefaultResyncPeriod,
+		"How often ApiOperation resources are compared with Azure when nothing has changed.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -78,6 +110,21 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if azureQPS > 0 && azureBurst < 1 {
+		setupLog.Error(fmt.Errorf("azure-burst is %d", azureBurst), "azure-burst must be at least 1 when azure-qps is set")
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		OTLPEndpoint: otlpEndpoint,
+		Insecure:     otlpInsecure,
+		ServiceName:  "stilas-az",
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -145,32 +192,74 @@ func main() {
 		os.Exit(1)
 	}
 
+	limiter := azure.NewRequestLimiter(azureQPS, azureBurst)
+	if err := mgr.Add(limiter); err != nil {
+		setupLog.Error(err, "unable to set up Azure request limiter")
+		os.Exit(1)
+	}
+	newClient := func(config azure.ApimClientConfig) (azure.Client, error) {
+		config.Limiter = limiter
+		apimClient, err := azure.NewAPIMClient(config)
+		if err != nil {
+			return nil, err
+		}
+		return apimClient, nil
+	}
+
 	if err = (&controller.ApiReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		NewClient: azure.NewAPIMClient,
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		NewClient:    newClient,
+		Recorder:     mgr.GetEventRecorderFor("api-controller"),
+		ResyncPeriod: apiResyncPeriod,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Api")
 		os.Exit(1)
 	}
 	if err = (&controller.ApiVersionReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		NewClient: azure.NewAPIMClient,
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		NewClient:    newClient,
+		Recorder:     mgr.GetEventRecorderFor("apiversion-controller"),
+		ResyncPeriod: apiVersionResyncPeriod,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ApiVersion")
 		os.Exit(1)
 	}
 	if err = (&controller.BackendReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		NewClient: azure.NewAPIMClient,
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		NewClient:    newClient,
+		Recorder:     mgr.GetEventRecorderFor("backend-controller"),
+		ResyncPeriod: backendResyncPeriod,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Backend")
 		os.Exit(1)
 	}
+	if err = (&controller.GraphQLResolverReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		NewClient:    newClient,
+		Recorder:     mgr.GetEventRecorderFor("graphqlresolver-controller"),
+		ResyncPeriod: graphQLResolverResyncPeriod,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GraphQLResolver")
+		os.Exit(1)
+	}
+	if err = (&controller.ApiOperationReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		NewClient:    newClient,
+		Recorder:     mgr.GetEventRecorderFor("apioperation-controller"),
+		ResyncPeriod: apiOperationResyncPeriod,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ApiOperation")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	metrics.Registry.MustRegister(controller.NewProvisioningStateCollector(mgr.GetClient()))
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -181,7 +270,13 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctrl.SetupSignalHandler())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "unable to flush traces")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
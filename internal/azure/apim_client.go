@@ -9,19 +9,70 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
 	"net/http"
+	"slices"
 )
 
+// Client is the subset of the APIM management API used by the controllers. It is implemented by APIMClient and by the
+// generated mock in internal/azure/mock.
+type Client interface {
+	GetApiVersionSet(ctx context.Context, apiVersionSetName string, options *apim.APIVersionSetClientGetOptions) (apim.APIVersionSetClientGetResponse, error)
+	CreateUpdateApiVersionSet(ctx context.Context, apiVersionSetName string, parameters apim.APIVersionSetContract, options *apim.APIVersionSetClientCreateOrUpdateOptions) (apim.APIVersionSetClientCreateOrUpdateResponse, error)
+	DeleteApiVersionSet(ctx context.Context, apiVersionSetName string, etag string, options *apim.APIVersionSetClientDeleteOptions) (apim.APIVersionSetClientDeleteResponse, error)
+	GetApi(ctx context.Context, apiId string, options *apim.APIClientGetOptions) (apim.APIClientGetResponse, error)
+	CreateUpdateApi(ctx context.Context, apiId string, parameters apim.APICreateOrUpdateParameter, options *apim.APIClientBeginCreateOrUpdateOptions) (*runtime.Poller[apim.APIClientCreateOrUpdateResponse], error)
+	DeleteApi(ctx context.Context, apiId string, etag string, options *apim.APIClientDeleteOptions) (apim.APIClientDeleteResponse, error)
+	CreateUpdateApiRelease(ctx context.Context, apiId string, releaseId string, parameters apim.APIReleaseContract, options *apim.APIReleaseClientCreateOrUpdateOptions) (apim.APIReleaseClientCreateOrUpdateResponse, error)
+	GetApiPolicy(ctx context.Context, apiId string, options *apim.APIPolicyClientGetOptions) (apim.APIPolicyClientGetResponse, error)
+	CreateUpdateApiPolicy(ctx context.Context, apiId string, parameters apim.PolicyContract, options *apim.APIPolicyClientCreateOrUpdateOptions) (apim.APIPolicyClientCreateOrUpdateResponse, error)
+	DeleteApiPolicy(ctx context.Context, apiId string, etag string, options *apim.APIPolicyClientDeleteOptions) (apim.APIPolicyClientDeleteResponse, error)
+	GetApiOperation(ctx context.Context, apiId string, operationId string, options *apim.APIOperationClientGetOptions) (apim.APIOperationClientGetResponse, error)
+	CreateUpdateApiOperation(ctx context.Context, apiId string, operationId string, parameters apim.OperationContract, options *apim.APIOperationClientCreateOrUpdateOptions) (apim.APIOperationClientCreateOrUpdateResponse, error)
+	DeleteApiOperation(ctx context.Context, apiId string, operationId string, etag string, options *apim.APIOperationClientDeleteOptions) (apim.APIOperationClientDeleteResponse, error)
+	GetApiOperationPolicy(ctx context.Context, apiId string, operationId string, options *apim.APIOperationPolicyClientGetOptions) (apim.APIOperationPolicyClientGetResponse, error)
+	CreateUpdateApiOperationPolicy(ctx context.Context, apiId string, operationId string, parameters apim.PolicyContract, options *apim.APIOperationPolicyClientCreateOrUpdateOptions) (apim.APIOperationPolicyClientCreateOrUpdateResponse, error)
+	DeleteApiOperationPolicy(ctx context.Context, apiId string, operationId string, etag string, options *apim.APIOperationPolicyClientDeleteOptions) (apim.APIOperationPolicyClientDeleteResponse, error)
+	CreateUpdateApiSchema(ctx context.Context, apiId string, schemaId string, parameters apim.SchemaContract, options *apim.APISchemaClientBeginCreateOrUpdateOptions) (*runtime.Poller[apim.APISchemaClientCreateOrUpdateResponse], error)
+	GetGraphQLResolver(ctx context.Context, apiId string, resolverId string, options *apim.GraphQLAPIResolverClientGetOptions) (apim.GraphQLAPIResolverClientGetResponse, error)
+	CreateUpdateGraphQLResolver(ctx context.Context, apiId string, resolverId string, parameters apim.ResolverContract, options *apim.GraphQLAPIResolverClientCreateOrUpdateOptions) (apim.GraphQLAPIResolverClientCreateOrUpdateResponse, error)
+	DeleteGraphQLResolver(ctx context.Context, apiId string, resolverId string, etag string, options *apim.GraphQLAPIResolverClientDeleteOptions) (apim.GraphQLAPIResolverClientDeleteResponse, error)
+	GetGraphQLResolverPolicy(ctx context.Context, apiId string, resolverId string, options *apim.GraphQLAPIResolverPolicyClientGetOptions) (apim.GraphQLAPIResolverPolicyClientGetResponse, error)
+	CreateUpdateGraphQLResolverPolicy(ctx context.Context, apiId string, resolverId string, parameters apim.PolicyContract, options *apim.GraphQLAPIResolverPolicyClientCreateOrUpdateOptions) (apim.GraphQLAPIResolverPolicyClientCreateOrUpdateResponse, error)
+	GetBackend(ctx context.Context, backendId string, options *apim.BackendClientGetOptions) (apim.BackendClientGetResponse, error)
+	CreateUpdateBackend(ctx context.Context, backendId string, parameters apim.BackendContract, options *apim.BackendClientCreateOrUpdateOptions) (apim.BackendClientCreateOrUpdateResponse, error)
+	DeleteBackend(ctx context.Context, backendId string, etag string, options *apim.BackendClientDeleteOptions) (apim.BackendClientDeleteResponse, error)
+}
+
+var _ Client = &APIMClient{}
+
 // APIMClient is a client for interacting with the Azure API Management service
 type APIMClient struct {
 	// ApimClientConfig is the configuration for the APIM client
 	ApimClientConfig  ApimClientConfig
 	apimClientFactory *apim.ClientFactory
+	// importClientFactory uses importApiVersion for imports of API types the default API version does not support
+	importClientFactory *apim.ClientFactory
 }
 
+// importApiVersion is the APIM management API version used to import gRPC and OData APIs. The API version of the SDK
+// predates both API types and rejects their formats.
+const importApiVersion = "2024-05-01"
+
+// API types and import types of importApiVersion that are missing in the SDK
+const (
+	APITypeGrpc      apim.APIType     = "grpc"
+	APITypeOdata     apim.APIType     = "odata"
+	SoapAPITypeGrpc  apim.SoapAPIType = "grpc"
+	SoapAPITypeOdata apim.SoapAPIType = "odata"
+)
+
 // ApimClientConfig is the configuration for the APIMClient
 type ApimClientConfig struct {
-	ClientOptions   *azidentity.DefaultAzureCredentialOptions
-	FactoryOptions  *arm.ClientOptions
+	ClientOptions *azidentity.DefaultAzureCredentialOptions
+	// Credential is used to authenticate to Azure. A DefaultAzureCredential created with ClientOptions is used if nil.
+	Credential     azcore.TokenCredential
+	FactoryOptions *arm.ClientOptions
+	// Limiter is shared by all clients to limit the request rate to the APIM management API. Requests are not limited if nil.
+	Limiter         *RequestLimiter
 	SubscriptionId  string
 	ResourceGroup   string
 	ApimServiceName string
@@ -29,78 +80,221 @@ type ApimClientConfig struct {
 
 // NewAPIMClient creates a new APIMClient
 func NewAPIMClient(config ApimClientConfig) (*APIMClient, error) {
-	credential, err := azidentity.NewDefaultAzureCredential(config.ClientOptions)
+	credential := config.Credential
+	if credential == nil {
+		defaultCredential, err := azidentity.NewDefaultAzureCredential(config.ClientOptions)
+		if err != nil {
+			return nil, err
+		}
+		credential = defaultCredential
+	}
+	factoryOptions := arm.ClientOptions{}
+	if config.FactoryOptions != nil {
+		factoryOptions = *config.FactoryOptions
+	}
+	factoryOptions.PerCallPolicies = append(slices.Clone(factoryOptions.PerCallPolicies), limiterPolicy{limiter: config.Limiter})
+	factoryOptions.PerRetryPolicies = append(slices.Clone(factoryOptions.PerRetryPolicies), tracingPolicy{})
+	clientFactory, err := apim.NewClientFactory(config.SubscriptionId, credential, &factoryOptions)
 	if err != nil {
 		return nil, err
 	}
-	clientFactory, err := apim.NewClientFactory(config.SubscriptionId, credential, config.FactoryOptions)
+	importFactoryOptions := factoryOptions
+	importFactoryOptions.APIVersion = importApiVersion
+	importClientFactory, err := apim.NewClientFactory(config.SubscriptionId, credential, &importFactoryOptions)
 	if err != nil {
 		return nil, err
 	}
 	return &APIMClient{
-		ApimClientConfig:  config,
-		apimClientFactory: clientFactory,
+		ApimClientConfig:    config,
+		apimClientFactory:   clientFactory,
+		importClientFactory: importClientFactory,
 	}, nil
 }
 
 func (c *APIMClient) GetApiVersionSet(ctx context.Context, apiVersionSetName string, options *apim.APIVersionSetClientGetOptions) (apim.APIVersionSetClientGetResponse, error) {
 	client := c.apimClientFactory.NewAPIVersionSetClient()
-	return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiVersionSetName, options)
+	return call(ctx, c, PriorityHigh, "GetApiVersionSet", func(ctx context.Context) (apim.APIVersionSetClientGetResponse, error) {
+		return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiVersionSetName, options)
+	})
 }
 
 func (c *APIMClient) CreateUpdateApiVersionSet(ctx context.Context, apiVersionSetName string, parameters apim.APIVersionSetContract, options *apim.APIVersionSetClientCreateOrUpdateOptions) (apim.APIVersionSetClientCreateOrUpdateResponse, error) {
 	client := c.apimClientFactory.NewAPIVersionSetClient()
-	return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiVersionSetName, parameters, options)
+	return call(ctx, c, PriorityNormal, "CreateUpdateApiVersionSet", func(ctx context.Context) (apim.APIVersionSetClientCreateOrUpdateResponse, error) {
+		return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiVersionSetName, parameters, options)
+	})
 }
 
 func (c *APIMClient) DeleteApiVersionSet(ctx context.Context, apiVersionSetName string, etag string, options *apim.APIVersionSetClientDeleteOptions) (apim.APIVersionSetClientDeleteResponse, error) {
 	client := c.apimClientFactory.NewAPIVersionSetClient()
-	return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiVersionSetName, etag, options)
+	return call(ctx, c, PriorityHigh, "DeleteApiVersionSet", func(ctx context.Context) (apim.APIVersionSetClientDeleteResponse, error) {
+		return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiVersionSetName, etag, options)
+	})
 }
 
 func (c *APIMClient) GetApi(ctx context.Context, apiId string, options *apim.APIClientGetOptions) (apim.APIClientGetResponse, error) {
 	client := c.apimClientFactory.NewAPIClient()
-	return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, options)
+	return call(ctx, c, PriorityHigh, "GetApi", func(ctx context.Context) (apim.APIClientGetResponse, error) {
+		return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, options)
+	})
 }
 
 func (c *APIMClient) CreateUpdateApi(ctx context.Context, apiId string, parameters apim.APICreateOrUpdateParameter, options *apim.APIClientBeginCreateOrUpdateOptions) (*runtime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
 	client := c.apimClientFactory.NewAPIClient()
-	return client.BeginCreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, parameters, options)
+	if requiresImportApiVersion(parameters) {
+		client = c.importClientFactory.NewAPIClient()
+	}
+	return call(ctx, c, PriorityNormal, "CreateUpdateApi", func(ctx context.Context) (*runtime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+		return client.BeginCreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, parameters, options)
+	})
+}
+
+// requiresImportApiVersion returns true if the API is a gRPC or OData API
+func requiresImportApiVersion(parameters apim.APICreateOrUpdateParameter) bool {
+	if parameters.Properties == nil || parameters.Properties.APIType == nil {
+		return false
+	}
+	return *parameters.Properties.APIType == APITypeGrpc || *parameters.Properties.APIType == APITypeOdata
 }
 
 func (c *APIMClient) DeleteApi(ctx context.Context, apiId string, etag string, options *apim.APIClientDeleteOptions) (apim.APIClientDeleteResponse, error) {
 	client := c.apimClientFactory.NewAPIClient()
-	return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, etag, options)
+	return call(ctx, c, PriorityHigh, "DeleteApi", func(ctx context.Context) (apim.APIClientDeleteResponse, error) {
+		return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, etag, options)
+	})
+}
+
+func (c *APIMClient) CreateUpdateApiRelease(ctx context.Context, apiId string, releaseId string, parameters apim.APIReleaseContract, options *apim.APIReleaseClientCreateOrUpdateOptions) (apim.APIReleaseClientCreateOrUpdateResponse, error) {
+	client := c.apimClientFactory.NewAPIReleaseClient()
+	return call(ctx, c, PriorityNormal, "CreateUpdateApiRelease", func(ctx context.Context) (apim.APIReleaseClientCreateOrUpdateResponse, error) {
+		return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, releaseId, parameters, options)
+	})
 }
 
 func (c *APIMClient) GetApiPolicy(ctx context.Context, apiId string, options *apim.APIPolicyClientGetOptions) (apim.APIPolicyClientGetResponse, error) {
 	client := c.apimClientFactory.NewAPIPolicyClient()
-	return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, apim.PolicyIDNamePolicy, options)
+	return call(ctx, c, PriorityHigh, "GetApiPolicy", func(ctx context.Context) (apim.APIPolicyClientGetResponse, error) {
+		return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, apim.PolicyIDNamePolicy, options)
+	})
 }
 
 func (c *APIMClient) CreateUpdateApiPolicy(ctx context.Context, apiId string, parameters apim.PolicyContract, options *apim.APIPolicyClientCreateOrUpdateOptions) (apim.APIPolicyClientCreateOrUpdateResponse, error) {
 	client := c.apimClientFactory.NewAPIPolicyClient()
-	return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, apim.PolicyIDNamePolicy, parameters, options)
+	return call(ctx, c, PriorityNormal, "CreateUpdateApiPolicy", func(ctx context.Context) (apim.APIPolicyClientCreateOrUpdateResponse, error) {
+		return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, apim.PolicyIDNamePolicy, parameters, options)
+	})
 }
 
 func (c *APIMClient) DeleteApiPolicy(ctx context.Context, apiId string, etag string, options *apim.APIPolicyClientDeleteOptions) (apim.APIPolicyClientDeleteResponse, error) {
 	client := c.apimClientFactory.NewAPIPolicyClient()
-	return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, apim.PolicyIDNamePolicy, etag, options)
+	return call(ctx, c, PriorityHigh, "DeleteApiPolicy", func(ctx context.Context) (apim.APIPolicyClientDeleteResponse, error) {
+		return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, apim.PolicyIDNamePolicy, etag, options)
+	})
+}
+
+func (c *APIMClient) GetApiOperation(ctx context.Context, apiId string, operationId string, options *apim.APIOperationClientGetOptions) (apim.APIOperationClientGetResponse, error) {
+	client := c.apimClientFactory.NewAPIOperationClient()
+	return call(ctx, c, PriorityHigh, "GetApiOperation", func(ctx context.Context) (apim.APIOperationClientGetResponse, error) {
+		return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, operationId, options)
+	})
+}
+
+func (c *APIMClient) CreateUpdateApiOperation(ctx context.Context, apiId string, operationId string, parameters apim.OperationContract, options *apim.APIOperationClientCreateOrUpdateOptions) (apim.APIOperationClientCreateOrUpdateResponse, error) {
+	client := c.apimClientFactory.NewAPIOperationClient()
+	return call(ctx, c, PriorityNormal, "CreateUpdateApiOperation", func(ctx context.Context) (apim.APIOperationClientCreateOrUpdateResponse, error) {
+		return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, operationId, parameters, options)
+	})
+}
+
+func (c *APIMClient) DeleteApiOperation(ctx context.Context, apiId string, operationId string, etag string, options *apim.APIOperationClientDeleteOptions) (apim.APIOperationClientDeleteResponse, error) {
+	client := c.apimClientFactory.NewAPIOperationClient()
+	return call(ctx, c, PriorityHigh, "DeleteApiOperation", func(ctx context.Context) (apim.APIOperationClientDeleteResponse, error) {
+		return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, operationId, etag, options)
+	})
+}
+
+func (c *APIMClient) GetApiOperationPolicy(ctx context.Context, apiId string, operationId string, options *apim.APIOperationPolicyClientGetOptions) (apim.APIOperationPolicyClientGetResponse, error) {
+	client := c.apimClientFactory.NewAPIOperationPolicyClient()
+	return call(ctx, c, PriorityHigh, "GetApiOperationPolicy", func(ctx context.Context) (apim.APIOperationPolicyClientGetResponse, error) {
+		return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, operationId, apim.PolicyIDNamePolicy, options)
+	})
+}
+
+func (c *APIMClient) CreateUpdateApiOperationPolicy(ctx context.Context, apiId string, operationId string, parameters apim.PolicyContract, options *apim.APIOperationPolicyClientCreateOrUpdateOptions) (apim.APIOperationPolicyClientCreateOrUpdateResponse, error) {
+	client := c.apimClientFactory.NewAPIOperationPolicyClient()
+	return call(ctx, c, PriorityNormal, "CreateUpdateApiOperationPolicy", func(ctx context.Context) (apim.APIOperationPolicyClientCreateOrUpdateResponse, error) {
+		return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, operationId, apim.PolicyIDNamePolicy, parameters, options)
+	})
+}
+
+func (c *APIMClient) DeleteApiOperationPolicy(ctx context.Context, apiId string, operationId string, etag string, options *apim.APIOperationPolicyClientDeleteOptions) (apim.APIOperationPolicyClientDeleteResponse, error) {
+	client := c.apimClientFactory.NewAPIOperationPolicyClient()
+	return call(ctx, c, PriorityHigh, "DeleteApiOperationPolicy", func(ctx context.Context) (apim.APIOperationPolicyClientDeleteResponse, error) {
+		return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, operationId, apim.PolicyIDNamePolicy, etag, options)
+	})
+}
+
+func (c *APIMClient) CreateUpdateApiSchema(ctx context.Context, apiId string, schemaId string, parameters apim.SchemaContract, options *apim.APISchemaClientBeginCreateOrUpdateOptions) (*runtime.Poller[apim.APISchemaClientCreateOrUpdateResponse], error) {
+	client := c.apimClientFactory.NewAPISchemaClient()
+	return call(ctx, c, PriorityNormal, "CreateUpdateApiSchema", func(ctx context.Context) (*runtime.Poller[apim.APISchemaClientCreateOrUpdateResponse], error) {
+		return client.BeginCreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, schemaId, parameters, options)
+	})
+}
+
+func (c *APIMClient) GetGraphQLResolver(ctx context.Context, apiId string, resolverId string, options *apim.GraphQLAPIResolverClientGetOptions) (apim.GraphQLAPIResolverClientGetResponse, error) {
+	client := c.apimClientFactory.NewGraphQLAPIResolverClient()
+	return call(ctx, c, PriorityHigh, "GetGraphQLResolver", func(ctx context.Context) (apim.GraphQLAPIResolverClientGetResponse, error) {
+		return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, resolverId, options)
+	})
+}
+
+func (c *APIMClient) CreateUpdateGraphQLResolver(ctx context.Context, apiId string, resolverId string, parameters apim.ResolverContract, options *apim.GraphQLAPIResolverClientCreateOrUpdateOptions) (apim.GraphQLAPIResolverClientCreateOrUpdateResponse, error) {
+	client := c.apimClientFactory.NewGraphQLAPIResolverClient()
+	return call(ctx, c, PriorityNormal, "CreateUpdateGraphQLResolver", func(ctx context.Context) (apim.GraphQLAPIResolverClientCreateOrUpdateResponse, error) {
+		return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, resolverId, parameters, options)
+	})
+}
+
+func (c *APIMClient) DeleteGraphQLResolver(ctx context.Context, apiId string, resolverId string, etag string, options *apim.GraphQLAPIResolverClientDeleteOptions) (apim.GraphQLAPIResolverClientDeleteResponse, error) {
+	client := c.apimClientFactory.NewGraphQLAPIResolverClient()
+	return call(ctx, c, PriorityHigh, "DeleteGraphQLResolver", func(ctx context.Context) (apim.GraphQLAPIResolverClientDeleteResponse, error) {
+		return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, resolverId, etag, options)
+	})
+}
+
+func (c *APIMClient) GetGraphQLResolverPolicy(ctx context.Context, apiId string, resolverId string, options *apim.GraphQLAPIResolverPolicyClientGetOptions) (apim.GraphQLAPIResolverPolicyClientGetResponse, error) {
+	client := c.apimClientFactory.NewGraphQLAPIResolverPolicyClient()
+	return call(ctx, c, PriorityHigh, "GetGraphQLResolverPolicy", func(ctx context.Context) (apim.GraphQLAPIResolverPolicyClientGetResponse, error) {
+		return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, resolverId, apim.PolicyIDNamePolicy, options)
+	})
+}
+
+func (c *APIMClient) CreateUpdateGraphQLResolverPolicy(ctx context.Context, apiId string, resolverId string, parameters apim.PolicyContract, options *apim.GraphQLAPIResolverPolicyClientCreateOrUpdateOptions) (apim.GraphQLAPIResolverPolicyClientCreateOrUpdateResponse, error) {
+	client := c.apimClientFactory.NewGraphQLAPIResolverPolicyClient()
+	return call(ctx, c, PriorityNormal, "CreateUpdateGraphQLResolverPolicy", func(ctx context.Context) (apim.GraphQLAPIResolverPolicyClientCreateOrUpdateResponse, error) {
+		return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, apiId, resolverId, apim.PolicyIDNamePolicy, parameters, options)
+	})
 }
 
 func (c *APIMClient) GetBackend(ctx context.Context, backendId string, options *apim.BackendClientGetOptions) (apim.BackendClientGetResponse, error) {
 	client := c.apimClientFactory.NewBackendClient()
-	return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, backendId, options)
+	return call(ctx, c, PriorityHigh, "GetBackend", func(ctx context.Context) (apim.BackendClientGetResponse, error) {
+		return client.Get(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, backendId, options)
+	})
 }
 
 func (c *APIMClient) CreateUpdateBackend(ctx context.Context, backendId string, parameters apim.BackendContract, options *apim.BackendClientCreateOrUpdateOptions) (apim.BackendClientCreateOrUpdateResponse, error) {
 	client := c.apimClientFactory.NewBackendClient()
-	return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, backendId, parameters, options)
+	return call(ctx, c, PriorityNormal, "CreateUpdateBackend", func(ctx context.Context) (apim.BackendClientCreateOrUpdateResponse, error) {
+		return client.CreateOrUpdate(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, backendId, parameters, options)
+	})
 }
 
 func (c *APIMClient) DeleteBackend(ctx context.Context, backendId string, etag string, options *apim.BackendClientDeleteOptions) (apim.BackendClientDeleteResponse, error) {
 	client := c.apimClientFactory.NewBackendClient()
-	return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, backendId, etag, options)
+	return call(ctx, c, PriorityHigh, "DeleteBackend", func(ctx context.Context) (apim.BackendClientDeleteResponse, error) {
+		return client.Delete(ctx, c.ApimClientConfig.ResourceGroup, c.ApimClientConfig.ApimServiceName, backendId, etag, options)
+	})
 }
 
 func IsNotFoundError(err error) bool {
@@ -111,6 +305,14 @@ func IsNotFoundError(err error) bool {
 	return false
 }
 
+func IsPreconditionFailedError(err error) bool {
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return responseError.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
+}
+
 func IgnoreNotFound(err error) error {
 	if IsNotFoundError(err) {
 		return nil
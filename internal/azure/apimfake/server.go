@@ -0,0 +1,444 @@
+// Package apimfake provides an in-memory fake of the Azure API Management REST API used by the operator.
+// The fake is served over TLS by httptest and is injected into the APIMClient through arm.ClientOptions, so
+// controllers can be tested end-to-end without access to Azure.
+package apimfake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	"github.com/tjololo/stilas-az/internal/azure"
+)
+
+// collections lists the resource paths served by the fake, relative to the APIM service.
+// A * matches the name of the parent resource.
+var collections = []string{
+	"apiVersionSets",
+	"apis",
+	"apis/*/policies",
+	"apis/*/operations",
+	"apis/*/operations/*/policies",
+	"apis/*/releases",
+	"apis/*/resolvers",
+	"apis/*/resolvers/*/policies",
+	"apis/*/schemas",
+	"backends",
+	"products",
+	"products/*/apis",
+	"subscriptions",
+}
+
+// grpcOdataApiVersion is the first APIM management API version supporting gRPC and OData APIs
+const grpcOdataApiVersion = "2023-09-01-preview"
+
+// Fault makes the server fail matching requests instead of serving them
+type Fault struct {
+	// Method of the requests to fail. Empty matches all methods.
+	Method string
+	// Path is a substring of the resource path relative to the APIM service, e.g. "apis/default-petstore". Empty matches all paths.
+	Path string
+	// StatusCode returned for matching requests
+	StatusCode int
+	// Code is the Azure error code returned in the body. Defaults to the status text.
+	Code string
+	// RetryAfter is returned in the Retry-After header when set
+	RetryAfter time.Duration
+	// Times is the number of requests to fail. Zero fails all matching requests.
+	Times int
+}
+
+type resource struct {
+	etag int
+	body map[string]any
+}
+
+type operation struct {
+	path      string
+	body      map[string]any
+	remaining int
+}
+
+// Server is an in-memory fake of the APIM management API
+type Server struct {
+	*httptest.Server
+	// LROPolls is the number of polls an API create or update stays in progress. Zero completes the operation synchronously.
+	LROPolls int
+
+	mu         sync.Mutex
+	resources  map[string]*resource
+	operations map[string]*operation
+	faults     []*Fault
+	requests   []string
+	nextId     int
+}
+
+// NewServer starts a new fake APIM server. The server must be closed when the test is done.
+func NewServer() *Server {
+	s := &Server{
+		resources:  make(map[string]*resource),
+		operations: make(map[string]*operation),
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// ClientOptions returns the options that make an APIMClient send its requests to the server
+func (s *Server) ClientOptions() *arm.ClientOptions {
+	return &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Cloud: cloud.Configuration{
+				ActiveDirectoryAuthorityHost: s.URL,
+				Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+					cloud.ResourceManager: {Audience: s.URL, Endpoint: s.URL},
+				},
+			},
+			Transport: s.Client(),
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+		},
+		DisableRPRegistration: true,
+	}
+}
+
+// NewClient creates an APIMClient for the server so it can be used as the NewClient of the reconcilers
+func (s *Server) NewClient(config azure.ApimClientConfig) (azure.Client, error) {
+	config.Credential = fakeCredential{}
+	config.FactoryOptions = s.ClientOptions()
+	apimClient, err := azure.NewAPIMClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return apimClient, nil
+}
+
+// AddFault makes the server fail matching requests
+func (s *Server) AddFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// Resource returns the stored body of the resource at path relative to the APIM service, e.g. "apis/default-petstore"
+func (s *Server) Resource(path string) (map[string]any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, ok := s.resources[key(path)]
+	if !ok {
+		return nil, false
+	}
+	return res.body, true
+}
+
+// SetProperties replaces the properties of the resource at path and changes its ETag, as if it was edited in the portal
+func (s *Server) SetProperties(path string, properties map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store(path, properties)
+}
+
+// Requests returns the requests served so far as "METHOD path" with the path relative to the APIM service
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, ok := servicePath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "InvalidResourceType", "The resource path "+r.URL.Path+" is not served by the fake")
+		return
+	}
+	s.requests = append(s.requests, r.Method+" "+path)
+	if fault := s.matchFault(r.Method, path); fault != nil {
+		if fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+		}
+		code := fault.Code
+		if code == "" {
+			code = strings.ReplaceAll(http.StatusText(fault.StatusCode), " ", "")
+		}
+		writeError(w, fault.StatusCode, code, "Injected fault")
+		return
+	}
+	if id, ok := strings.CutPrefix(path, "operations/"); ok && r.Method == http.MethodGet {
+		s.pollOperation(w, id)
+		return
+	}
+	if !served(path) {
+		writeError(w, http.StatusNotFound, "InvalidResourceType", "The resource type of "+path+" is not served by the fake")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		s.get(w, path)
+	case http.MethodPut:
+		s.put(w, r, path)
+	case http.MethodDelete:
+		s.delete(w, r, path)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method+" is not supported by the fake")
+	}
+}
+
+func (s *Server) get(w http.ResponseWriter, path string) {
+	res, ok := s.resources[key(path)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "ResourceNotFound", "Resource "+path+" not found")
+		return
+	}
+	writeResource(w, http.StatusOK, res)
+}
+
+func (s *Server) put(w http.ResponseWriter, r *http.Request, path string) {
+	if parent := parentPath(path); parent != "" {
+		if _, ok := s.resources[key(parent)]; !ok {
+			writeError(w, http.StatusNotFound, "ResourceNotFound", "Parent resource "+parent+" not found")
+			return
+		}
+	}
+	existing, exists := s.resources[key(path)]
+	if !s.ifMatch(w, r, existing) {
+		return
+	}
+	var body struct {
+		Properties map[string]any `json:"properties"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", "Invalid request body: "+err.Error())
+		return
+	}
+	if body.Properties == nil {
+		body.Properties = map[string]any{}
+	}
+	if isApi(path) {
+		if apiType := body.Properties["type"]; (apiType == "grpc" || apiType == "odata") && r.URL.Query().Get("api-version") < grpcOdataApiVersion {
+			writeError(w, http.StatusBadRequest, "ValidationError", fmt.Sprintf("API type %s is not supported by this API version", apiType))
+			return
+		}
+		apiProperties(path, body.Properties)
+		if s.LROPolls > 0 {
+			s.nextId++
+			id := strconv.Itoa(s.nextId)
+			s.operations[id] = &operation{path: path, body: body.Properties, remaining: s.LROPolls}
+			w.Header().Set("Location", s.URL+"/operations/"+id)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+	}
+	var res *resource
+	if isApi(path) {
+		res = s.storeApi(path, body.Properties)
+	} else {
+		res = s.store(path, body.Properties)
+	}
+	status := http.StatusCreated
+	if exists {
+		status = http.StatusOK
+	}
+	writeResource(w, status, res)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, path string) {
+	existing, ok := s.resources[key(path)]
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if !s.ifMatch(w, r, existing) {
+		return
+	}
+	prefix := key(path) + "/"
+	for k := range s.resources {
+		if k == key(path) || strings.HasPrefix(k, prefix) {
+			delete(s.resources, k)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) pollOperation(w http.ResponseWriter, id string) {
+	op, ok := s.operations[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "ResourceNotFound", "Operation "+id+" not found")
+		return
+	}
+	if op.remaining > 0 {
+		op.remaining--
+		w.Header().Set("Location", s.URL+"/operations/"+id)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	delete(s.operations, id)
+	writeResource(w, http.StatusOK, s.storeApi(op.path, op.body))
+}
+
+// ifMatch checks the If-Match header of the request against the ETag of the resource and writes 412 if it does not match
+func (s *Server) ifMatch(w http.ResponseWriter, r *http.Request, existing *resource) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+	if existing == nil || ifMatch != etag(existing) {
+		writeError(w, http.StatusPreconditionFailed, "PreconditionFailed", "The resource has been modified since it was read")
+		return false
+	}
+	return true
+}
+
+func (s *Server) matchFault(method string, path string) *Fault {
+	for i, fault := range s.faults {
+		if (fault.Method != "" && fault.Method != method) || !strings.Contains(path, fault.Path) {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func (s *Server) store(path string, properties map[string]any) *resource {
+	res, ok := s.resources[key(path)]
+	if !ok {
+		res = &resource{}
+		s.resources[key(path)] = res
+	}
+	res.etag++
+	segments := strings.Split(path, "/")
+	types := make([]string, 0, len(segments)/2)
+	for i := 0; i < len(segments); i += 2 {
+		types = append(types, segments[i])
+	}
+	res.body = map[string]any{
+		"id":         "/" + path,
+		"name":       segments[len(segments)-1],
+		"type":       "Microsoft.ApiManagement/service/" + strings.Join(types, "/"),
+		"properties": properties,
+	}
+	return res
+}
+
+// storeApi stores the API and the operations APIM creates for the API type
+func (s *Server) storeApi(path string, properties map[string]any) *resource {
+	res := s.store(path, properties)
+	operation := path + "/operations/onHandshake"
+	if _, ok := s.resources[key(operation)]; !ok && properties["type"] == "websocket" {
+		s.store(operation, map[string]any{"displayName": "onHandshake", "method": "GET", "urlTemplate": "/"})
+	}
+	return res
+}
+
+// apiProperties sets the properties APIM derives when an API is imported
+func apiProperties(path string, properties map[string]any) {
+	delete(properties, "value")
+	delete(properties, "format")
+	delete(properties, "wsdlSelector")
+	// the import type is stored as the type of the API, a SOAP pass-through API has type soap
+	if apiType, ok := properties["apiType"]; ok {
+		properties["type"] = apiType
+		delete(properties, "apiType")
+	}
+	name := path[strings.LastIndex(path, "/")+1:]
+	if _, revision, ok := strings.Cut(name, ";rev="); ok {
+		properties["apiRevision"] = revision
+		properties["isCurrent"] = false
+		return
+	}
+	if _, ok := properties["apiRevision"]; !ok {
+		properties["apiRevision"] = "1"
+	}
+	properties["isCurrent"] = true
+}
+
+// servicePath returns the path of the request relative to the APIM service
+func servicePath(urlPath string) (string, bool) {
+	if id, ok := strings.CutPrefix(urlPath, "/operations/"); ok {
+		return "operations/" + id, true
+	}
+	segments := strings.Split(strings.Trim(urlPath, "/"), "/")
+	if len(segments) < 9 || segments[0] != "subscriptions" || segments[2] != "resourceGroups" ||
+		segments[4] != "providers" || !strings.EqualFold(segments[5], "Microsoft.ApiManagement") || segments[6] != "service" {
+		return "", false
+	}
+	return strings.Join(segments[8:], "/"), true
+}
+
+// served returns true if the path is a resource in one of the served collections
+func served(path string) bool {
+	segments := strings.Split(path, "/")
+	if len(segments)%2 != 0 {
+		return false
+	}
+	types := make([]string, 0, len(segments)/2)
+	for i := 0; i < len(segments); i += 2 {
+		types = append(types, segments[i])
+	}
+	pattern := strings.Join(types, "/*/")
+	for _, collection := range collections {
+		if collection == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func parentPath(path string) string {
+	segments := strings.Split(path, "/")
+	if len(segments) <= 2 {
+		return ""
+	}
+	return strings.Join(segments[:len(segments)-2], "/")
+}
+
+func isApi(path string) bool {
+	return strings.HasPrefix(path, "apis/") && strings.Count(path, "/") == 1
+}
+
+// key returns the storage key of a path. Azure resource names are case-insensitive.
+func key(path string) string {
+	return strings.ToLower(path)
+}
+
+func etag(res *resource) string {
+	return fmt.Sprintf("\"%d\"", res.etag)
+}
+
+func writeResource(w http.ResponseWriter, status int, res *resource) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(res))
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(res.body)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-ms-error-code", code)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"code": code, "message": message},
+	})
+}
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "fake", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
@@ -0,0 +1,277 @@
+package apimfake
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+
+	"github.com/tjololo/stilas-az/internal/azure"
+)
+
+func newTestClient(t *testing.T) (*Server, azure.Client) {
+	t.Helper()
+	server := NewServer()
+	t.Cleanup(server.Close)
+	client, err := server.NewClient(azure.ApimClientConfig{
+		SubscriptionId:  "sub",
+		ResourceGroup:   "rg",
+		ApimServiceName: "apim",
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return server, client
+}
+
+func TestVersionSetCRUD(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestClient(t)
+
+	_, err := client.GetApiVersionSet(ctx, "petstore", nil)
+	if !azure.IsNotFoundError(err) {
+		t.Fatalf("GetApiVersionSet() error = %v, want not found", err)
+	}
+	created, err := client.CreateUpdateApiVersionSet(ctx, "petstore", apim.APIVersionSetContract{
+		Properties: &apim.APIVersionSetContractProperties{
+			DisplayName:      to.Ptr("Petstore"),
+			VersioningScheme: to.Ptr(apim.VersioningSchemeSegment),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateUpdateApiVersionSet() error = %v", err)
+	}
+	if created.ETag == nil || *created.ETag == "" {
+		t.Error("ETag is empty")
+	}
+	got, err := client.GetApiVersionSet(ctx, "petstore", nil)
+	if err != nil {
+		t.Fatalf("GetApiVersionSet() error = %v", err)
+	}
+	if *got.Properties.DisplayName != "Petstore" || *got.Name != "petstore" {
+		t.Errorf("GetApiVersionSet() = %s %s, want petstore Petstore", *got.Name, *got.Properties.DisplayName)
+	}
+	if _, err := client.DeleteApiVersionSet(ctx, "petstore", *got.ETag, nil); err != nil {
+		t.Fatalf("DeleteApiVersionSet() error = %v", err)
+	}
+	if _, ok := server.Resource("apiVersionSets/petstore"); ok {
+		t.Error("version set was not deleted")
+	}
+}
+
+func TestApiLongRunningOperation(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestClient(t)
+	server.LROPolls = 1
+
+	poller, err := client.CreateUpdateApi(ctx, "petstore-v1", apim.APICreateOrUpdateParameter{
+		Properties: &apim.APICreateOrUpdateProperties{
+			Path:   to.Ptr("petstore"),
+			Format: to.Ptr(apim.ContentFormatOpenapiJSON),
+			Value:  to.Ptr(`{"openapi":"3.0.1"}`),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateUpdateApi() error = %v", err)
+	}
+	op, err := azure.StartResumeOperation(ctx, poller)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if op.Status != azure.OperationStatusInProgress {
+		t.Fatalf("Status = %s, want %s", op.Status, azure.OperationStatusInProgress)
+	}
+	op, err = azure.StartResumeOperation(ctx, poller)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if op.Status != azure.OperationStatusSucceeded {
+		t.Fatalf("Status = %s, want %s", op.Status, azure.OperationStatusSucceeded)
+	}
+	if *op.Result.Properties.Path != "petstore" || !*op.Result.Properties.IsCurrent {
+		t.Errorf("Result = %+v, want current API with path petstore", op.Result.Properties)
+	}
+	api, ok := server.Resource("apis/petstore-v1")
+	if !ok {
+		t.Fatal("API was not stored")
+	}
+	if _, ok := api["properties"].(map[string]any)["value"]; ok {
+		t.Error("imported specification was stored on the API")
+	}
+}
+
+func TestSoapPassThroughImport(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestClient(t)
+
+	poller, err := client.CreateUpdateApi(ctx, "legacy-v1", apim.APICreateOrUpdateParameter{
+		Properties: &apim.APICreateOrUpdateProperties{
+			Path:        to.Ptr("legacy"),
+			Format:      to.Ptr(apim.ContentFormatWsdl),
+			Value:       to.Ptr("<definitions/>"),
+			SoapAPIType: to.Ptr(apim.SoapAPITypeSoapPassThrough),
+			WsdlSelector: &apim.APICreateOrUpdatePropertiesWsdlSelector{
+				WsdlServiceName:  to.Ptr("LegacyService"),
+				WsdlEndpointName: to.Ptr("LegacySoap"),
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateUpdateApi() error = %v", err)
+	}
+	op, err := azure.StartResumeOperation(ctx, poller)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if *op.Result.Properties.APIType != apim.APITypeSoap {
+		t.Errorf("APIType = %s, want %s", *op.Result.Properties.APIType, apim.APITypeSoap)
+	}
+	api, _ := server.Resource("apis/legacy-v1")
+	if _, ok := api["properties"].(map[string]any)["wsdlSelector"]; ok {
+		t.Error("WSDL selector was stored on the API")
+	}
+}
+
+func TestGrpcImportApiVersion(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestClient(t)
+
+	poller, err := client.CreateUpdateApi(ctx, "greeter-v1", apim.APICreateOrUpdateParameter{
+		Properties: &apim.APICreateOrUpdateProperties{
+			Path:        to.Ptr("greeter"),
+			APIType:     to.Ptr(azure.APITypeGrpc),
+			SoapAPIType: to.Ptr(azure.SoapAPITypeGrpc),
+			Format:      to.Ptr(apim.ContentFormat("grpc")),
+			Value:       to.Ptr(`syntax = "proto3";`),
+			ServiceURL:  to.Ptr("https://greeter.example.com"),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateUpdateApi() error = %v", err)
+	}
+	op, err := azure.StartResumeOperation(ctx, poller)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if *op.Result.Properties.APIType != azure.APITypeGrpc {
+		t.Errorf("APIType = %s, want %s", *op.Result.Properties.APIType, azure.APITypeGrpc)
+	}
+}
+
+func TestDeleteCascades(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestClient(t)
+
+	_, err := client.CreateUpdateApiPolicy(ctx, "petstore-v1", apim.PolicyContract{}, nil)
+	if !azure.IsNotFoundError(err) {
+		t.Fatalf("CreateUpdateApiPolicy() error = %v, want not found without parent API", err)
+	}
+	if class := azure.ClassifyError(err).Class; class != azure.ErrorClassTransient {
+		t.Errorf("ClassifyError() = %s, want %s until the parent API exists", class, azure.ErrorClassTransient)
+	}
+	server.SetProperties("apis/petstore-v1", map[string]any{"path": "petstore"})
+	if _, err := client.CreateUpdateApiPolicy(ctx, "petstore-v1", apim.PolicyContract{
+		Properties: &apim.PolicyContractProperties{Value: to.Ptr("<policies/>")},
+	}, nil); err != nil {
+		t.Fatalf("CreateUpdateApiPolicy() error = %v", err)
+	}
+	if _, err := client.DeleteApi(ctx, "petstore-v1", "*", nil); err != nil {
+		t.Fatalf("DeleteApi() error = %v", err)
+	}
+	if _, ok := server.Resource("apis/petstore-v1/policies/policy"); ok {
+		t.Error("policy was not deleted with the API")
+	}
+}
+
+func TestWebSocketHandshakeOperation(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestClient(t)
+
+	poller, err := client.CreateUpdateApi(ctx, "chat-v1", apim.APICreateOrUpdateParameter{
+		Properties: &apim.APICreateOrUpdateProperties{
+			Path:        to.Ptr("chat"),
+			APIType:     to.Ptr(apim.APITypeWebsocket),
+			ServiceURL:  to.Ptr("wss://chat.example.com"),
+			Protocols:   []*apim.Protocol{to.Ptr(apim.ProtocolWss)},
+			DisplayName: to.Ptr("Chat"),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateUpdateApi() error = %v", err)
+	}
+	if _, err := azure.StartResumeOperation(ctx, poller); err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if _, err := client.GetApiOperationPolicy(ctx, "chat-v1", "onHandshake", nil); !azure.IsNotFoundError(err) {
+		t.Fatalf("GetApiOperationPolicy() error = %v, want not found", err)
+	}
+	if _, err := client.CreateUpdateApiOperationPolicy(ctx, "chat-v1", "onHandshake", apim.PolicyContract{
+		Properties: &apim.PolicyContractProperties{Value: to.Ptr("<policies/>")},
+	}, nil); err != nil {
+		t.Fatalf("CreateUpdateApiOperationPolicy() error = %v", err)
+	}
+	if _, err := client.GetApiOperationPolicy(ctx, "chat-v1", "onHandshake", nil); err != nil {
+		t.Fatalf("GetApiOperationPolicy() error = %v", err)
+	}
+}
+
+func TestStaleETag(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestClient(t)
+
+	created, err := client.CreateUpdateBackend(ctx, "petstore", apim.BackendContract{
+		Properties: &apim.BackendContractProperties{URL: to.Ptr("https://petstore.example.com"), Protocol: to.Ptr(apim.BackendProtocolHTTP)},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateUpdateBackend() error = %v", err)
+	}
+	server.SetProperties("backends/petstore", map[string]any{"url": "https://changed.example.com", "protocol": "http"})
+
+	_, err = client.CreateUpdateBackend(ctx, "petstore", apim.BackendContract{
+		Properties: &apim.BackendContractProperties{URL: to.Ptr("https://petstore.example.com"), Protocol: to.Ptr(apim.BackendProtocolHTTP)},
+	}, &apim.BackendClientCreateOrUpdateOptions{IfMatch: created.ETag})
+	if !azure.IsPreconditionFailedError(err) {
+		t.Fatalf("CreateUpdateBackend() error = %v, want precondition failed", err)
+	}
+	if class := azure.ClassifyError(err).Class; class != azure.ErrorClassConflict {
+		t.Errorf("ClassifyError() = %s, want %s", class, azure.ErrorClassConflict)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault Fault
+		want  azure.ErrorClassification
+	}{
+		{
+			name:  "throttled",
+			fault: Fault{Method: http.MethodGet, Path: "backends/petstore", StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second, Times: 1},
+			want:  azure.ErrorClassification{Class: azure.ErrorClassThrottled, RetryAfter: 5 * time.Second},
+		},
+		{
+			name:  "conflict",
+			fault: Fault{Path: "backends/", StatusCode: http.StatusConflict, Code: "Conflict", Times: 1},
+			want:  azure.ErrorClassification{Class: azure.ErrorClassConflict},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, client := newTestClient(t)
+			server.SetProperties("backends/petstore", map[string]any{"url": "https://petstore.example.com", "protocol": "http"})
+			server.AddFault(tt.fault)
+
+			_, err := client.GetBackend(ctx, "petstore", nil)
+			if got := azure.ClassifyError(err); got != tt.want {
+				t.Fatalf("ClassifyError() = %+v, want %+v", got, tt.want)
+			}
+			if _, err := client.GetBackend(ctx, "petstore", nil); err != nil {
+				t.Errorf("GetBackend() error = %v after the fault was used up", err)
+			}
+		})
+	}
+}
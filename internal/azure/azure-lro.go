@@ -2,9 +2,16 @@ package azure
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"net/http"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
 )
 
 type OperationStatus string
@@ -15,27 +22,125 @@ const (
 	OperationStatusFailed     OperationStatus = "Failed"
 )
 
-func StartResumeOperation[T any](ctx context.Context, poller *runtime.Poller[T]) (status OperationStatus, result T, resumeToken string, err error) {
+// Operation is the state of a long-running operation after it has been started or resumed
+type Operation[T any] struct {
+	Status OperationStatus
+	// Result is set when Status is Succeeded
+	Result T
+	// ResumeToken is set when Status is InProgress and must be persisted to resume the operation in a later reconcile
+	ResumeToken string
+	// RetryAfter is the delay requested by Azure before the operation is polled again. Zero if Azure did not request a delay.
+	RetryAfter time.Duration
+	// Error is set when Status is Failed
+	Error *OperationError
+}
+
+// OperationError is the error reported by Azure for a failed long-running operation
+type OperationError struct {
+	StatusCode int                    `json:"-"`
+	Code       string                 `json:"code"`
+	Message    string                 `json:"message"`
+	Target     string                 `json:"target,omitempty"`
+	Details    []OperationErrorDetail `json:"details,omitempty"`
+}
+
+// OperationErrorDetail is a detail of an OperationError, typically one per validation failure
+type OperationErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Target  string `json:"target,omitempty"`
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// StartResumeOperation polls the operation once and returns its state. The poller handles the 202 Accepted,
+// Location and Azure-AsyncOperation semantics of Azure long-running operations.
+// A returned error means the operation could not be polled, in which case the resume token is still returned when
+// available so the operation can be resumed instead of started again. Failures of the operation itself are reported
+// with Status Failed and the error details returned by Azure.
+func StartResumeOperation[T any](ctx context.Context, poller *runtime.Poller[T]) (op Operation[T], err error) {
 	logger := log.FromContext(ctx)
-	res, err := poller.Poll(ctx)
-	if err != nil {
-		status = OperationStatusFailed
-		return
-	}
-	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
-		status = OperationStatusFailed
-		err = runtime.NewResponseError(res)
-		return
+	ctx, span := tracer.Start(ctx, "StartResumeOperation")
+	start := time.Now()
+	defer func() {
+		lroDuration.WithLabelValues(string(op.Status)).Observe(time.Since(start).Seconds())
+		span.SetAttributes(attribute.String("operation.status", string(op.Status)))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if op.Error != nil {
+			span.SetStatus(codes.Error, op.Error.Error())
+		}
+		span.End()
+	}()
+	if !poller.Done() {
+		resp, pollErr := poller.Poll(ctx)
+		if pollErr != nil {
+			op.Status = OperationStatusInProgress
+			op.ResumeToken, _ = poller.ResumeToken()
+			err = pollErr
+			return
+		}
+		if d, ok := retryAfterHeader(resp); ok {
+			op.RetryAfter = d
+		}
 	}
-	if poller.Done() {
-		status = OperationStatusSucceeded
-		result, err = poller.Result(ctx)
-	} else {
-		status = OperationStatusInProgress
-		resumeToken, err = poller.ResumeToken()
+	if !poller.Done() {
+		op.Status = OperationStatusInProgress
+		op.ResumeToken, err = poller.ResumeToken()
 		if err != nil {
 			logger.Error(err, "Failed to get resume Token")
 		}
+		return
 	}
+	result, resultErr := poller.Result(ctx)
+	if resultErr != nil {
+		var responseError *azcore.ResponseError
+		if !errors.As(resultErr, &responseError) || retryable(responseError.StatusCode) {
+			// the final result could not be fetched, the operation itself did not fail
+			op.Status = OperationStatusInProgress
+			op.ResumeToken, _ = poller.ResumeToken()
+			err = resultErr
+			return
+		}
+		op.Status = OperationStatusFailed
+		op.Error = NewOperationError(resultErr)
+		return
+	}
+	op.Status = OperationStatusSucceeded
+	op.Result = result
 	return
 }
+
+// retryable returns true for HTTP status codes that do not end a long-running operation
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// NewOperationError extracts the error details from the body of an Azure error response.
+// The message of err is used when the body does not contain an Azure error.
+func NewOperationError(err error) *OperationError {
+	opErr := &OperationError{Code: ErrorCode(err), Message: err.Error()}
+	var responseError *azcore.ResponseError
+	if !errors.As(err, &responseError) {
+		return opErr
+	}
+	opErr.StatusCode = responseError.StatusCode
+	if responseError.RawResponse == nil {
+		return opErr
+	}
+	body, readErr := runtime.Payload(responseError.RawResponse)
+	if readErr != nil {
+		return opErr
+	}
+	var payload struct {
+		Error *OperationError `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) != nil || payload.Error == nil {
+		return opErr
+	}
+	payload.Error.StatusCode = responseError.StatusCode
+	return payload.Error
+}
@@ -0,0 +1,206 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+const (
+	resourceURL  = "https://management.azure.com/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ApiManagement/service/apim/apis/api"
+	operationURL = "https://management.azure.com/subscriptions/sub/providers/Microsoft.ApiManagement/locations/westeurope/operationResults/op"
+)
+
+type testResource struct {
+	Name string `json:"name"`
+}
+
+// fakeTransport returns the queued responses for each request in order
+type fakeTransport struct {
+	responses map[string][]*http.Response
+}
+
+func (f *fakeTransport) Do(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.String()
+	queue := f.responses[key]
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("unexpected request %s", key)
+	}
+	f.responses[key] = queue[1:]
+	resp := queue[0]
+	resp.Request = req
+	return resp, nil
+}
+
+func fakeResponse(statusCode int, body string, header http.Header) *http.Response {
+	canonical := http.Header{"Content-Type": []string{"application/json"}}
+	for key, values := range header {
+		canonical[http.CanonicalHeaderKey(key)] = values
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     canonical,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func testPipeline(transport *fakeTransport) runtime.Pipeline {
+	return runtime.NewPipeline("test", "v0.0.1", runtime.PipelineOptions{}, &policy.ClientOptions{
+		Transport: transport,
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+}
+
+func startPoller(t *testing.T, pl runtime.Pipeline, initial *http.Response) *runtime.Poller[testResource] {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPut, resourceURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial.Request = req
+	poller, err := runtime.NewPoller[testResource](initial, pl, nil)
+	if err != nil {
+		t.Fatalf("NewPoller() error = %v", err)
+	}
+	return poller
+}
+
+func TestStartResumeOperationAsyncOperation(t *testing.T) {
+	ctx := context.Background()
+	transport := &fakeTransport{responses: map[string][]*http.Response{
+		"GET " + operationURL: {
+			fakeResponse(http.StatusOK, `{"status":"InProgress"}`, http.Header{"Retry-After": []string{"7"}}),
+			fakeResponse(http.StatusOK, `{"status":"Succeeded"}`, nil),
+		},
+		"GET " + resourceURL: {
+			fakeResponse(http.StatusOK, `{"name":"api"}`, nil),
+		},
+	}}
+	pl := testPipeline(transport)
+	poller := startPoller(t, pl, fakeResponse(http.StatusAccepted, "", http.Header{"Azure-AsyncOperation": []string{operationURL}}))
+
+	op, err := StartResumeOperation(ctx, poller)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if op.Status != OperationStatusInProgress {
+		t.Fatalf("Status = %s, want %s", op.Status, OperationStatusInProgress)
+	}
+	if op.ResumeToken == "" {
+		t.Fatal("ResumeToken is empty for an operation in progress")
+	}
+	if op.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %s, want 7s", op.RetryAfter)
+	}
+
+	resumed, err := runtime.NewPollerFromResumeToken[testResource](op.ResumeToken, pl, nil)
+	if err != nil {
+		t.Fatalf("NewPollerFromResumeToken() error = %v", err)
+	}
+	op, err = StartResumeOperation(ctx, resumed)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if op.Status != OperationStatusSucceeded {
+		t.Fatalf("Status = %s, want %s", op.Status, OperationStatusSucceeded)
+	}
+	if op.Result.Name != "api" {
+		t.Errorf("Result.Name = %q, want %q", op.Result.Name, "api")
+	}
+}
+
+func TestStartResumeOperationLocation(t *testing.T) {
+	ctx := context.Background()
+	transport := &fakeTransport{responses: map[string][]*http.Response{
+		"GET " + operationURL: {
+			fakeResponse(http.StatusAccepted, "", http.Header{"Location": []string{operationURL}}),
+			fakeResponse(http.StatusOK, `{"name":"api"}`, nil),
+		},
+	}}
+	pl := testPipeline(transport)
+	poller := startPoller(t, pl, fakeResponse(http.StatusAccepted, "", http.Header{"Location": []string{operationURL}}))
+
+	op, err := StartResumeOperation(ctx, poller)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if op.Status != OperationStatusInProgress {
+		t.Fatalf("Status = %s, want %s", op.Status, OperationStatusInProgress)
+	}
+	op, err = StartResumeOperation(ctx, poller)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if op.Status != OperationStatusSucceeded || op.Result.Name != "api" {
+		t.Errorf("got %s %+v, want Succeeded with result", op.Status, op.Result)
+	}
+}
+
+func TestStartResumeOperationCompletedSynchronously(t *testing.T) {
+	pl := testPipeline(&fakeTransport{responses: map[string][]*http.Response{}})
+	poller := startPoller(t, pl, fakeResponse(http.StatusOK, `{"name":"api"}`, nil))
+
+	op, err := StartResumeOperation(context.Background(), poller)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if op.Status != OperationStatusSucceeded || op.Result.Name != "api" {
+		t.Errorf("got %s %+v, want Succeeded with result", op.Status, op.Result)
+	}
+}
+
+func TestStartResumeOperationFailed(t *testing.T) {
+	transport := &fakeTransport{responses: map[string][]*http.Response{
+		"GET " + operationURL: {
+			fakeResponse(http.StatusOK, `{"status":"Failed","error":{"code":"ValidationError","message":"One or more fields contain incorrect values","details":[{"code":"ValidationError","target":"representation","message":"Parsing error(s): JSON is not valid"}]}}`, nil),
+		},
+	}}
+	pl := testPipeline(transport)
+	poller := startPoller(t, pl, fakeResponse(http.StatusAccepted, "", http.Header{"Azure-AsyncOperation": []string{operationURL}}))
+
+	op, err := StartResumeOperation(context.Background(), poller)
+	if err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	if op.Status != OperationStatusFailed {
+		t.Fatalf("Status = %s, want %s", op.Status, OperationStatusFailed)
+	}
+	if op.Error == nil || op.Error.Code != "ValidationError" {
+		t.Fatalf("Error = %+v, want ValidationError", op.Error)
+	}
+	if len(op.Error.Details) != 1 || op.Error.Details[0].Target != "representation" {
+		t.Errorf("Details = %+v, want one detail targeting representation", op.Error.Details)
+	}
+}
+
+func TestStartResumeOperationPollError(t *testing.T) {
+	transport := &fakeTransport{responses: map[string][]*http.Response{
+		"GET " + operationURL: {
+			fakeResponse(http.StatusServiceUnavailable, `{"error":{"code":"ServiceUnavailable","message":"try again"}}`, nil),
+		},
+	}}
+	pl := testPipeline(transport)
+	poller := startPoller(t, pl, fakeResponse(http.StatusAccepted, "", http.Header{"Azure-AsyncOperation": []string{operationURL}}))
+
+	op, err := StartResumeOperation(context.Background(), poller)
+	if err == nil {
+		t.Fatal("expected error when polling fails")
+	}
+	if op.Status != OperationStatusInProgress {
+		t.Errorf("Status = %s, want %s", op.Status, OperationStatusInProgress)
+	}
+	if op.ResumeToken == "" {
+		t.Error("ResumeToken is empty, the operation can not be resumed")
+	}
+	if class := ClassifyError(err).Class; class != ErrorClassTransient {
+		t.Errorf("ClassifyError() = %s, want %s", class, ErrorClassTransient)
+	}
+}
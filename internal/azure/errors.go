@@ -0,0 +1,129 @@
+package azure
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// ErrorClass describes how a failed Azure call should be retried
+type ErrorClass string
+
+const (
+	// ErrorClassThrottled - Azure rejected the call because of rate limiting. Retry after RetryAfter.
+	ErrorClassThrottled ErrorClass = "Throttled"
+	// ErrorClassTransient - Server side or network failure. Retry with backoff.
+	ErrorClassTransient ErrorClass = "Transient"
+	// ErrorClassConflict - The resource was modified concurrently. Re-read and retry immediately.
+	ErrorClassConflict ErrorClass = "Conflict"
+	// ErrorClassPermanent - The request is invalid and will fail until it is changed.
+	ErrorClassPermanent ErrorClass = "Permanent"
+)
+
+// DefaultRetryAfter is used for throttled calls when Azure does not return a Retry-After header
+const DefaultRetryAfter = 30 * time.Second
+
+// ErrorClassification is the retry decision for a failed Azure call
+type ErrorClassification struct {
+	Class      ErrorClass
+	RetryAfter time.Duration
+}
+
+// classifiedError is an error with a class chosen by the caller
+type classifiedError struct {
+	err   error
+	class ErrorClass
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+// WithErrorClass returns err with the class ClassifyError returns for it, for errors the caller knows better how to retry
+func WithErrorClass(err error, class ErrorClass) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, class: class}
+}
+
+// ClassifyError returns the retry decision for err. Errors that are not Azure response errors are treated as transient
+// unless they are classified with WithErrorClass.
+func ClassifyError(err error) ErrorClassification {
+	var classified *classifiedError
+	if errors.As(err, &classified) {
+		if classified.class == ErrorClassThrottled {
+			return ErrorClassification{Class: ErrorClassThrottled, RetryAfter: DefaultRetryAfter}
+		}
+		return ErrorClassification{Class: classified.class}
+	}
+	var responseError *azcore.ResponseError
+	if !errors.As(err, &responseError) {
+		return ErrorClassification{Class: ErrorClassTransient}
+	}
+	switch code := responseError.StatusCode; {
+	case code == http.StatusTooManyRequests:
+		return ErrorClassification{Class: ErrorClassThrottled, RetryAfter: retryAfter(responseError.RawResponse)}
+	case code == http.StatusConflict || code == http.StatusPreconditionFailed:
+		return ErrorClassification{Class: ErrorClassConflict}
+	case code == http.StatusRequestTimeout || code >= http.StatusInternalServerError:
+		return ErrorClassification{Class: ErrorClassTransient}
+	case code == http.StatusNotFound && isWrite(responseError.RawResponse):
+		// the parent of the resource, e.g. the API of a policy, may not have been created yet
+		return ErrorClassification{Class: ErrorClassTransient}
+	case code >= http.StatusBadRequest:
+		return ErrorClassification{Class: ErrorClassPermanent}
+	default:
+		return ErrorClassification{Class: ErrorClassTransient}
+	}
+}
+
+// isWrite returns true if the response is for a request that creates, updates or deletes a resource
+func isWrite(resp *http.Response) bool {
+	if resp == nil || resp.Request == nil {
+		return false
+	}
+	switch resp.Request.Method {
+	case http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter reads the delay requested by Azure from the response headers
+func retryAfter(resp *http.Response) time.Duration {
+	if d, ok := retryAfterHeader(resp); ok {
+		return d
+	}
+	return DefaultRetryAfter
+}
+
+// retryAfterHeader returns the delay in the retry-after-ms, x-ms-retry-after-ms or Retry-After header of the response
+func retryAfterHeader(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	for _, header := range []string{"retry-after-ms", "x-ms-retry-after-ms"} {
+		if ms, err := strconv.Atoi(resp.Header.Get(header)); err == nil && ms > 0 {
+			return time.Duration(ms) * time.Millisecond, true
+		}
+	}
+	value := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
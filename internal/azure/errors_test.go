@@ -0,0 +1,54 @@
+package azure
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+func responseError(statusCode int, header http.Header) error {
+	return &azcore.ResponseError{
+		StatusCode:  statusCode,
+		RawResponse: &http.Response{StatusCode: statusCode, Header: header},
+	}
+}
+
+func requestError(method string, statusCode int) error {
+	return &azcore.ResponseError{
+		StatusCode:  statusCode,
+		RawResponse: &http.Response{StatusCode: statusCode, Request: &http.Request{Method: method}},
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClassification
+	}{
+		{"throttled with retry-after seconds", responseError(429, http.Header{"Retry-After": []string{"12"}}), ErrorClassification{Class: ErrorClassThrottled, RetryAfter: 12 * time.Second}},
+		{"throttled with retry-after-ms", responseError(429, http.Header{"Retry-After-Ms": []string{"1500"}}), ErrorClassification{Class: ErrorClassThrottled, RetryAfter: 1500 * time.Millisecond}},
+		{"throttled without header", responseError(429, http.Header{}), ErrorClassification{Class: ErrorClassThrottled, RetryAfter: DefaultRetryAfter}},
+		{"server error", responseError(503, http.Header{}), ErrorClassification{Class: ErrorClassTransient}},
+		{"conflict", responseError(409, http.Header{}), ErrorClassification{Class: ErrorClassConflict}},
+		{"precondition failed", responseError(412, http.Header{}), ErrorClassification{Class: ErrorClassConflict}},
+		{"validation error", responseError(400, http.Header{}), ErrorClassification{Class: ErrorClassPermanent}},
+		{"not found on read", requestError(http.MethodGet, 404), ErrorClassification{Class: ErrorClassPermanent}},
+		{"not found on write", requestError(http.MethodPut, 404), ErrorClassification{Class: ErrorClassTransient}},
+		{"validation error on write", requestError(http.MethodPut, 400), ErrorClassification{Class: ErrorClassPermanent}},
+		{"wrapped validation error", fmt.Errorf("failed: %w", responseError(400, http.Header{})), ErrorClassification{Class: ErrorClassPermanent}},
+		{"network error", fmt.Errorf("connection reset"), ErrorClassification{Class: ErrorClassTransient}},
+		{"classified error", fmt.Errorf("failed: %w", WithErrorClass(fmt.Errorf("invalid template"), ErrorClassPermanent)), ErrorClassification{Class: ErrorClassPermanent}},
+		{"classified response error", WithErrorClass(responseError(400, http.Header{}), ErrorClassTransient), ErrorClassification{Class: ErrorClassTransient}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Priority is the priority of a request waiting for the RequestLimiter
+type Priority int
+
+const (
+	// PriorityNormal is used for creates and updates
+	PriorityNormal Priority = iota
+	// PriorityHigh is used for deletes and reads. High priority requests are served before any waiting normal priority request.
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	if p == PriorityHigh {
+		return "high"
+	}
+	return "normal"
+}
+
+var (
+	limiterWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "stilas_az_azure_limiter_wait_seconds",
+			Help:    "Time requests to the Azure API Management API waited for the request limiter per priority",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"priority"},
+	)
+	limiterQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stilas_az_azure_limiter_queue_depth",
+			Help: "Number of requests to the Azure API Management API waiting for the request limiter per priority",
+		},
+		[]string{"priority"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(limiterWait, limiterQueueDepth)
+}
+
+// RequestLimiter is a token bucket limiter shared by all APIMClients. Waiting requests are granted tokens in priority order.
+// The limiter hands out tokens while Start is running, so it must be added to the manager.
+type RequestLimiter struct {
+	limiter *rate.Limiter
+	mu      sync.Mutex
+	queues  [PriorityHigh + 1][]chan struct{}
+	signal  chan struct{}
+}
+
+// NewRequestLimiter creates a RequestLimiter allowing qps requests per second with bursts of up to burst requests.
+// A qps of zero or less disables limiting.
+func NewRequestLimiter(qps float64, burst int) *RequestLimiter {
+	limit := rate.Limit(qps)
+	if qps <= 0 {
+		limit = rate.Inf
+	}
+	return &RequestLimiter{
+		limiter: rate.NewLimiter(limit, burst),
+		signal:  make(chan struct{}, 1),
+	}
+}
+
+// Wait blocks until the request is granted a token or ctx is done. A nil RequestLimiter does not limit requests.
+func (l *RequestLimiter) Wait(ctx context.Context, priority Priority) error {
+	if l == nil {
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		limiterWait.WithLabelValues(priority.String()).Observe(time.Since(start).Seconds())
+	}()
+	ticket := make(chan struct{})
+	l.mu.Lock()
+	l.queues[priority] = append(l.queues[priority], ticket)
+	limiterQueueDepth.WithLabelValues(priority.String()).Inc()
+	l.mu.Unlock()
+	select {
+	case l.signal <- struct{}{}:
+	default:
+	}
+	select {
+	case <-ticket:
+		return nil
+	case <-ctx.Done():
+		l.cancel(priority, ticket)
+		return ctx.Err()
+	}
+}
+
+// cancel removes the ticket of a cancelled request from its queue. A ticket that is no longer queued was granted while
+// the request was cancelled, its token is passed on to the next waiting request so it is not lost.
+func (l *RequestLimiter) cancel(priority Priority, ticket chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if i := slices.Index(l.queues[priority], ticket); i >= 0 {
+		l.queues[priority] = slices.Delete(l.queues[priority], i, i+1)
+		limiterQueueDepth.WithLabelValues(priority.String()).Dec()
+		return
+	}
+	l.grantLocked()
+}
+
+// Start hands out tokens to waiting requests until ctx is done. An error is returned if the limiter cannot hand out
+// tokens, e.g. when the burst is zero, since requests would otherwise wait forever.
+func (l *RequestLimiter) Start(ctx context.Context) error {
+	for {
+		if !l.waiting() {
+			select {
+			case <-l.signal:
+				continue
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		if err := l.limiter.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("request limiter cannot grant tokens: %w", err)
+		}
+		l.grant()
+	}
+}
+
+func (l *RequestLimiter) waiting() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.queues[PriorityHigh]) > 0 || len(l.queues[PriorityNormal]) > 0
+}
+
+// grant releases the longest waiting request with the highest priority
+func (l *RequestLimiter) grant() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.grantLocked()
+}
+
+// grantLocked is grant for callers holding the lock
+func (l *RequestLimiter) grantLocked() {
+	for priority := PriorityHigh; priority >= PriorityNormal; priority-- {
+		if len(l.queues[priority]) == 0 {
+			continue
+		}
+		close(l.queues[priority][0])
+		l.queues[priority] = l.queues[priority][1:]
+		limiterQueueDepth.WithLabelValues(priority.String()).Dec()
+		return
+	}
+}
+
+// limitedKey marks the context of a request that already waited for the RequestLimiter
+type limitedKey struct{}
+
+// limiterPolicy is an azcore pipeline policy that makes requests not sent by a call of the APIMClient, such as the polls
+// of long-running operations, wait for the RequestLimiter with normal priority. Calls wait with their own priority
+// before the request is sent.
+type limiterPolicy struct {
+	limiter *RequestLimiter
+}
+
+func (p limiterPolicy) Do(req *policy.Request) (*http.Response, error) {
+	ctx := req.Raw().Context()
+	if ctx.Value(limitedKey{}) == nil {
+		if err := p.limiter.Wait(ctx, PriorityNormal); err != nil {
+			return nil, err
+		}
+	}
+	return req.Next()
+}
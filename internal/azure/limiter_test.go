@@ -0,0 +1,130 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+func TestRequestLimiterGrantsHighPriorityFirst(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	limiter := NewRequestLimiter(10, 1)
+	granted := make(chan Priority, 2)
+	for _, priority := range []Priority{PriorityNormal, PriorityHigh} {
+		go func() {
+			if err := limiter.Wait(ctx, priority); err == nil {
+				granted <- priority
+			}
+		}()
+		for !queued(limiter, priority) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	go func() { _ = limiter.Start(ctx) }()
+	if first := <-granted; first != PriorityHigh {
+		t.Errorf("first granted priority = %v, want %v", first, PriorityHigh)
+	}
+	if second := <-granted; second != PriorityNormal {
+		t.Errorf("second granted priority = %v, want %v", second, PriorityNormal)
+	}
+}
+
+func TestRequestLimiterWaitCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	limiter := NewRequestLimiter(10, 1)
+	cancel()
+	if err := limiter.Wait(ctx, PriorityNormal); err == nil {
+		t.Fatal("expected error when context is cancelled")
+	}
+	if queued(limiter, PriorityNormal) {
+		t.Error("cancelled request is still queued")
+	}
+}
+
+func TestRequestLimiterPassesOnTokenOfCancelledRequest(t *testing.T) {
+	limiter := NewRequestLimiter(10, 1)
+	cancelled, next := make(chan struct{}), make(chan struct{})
+	limiter.queues[PriorityNormal] = []chan struct{}{cancelled, next}
+	limiterQueueDepth.WithLabelValues(PriorityNormal.String()).Add(2)
+	// the request is cancelled after its ticket was granted
+	limiter.grant()
+	limiter.cancel(PriorityNormal, cancelled)
+	select {
+	case <-next:
+	default:
+		t.Error("token of the cancelled request was not passed on to the next request")
+	}
+	if queued(limiter, PriorityNormal) {
+		t.Error("granted request is still queued")
+	}
+}
+
+func TestNilRequestLimiterDoesNotLimit(t *testing.T) {
+	var limiter *RequestLimiter
+	if err := limiter.Wait(context.Background(), PriorityNormal); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+}
+
+func TestRequestLimiterStartFailsWithoutBurst(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	limiter := NewRequestLimiter(10, 0)
+	go func() { _ = limiter.Wait(ctx, PriorityNormal) }()
+	for !queued(limiter, PriorityNormal) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := limiter.Start(ctx); err == nil || ctx.Err() != nil {
+		t.Errorf("Start() error = %v, want an error before the context is done", err)
+	}
+}
+
+func TestLimiterPolicyLimitsUncalledRequests(t *testing.T) {
+	limiter := NewRequestLimiter(10, 1)
+	transport := &fakeTransport{responses: map[string][]*http.Response{
+		"GET " + operationURL: {
+			fakeResponse(http.StatusOK, `{"status":"InProgress"}`, nil),
+			fakeResponse(http.StatusOK, `{"status":"InProgress"}`, nil),
+		},
+	}}
+	pl := runtime.NewPipeline("test", "v0.0.1", runtime.PipelineOptions{PerCall: []policy.Policy{limiterPolicy{limiter: limiter}}}, &policy.ClientOptions{
+		Transport: transport,
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+	send := func(ctx context.Context) error {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, operationURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = pl.Do(req)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := send(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("poll error = %v, want the poll to wait for the limiter", err)
+	}
+	if err := send(context.WithValue(context.Background(), limitedKey{}, true)); err != nil {
+		t.Errorf("call error = %v, want calls that already waited to pass", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = limiter.Start(ctx) }()
+	if err := send(ctx); err != nil {
+		t.Errorf("poll error = %v, want the poll to be granted a token", err)
+	}
+}
+
+func queued(l *RequestLimiter, priority Priority) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.queues[priority]) > 0
+}
@@ -0,0 +1,84 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/codes"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	apiCallsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stilas_az_azure_api_calls_total",
+			Help: "Total number of calls to the Azure API Management API per operation and result",
+		},
+		[]string{"operation", "result", "code"},
+	)
+	apiCallDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "stilas_az_azure_api_call_duration_seconds",
+			Help:    "Latency of calls to the Azure API Management API per operation",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"operation"},
+	)
+	lroDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "stilas_az_azure_lro_duration_seconds",
+			Help:    "Time spent starting or resuming long-running operations per resulting operation status",
+			Buckets: []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+		[]string{"status"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(apiCallsTotal, apiCallDuration, lroDuration)
+}
+
+// call waits for the request limiter of the client with the given priority before observing f
+func call[T any](ctx context.Context, c *APIMClient, priority Priority, operation string, f func(context.Context) (T, error)) (T, error) {
+	if err := c.ApimClientConfig.Limiter.Wait(ctx, priority); err != nil {
+		var zero T
+		return zero, err
+	}
+	return observe(context.WithValue(ctx, limitedKey{}, true), operation, f)
+}
+
+// observe calls f in a span named after the operation and records the call count, latency and error code for the operation
+func observe[T any](ctx context.Context, operation string, f func(context.Context) (T, error)) (T, error) {
+	ctx, span := tracer.Start(ctx, "APIMClient."+operation)
+	defer span.End()
+	start := time.Now()
+	res, err := f(ctx)
+	apiCallDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	result := "success"
+	if err != nil {
+		result = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, ErrorCode(err))
+	}
+	apiCallsTotal.WithLabelValues(operation, result, ErrorCode(err)).Inc()
+	return res, err
+}
+
+// ErrorCode returns the Azure error code of err, falling back to the HTTP status code
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var responseError *azcore.ResponseError
+	if !errors.As(err, &responseError) {
+		return "Unknown"
+	}
+	if responseError.ErrorCode != "" {
+		return responseError.ErrorCode
+	}
+	return strconv.Itoa(responseError.StatusCode)
+}
@@ -0,0 +1,123 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// sampleCount returns the number of observations of a histogram
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := observer.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestObserveRecordsCalls(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		result string
+		code   string
+	}{
+		{"success", nil, "success", ""},
+		{"azure error code", &azcore.ResponseError{StatusCode: http.StatusConflict, ErrorCode: "Conflict"}, "error", "Conflict"},
+		{"http status code", &azcore.ResponseError{StatusCode: http.StatusNotFound}, "error", "404"},
+		{"other error", errors.New("connection reset"), "error", "Unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := "TestObserve " + tt.name
+			calls := apiCallsTotal.WithLabelValues(operation, tt.result, tt.code)
+			duration := apiCallDuration.WithLabelValues(operation)
+			callsBefore, samplesBefore := testutil.ToFloat64(calls), sampleCount(t, duration)
+
+			_, err := observe(context.Background(), operation, func(context.Context) (string, error) {
+				return "done", tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("observe() error = %v, want %v", err, tt.err)
+			}
+			if got := testutil.ToFloat64(calls); got != callsBefore+1 {
+				t.Errorf("calls = %v, want %v", got, callsBefore+1)
+			}
+			if got := sampleCount(t, duration); got != samplesBefore+1 {
+				t.Errorf("duration samples = %d, want %d", got, samplesBefore+1)
+			}
+		})
+	}
+}
+
+func TestMetricsRegistered(t *testing.T) {
+	_, _ = observe(context.Background(), "TestMetricsRegistered", func(context.Context) (string, error) {
+		return "", nil
+	})
+	for _, name := range []string{"stilas_az_azure_api_calls_total", "stilas_az_azure_api_call_duration_seconds"} {
+		count, err := testutil.GatherAndCount(metrics.Registry, name)
+		if err != nil {
+			t.Fatalf("GatherAndCount(%s) error = %v", name, err)
+		}
+		if count == 0 {
+			t.Errorf("%s is not registered on the controller-runtime registry", name)
+		}
+	}
+}
+
+func TestStartResumeOperationRecordsDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport *fakeTransport
+		initial   *http.Response
+		want      OperationStatus
+	}{
+		{
+			name:      "succeeded",
+			transport: &fakeTransport{responses: map[string][]*http.Response{}},
+			initial:   fakeResponse(http.StatusOK, `{"name":"api"}`, nil),
+			want:      OperationStatusSucceeded,
+		},
+		{
+			name: "in progress",
+			transport: &fakeTransport{responses: map[string][]*http.Response{
+				"GET " + operationURL: {fakeResponse(http.StatusOK, `{"status":"InProgress"}`, nil)},
+			}},
+			initial: fakeResponse(http.StatusAccepted, "", http.Header{"Azure-AsyncOperation": []string{operationURL}}),
+			want:    OperationStatusInProgress,
+		},
+		{
+			name: "failed",
+			transport: &fakeTransport{responses: map[string][]*http.Response{
+				"GET " + operationURL: {fakeResponse(http.StatusOK, `{"status":"Failed","error":{"code":"ValidationError","message":"invalid"}}`, nil)},
+			}},
+			initial: fakeResponse(http.StatusAccepted, "", http.Header{"Azure-AsyncOperation": []string{operationURL}}),
+			want:    OperationStatusFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := sampleCount(t, lroDuration.WithLabelValues(string(tt.want)))
+			poller := startPoller(t, testPipeline(tt.transport), tt.initial)
+
+			op, err := StartResumeOperation(context.Background(), poller)
+			if err != nil {
+				t.Fatalf("StartResumeOperation() error = %v", err)
+			}
+			if op.Status != tt.want {
+				t.Fatalf("Status = %s, want %s", op.Status, tt.want)
+			}
+			if got := sampleCount(t, lroDuration.WithLabelValues(string(tt.want))); got != before+1 {
+				t.Errorf("duration samples = %d, want %d", got, before+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/tjololo/stilas-az/internal/azure (interfaces: Client)
+//
+// Generated by this command:
+//
+//	mockgen -destination=internal/azure/mock/client.go -package=mock github.com/tjololo/stilas-az/internal/azure Client
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	runtime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	armapimanagement "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+	isgomock struct{}
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// CreateUpdateApi mocks base method.
+func (m *MockClient) CreateUpdateApi(ctx context.Context, apiId string, parameters armapimanagement.APICreateOrUpdateParameter, options *armapimanagement.APIClientBeginCreateOrUpdateOptions) (*runtime.Poller[armapimanagement.APIClientCreateOrUpdateResponse], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateApi", ctx, apiId, parameters, options)
+	ret0, _ := ret[0].(*runtime.Poller[armapimanagement.APIClientCreateOrUpdateResponse])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateApi indicates an expected call of CreateUpdateApi.
+func (mr *MockClientMockRecorder) CreateUpdateApi(ctx, apiId, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateApi", reflect.TypeOf((*MockClient)(nil).CreateUpdateApi), ctx, apiId, parameters, options)
+}
+
+// CreateUpdateApiOperation mocks base method.
+func (m *MockClient) CreateUpdateApiOperation(ctx context.Context, apiId, operationId string, parameters armapimanagement.OperationContract, options *armapimanagement.APIOperationClientCreateOrUpdateOptions) (armapimanagement.APIOperationClientCreateOrUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateApiOperation", ctx, apiId, operationId, parameters, options)
+	ret0, _ := ret[0].(armapimanagement.APIOperationClientCreateOrUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateApiOperation indicates an expected call of CreateUpdateApiOperation.
+func (mr *MockClientMockRecorder) CreateUpdateApiOperation(ctx, apiId, operationId, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateApiOperation", reflect.TypeOf((*MockClient)(nil).CreateUpdateApiOperation), ctx, apiId, operationId, parameters, options)
+}
+
+// CreateUpdateApiOperationPolicy mocks base method.
+func (m *MockClient) CreateUpdateApiOperationPolicy(ctx context.Context, apiId, operationId string, parameters armapimanagement.PolicyContract, options *armapimanagement.APIOperationPolicyClientCreateOrUpdateOptions) (armapimanagement.APIOperationPolicyClientCreateOrUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateApiOperationPolicy", ctx, apiId, operationId, parameters, options)
+	ret0, _ := ret[0].(armapimanagement.APIOperationPolicyClientCreateOrUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateApiOperationPolicy indicates an expected call of CreateUpdateApiOperationPolicy.
+func (mr *MockClientMockRecorder) CreateUpdateApiOperationPolicy(ctx, apiId, operationId, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateApiOperationPolicy", reflect.TypeOf((*MockClient)(nil).CreateUpdateApiOperationPolicy), ctx, apiId, operationId, parameters, options)
+}
+
+// CreateUpdateApiPolicy mocks base method.
+func (m *MockClient) CreateUpdateApiPolicy(ctx context.Context, apiId string, parameters armapimanagement.PolicyContract, options *armapimanagement.APIPolicyClientCreateOrUpdateOptions) (armapimanagement.APIPolicyClientCreateOrUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateApiPolicy", ctx, apiId, parameters, options)
+	ret0, _ := ret[0].(armapimanagement.APIPolicyClientCreateOrUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateApiPolicy indicates an expected call of CreateUpdateApiPolicy.
+func (mr *MockClientMockRecorder) CreateUpdateApiPolicy(ctx, apiId, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateApiPolicy", reflect.TypeOf((*MockClient)(nil).CreateUpdateApiPolicy), ctx, apiId, parameters, options)
+}
+
+// CreateUpdateApiRelease mocks base method.
+func (m *MockClient) CreateUpdateApiRelease(ctx context.Context, apiId, releaseId string, parameters armapimanagement.APIReleaseContract, options *armapimanagement.APIReleaseClientCreateOrUpdateOptions) (armapimanagement.APIReleaseClientCreateOrUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateApiRelease", ctx, apiId, releaseId, parameters, options)
+	ret0, _ := ret[0].(armapimanagement.APIReleaseClientCreateOrUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateApiRelease indicates an expected call of CreateUpdateApiRelease.
+func (mr *MockClientMockRecorder) CreateUpdateApiRelease(ctx, apiId, releaseId, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateApiRelease", reflect.TypeOf((*MockClient)(nil).CreateUpdateApiRelease), ctx, apiId, releaseId, parameters, options)
+}
+
+// CreateUpdateApiSchema mocks base method.
+func (m *MockClient) CreateUpdateApiSchema(ctx context.Context, apiId, schemaId string, parameters armapimanagement.SchemaContract, options *armapimanagement.APISchemaClientBeginCreateOrUpdateOptions) (*runtime.Poller[armapimanagement.APISchemaClientCreateOrUpdateResponse], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateApiSchema", ctx, apiId, schemaId, parameters, options)
+	ret0, _ := ret[0].(*runtime.Poller[armapimanagement.APISchemaClientCreateOrUpdateResponse])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateApiSchema indicates an expected call of CreateUpdateApiSchema.
+func (mr *MockClientMockRecorder) CreateUpdateApiSchema(ctx, apiId, schemaId, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateApiSchema", reflect.TypeOf((*MockClient)(nil).CreateUpdateApiSchema), ctx, apiId, schemaId, parameters, options)
+}
+
+// CreateUpdateApiVersionSet mocks base method.
+func (m *MockClient) CreateUpdateApiVersionSet(ctx context.Context, apiVersionSetName string, parameters armapimanagement.APIVersionSetContract, options *armapimanagement.APIVersionSetClientCreateOrUpdateOptions) (armapimanagement.APIVersionSetClientCreateOrUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateApiVersionSet", ctx, apiVersionSetName, parameters, options)
+	ret0, _ := ret[0].(armapimanagement.APIVersionSetClientCreateOrUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateApiVersionSet indicates an expected call of CreateUpdateApiVersionSet.
+func (mr *MockClientMockRecorder) CreateUpdateApiVersionSet(ctx, apiVersionSetName, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateApiVersionSet", reflect.TypeOf((*MockClient)(nil).CreateUpdateApiVersionSet), ctx, apiVersionSetName, parameters, options)
+}
+
+// CreateUpdateBackend mocks base method.
+func (m *MockClient) CreateUpdateBackend(ctx context.Context, backendId string, parameters armapimanagement.BackendContract, options *armapimanagement.BackendClientCreateOrUpdateOptions) (armapimanagement.BackendClientCreateOrUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateBackend", ctx, backendId, parameters, options)
+	ret0, _ := ret[0].(armapimanagement.BackendClientCreateOrUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateBackend indicates an expected call of CreateUpdateBackend.
+func (mr *MockClientMockRecorder) CreateUpdateBackend(ctx, backendId, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateBackend", reflect.TypeOf((*MockClient)(nil).CreateUpdateBackend), ctx, backendId, parameters, options)
+}
+
+// CreateUpdateGraphQLResolver mocks base method.
+func (m *MockClient) CreateUpdateGraphQLResolver(ctx context.Context, apiId, resolverId string, parameters armapimanagement.ResolverContract, options *armapimanagement.GraphQLAPIResolverClientCreateOrUpdateOptions) (armapimanagement.GraphQLAPIResolverClientCreateOrUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateGraphQLResolver", ctx, apiId, resolverId, parameters, options)
+	ret0, _ := ret[0].(armapimanagement.GraphQLAPIResolverClientCreateOrUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateGraphQLResolver indicates an expected call of CreateUpdateGraphQLResolver.
+func (mr *MockClientMockRecorder) CreateUpdateGraphQLResolver(ctx, apiId, resolverId, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateGraphQLResolver", reflect.TypeOf((*MockClient)(nil).CreateUpdateGraphQLResolver), ctx, apiId, resolverId, parameters, options)
+}
+
+// CreateUpdateGraphQLResolverPolicy mocks base method.
+func (m *MockClient) CreateUpdateGraphQLResolverPolicy(ctx context.Context, apiId, resolverId string, parameters armapimanagement.PolicyContract, options *armapimanagement.GraphQLAPIResolverPolicyClientCreateOrUpdateOptions) (armapimanagement.GraphQLAPIResolverPolicyClientCreateOrUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpdateGraphQLResolverPolicy", ctx, apiId, resolverId, parameters, options)
+	ret0, _ := ret[0].(armapimanagement.GraphQLAPIResolverPolicyClientCreateOrUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpdateGraphQLResolverPolicy indicates an expected call of CreateUpdateGraphQLResolverPolicy.
+func (mr *MockClientMockRecorder) CreateUpdateGraphQLResolverPolicy(ctx, apiId, resolverId, parameters, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpdateGraphQLResolverPolicy", reflect.TypeOf((*MockClient)(nil).CreateUpdateGraphQLResolverPolicy), ctx, apiId, resolverId, parameters, options)
+}
+
+// DeleteApi mocks base method.
+func (m *MockClient) DeleteApi(ctx context.Context, apiId, etag string, options *armapimanagement.APIClientDeleteOptions) (armapimanagement.APIClientDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApi", ctx, apiId, etag, options)
+	ret0, _ := ret[0].(armapimanagement.APIClientDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteApi indicates an expected call of DeleteApi.
+func (mr *MockClientMockRecorder) DeleteApi(ctx, apiId, etag, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApi", reflect.TypeOf((*MockClient)(nil).DeleteApi), ctx, apiId, etag, options)
+}
+
+// DeleteApiOperation mocks base method.
+func (m *MockClient) DeleteApiOperation(ctx context.Context, apiId, operationId, etag string, options *armapimanagement.APIOperationClientDeleteOptions) (armapimanagement.APIOperationClientDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApiOperation", ctx, apiId, operationId, etag, options)
+	ret0, _ := ret[0].(armapimanagement.APIOperationClientDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteApiOperation indicates an expected call of DeleteApiOperation.
+func (mr *MockClientMockRecorder) DeleteApiOperation(ctx, apiId, operationId, etag, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApiOperation", reflect.TypeOf((*MockClient)(nil).DeleteApiOperation), ctx, apiId, operationId, etag, options)
+}
+
+// DeleteApiOperationPolicy mocks base method.
+func (m *MockClient) DeleteApiOperationPolicy(ctx context.Context, apiId, operationId, etag string, options *armapimanagement.APIOperationPolicyClientDeleteOptions) (armapimanagement.APIOperationPolicyClientDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApiOperationPolicy", ctx, apiId, operationId, etag, options)
+	ret0, _ := ret[0].(armapimanagement.APIOperationPolicyClientDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteApiOperationPolicy indicates an expected call of DeleteApiOperationPolicy.
+func (mr *MockClientMockRecorder) DeleteApiOperationPolicy(ctx, apiId, operationId, etag, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApiOperationPolicy", reflect.TypeOf((*MockClient)(nil).DeleteApiOperationPolicy), ctx, apiId, operationId, etag, options)
+}
+
+// DeleteApiPolicy mocks base method.
+func (m *MockClient) DeleteApiPolicy(ctx context.Context, apiId, etag string, options *armapimanagement.APIPolicyClientDeleteOptions) (armapimanagement.APIPolicyClientDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApiPolicy", ctx, apiId, etag, options)
+	ret0, _ := ret[0].(armapimanagement.APIPolicyClientDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteApiPolicy indicates an expected call of DeleteApiPolicy.
+func (mr *MockClientMockRecorder) DeleteApiPolicy(ctx, apiId, etag, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApiPolicy", reflect.TypeOf((*MockClient)(nil).DeleteApiPolicy), ctx, apiId, etag, options)
+}
+
+// DeleteApiVersionSet mocks base method.
+func (m *MockClient) DeleteApiVersionSet(ctx context.Context, apiVersionSetName, etag string, options *armapimanagement.APIVersionSetClientDeleteOptions) (armapimanagement.APIVersionSetClientDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApiVersionSet", ctx, apiVersionSetName, etag, options)
+	ret0, _ := ret[0].(armapimanagement.APIVersionSetClientDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteApiVersionSet indicates an expected call of DeleteApiVersionSet.
+func (mr *MockClientMockRecorder) DeleteApiVersionSet(ctx, apiVersionSetName, etag, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApiVersionSet", reflect.TypeOf((*MockClient)(nil).DeleteApiVersionSet), ctx, apiVersionSetName, etag, options)
+}
+
+// DeleteBackend mocks base method.
+func (m *MockClient) DeleteBackend(ctx context.Context, backendId, etag string, options *armapimanagement.BackendClientDeleteOptions) (armapimanagement.BackendClientDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBackend", ctx, backendId, etag, options)
+	ret0, _ := ret[0].(armapimanagement.BackendClientDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBackend indicates an expected call of DeleteBackend.
+func (mr *MockClientMockRecorder) DeleteBackend(ctx, backendId, etag, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBackend", reflect.TypeOf((*MockClient)(nil).DeleteBackend), ctx, backendId, etag, options)
+}
+
+// DeleteGraphQLResolver mocks base method.
+func (m *MockClient) DeleteGraphQLResolver(ctx context.Context, apiId, resolverId, etag string, options *armapimanagement.GraphQLAPIResolverClientDeleteOptions) (armapimanagement.GraphQLAPIResolverClientDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGraphQLResolver", ctx, apiId, resolverId, etag, options)
+	ret0, _ := ret[0].(armapimanagement.GraphQLAPIResolverClientDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteGraphQLResolver indicates an expected call of DeleteGraphQLResolver.
+func (mr *MockClientMockRecorder) DeleteGraphQLResolver(ctx, apiId, resolverId, etag, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGraphQLResolver", reflect.TypeOf((*MockClient)(nil).DeleteGraphQLResolver), ctx, apiId, resolverId, etag, options)
+}
+
+// GetApi mocks base method.
+func (m *MockClient) GetApi(ctx context.Context, apiId string, options *armapimanagement.APIClientGetOptions) (armapimanagement.APIClientGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApi", ctx, apiId, options)
+	ret0, _ := ret[0].(armapimanagement.APIClientGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApi indicates an expected call of GetApi.
+func (mr *MockClientMockRecorder) GetApi(ctx, apiId, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApi", reflect.TypeOf((*MockClient)(nil).GetApi), ctx, apiId, options)
+}
+
+// GetApiOperation mocks base method.
+func (m *MockClient) GetApiOperation(ctx context.Context, apiId, operationId string, options *armapimanagement.APIOperationClientGetOptions) (armapimanagement.APIOperationClientGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiOperation", ctx, apiId, operationId, options)
+	ret0, _ := ret[0].(armapimanagement.APIOperationClientGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiOperation indicates an expected call of GetApiOperation.
+func (mr *MockClientMockRecorder) GetApiOperation(ctx, apiId, operationId, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiOperation", reflect.TypeOf((*MockClient)(nil).GetApiOperation), ctx, apiId, operationId, options)
+}
+
+// GetApiOperationPolicy mocks base method.
+func (m *MockClient) GetApiOperationPolicy(ctx context.Context, apiId, operationId string, options *armapimanagement.APIOperationPolicyClientGetOptions) (armapimanagement.APIOperationPolicyClientGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiOperationPolicy", ctx, apiId, operationId, options)
+	ret0, _ := ret[0].(armapimanagement.APIOperationPolicyClientGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiOperationPolicy indicates an expected call of GetApiOperationPolicy.
+func (mr *MockClientMockRecorder) GetApiOperationPolicy(ctx, apiId, operationId, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiOperationPolicy", reflect.TypeOf((*MockClient)(nil).GetApiOperationPolicy), ctx, apiId, operationId, options)
+}
+
+// GetApiPolicy mocks base method.
+func (m *MockClient) GetApiPolicy(ctx context.Context, apiId string, options *armapimanagement.APIPolicyClientGetOptions) (armapimanagement.APIPolicyClientGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiPolicy", ctx, apiId, options)
+	ret0, _ := ret[0].(armapimanagement.APIPolicyClientGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiPolicy indicates an expected call of GetApiPolicy.
+func (mr *MockClientMockRecorder) GetApiPolicy(ctx, apiId, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiPolicy", reflect.TypeOf((*MockClient)(nil).GetApiPolicy), ctx, apiId, options)
+}
+
+// GetApiVersionSet mocks base method.
+func (m *MockClient) GetApiVersionSet(ctx context.Context, apiVersionSetName string, options *armapimanagement.APIVersionSetClientGetOptions) (armapimanagement.APIVersionSetClientGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiVersionSet", ctx, apiVersionSetName, options)
+	ret0, _ := ret[0].(armapimanagement.APIVersionSetClientGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiVersionSet indicates an expected call of GetApiVersionSet.
+func (mr *MockClientMockRecorder) GetApiVersionSet(ctx, apiVersionSetName, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiVersionSet", reflect.TypeOf((*MockClient)(nil).GetApiVersionSet), ctx, apiVersionSetName, options)
+}
+
+// GetBackend mocks base method.
+func (m *MockClient) GetBackend(ctx context.Context, backendId string, options *armapimanagement.BackendClientGetOptions) (armapimanagement.BackendClientGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBackend", ctx, backendId, options)
+	ret0, _ := ret[0].(armapimanagement.BackendClientGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBackend indicates an expected call of GetBackend.
+func (mr *MockClientMockRecorder) GetBackend(ctx, backendId, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackend", reflect.TypeOf((*MockClient)(nil).GetBackend), ctx, backendId, options)
+}
+
+// GetGraphQLResolver mocks base method.
+func (m *MockClient) GetGraphQLResolver(ctx context.Context, apiId, resolverId string, options *armapimanagement.GraphQLAPIResolverClientGetOptions) (armapimanagement.GraphQLAPIResolverClientGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGraphQLResolver", ctx, apiId, resolverId, options)
+	ret0, _ := ret[0].(armapimanagement.GraphQLAPIResolverClientGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGraphQLResolver indicates an expected call of GetGraphQLResolver.
+func (mr *MockClientMockRecorder) GetGraphQLResolver(ctx, apiId, resolverId, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGraphQLResolver", reflect.TypeOf((*MockClient)(nil).GetGraphQLResolver), ctx, apiId, resolverId, options)
+}
+
+// GetGraphQLResolverPolicy mocks base method.
+func (m *MockClient) GetGraphQLResolverPolicy(ctx context.Context, apiId, resolverId string, options *armapimanagement.GraphQLAPIResolverPolicyClientGetOptions) (armapimanagement.GraphQLAPIResolverPolicyClientGetResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGraphQLResolverPolicy", ctx, apiId, resolverId, options)
+	ret0, _ := ret[0].(armapimanagement.GraphQLAPIResolverPolicyClientGetResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGraphQLResolverPolicy indicates an expected call of GetGraphQLResolverPolicy.
+func (mr *MockClientMockRecorder) GetGraphQLResolverPolicy(ctx, apiId, resolverId, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGraphQLResolverPolicy", reflect.TypeOf((*MockClient)(nil).GetGraphQLResolverPolicy), ctx, apiId, resolverId, options)
+}
@@ -0,0 +1,130 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanRecorder     = tracetest.NewSpanRecorder()
+	spanRecorderOnce sync.Once
+)
+
+// startTestSpan records the spans of the package tracer and starts a parent span for the spans of one test. The
+// global tracer provider can only be replaced once, so the spans of all tests are recorded by the same recorder.
+func startTestSpan(t *testing.T) (context.Context, trace.Span) {
+	t.Helper()
+	spanRecorderOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	return otel.Tracer("test").Start(context.Background(), t.Name())
+}
+
+// endedChildSpan returns the ended span with the given name and parent
+func endedChildSpan(t *testing.T, parent trace.Span, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range spanRecorder.Ended() {
+		if span.Name() == name && span.Parent().SpanID() == parent.SpanContext().SpanID() {
+			return span
+		}
+	}
+	t.Fatalf("no span %s below %s", name, t.Name())
+	return nil
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingPolicy(t *testing.T) {
+	ctx, parent := startTestSpan(t)
+	defer parent.End()
+	transport := &fakeTransport{responses: map[string][]*http.Response{
+		"GET " + resourceURL: {fakeResponse(http.StatusNotFound, `{"error":{"code":"ResourceNotFound"}}`, nil)},
+	}}
+	pl := runtime.NewPipeline("test", "v0.0.1", runtime.PipelineOptions{PerRetry: []policy.Policy{tracingPolicy{}}}, &policy.ClientOptions{
+		Transport: transport,
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+	req, err := runtime.NewRequest(ctx, http.MethodGet, resourceURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := pl.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	span := endedChildSpan(t, parent, "HTTP GET")
+	if span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("SpanKind = %s, want %s", span.SpanKind(), trace.SpanKindClient)
+	}
+	if got := spanAttribute(span, "http.response.status_code").AsInt64(); got != http.StatusNotFound {
+		t.Errorf("http.response.status_code = %d, want %d", got, http.StatusNotFound)
+	}
+	if got := spanAttribute(span, "url.full").AsString(); got != resourceURL {
+		t.Errorf("url.full = %s, want %s", got, resourceURL)
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("Status = %v, want %v", span.Status().Code, codes.Error)
+	}
+	traceparent := resp.Request.Header.Get("traceparent")
+	if traceparent == "" || traceparent[3:35] != span.SpanContext().TraceID().String() {
+		t.Errorf("traceparent = %q, want trace %s", traceparent, span.SpanContext().TraceID())
+	}
+}
+
+func TestObserveSpan(t *testing.T) {
+	ctx, parent := startTestSpan(t)
+	defer parent.End()
+
+	_, _ = observe(ctx, "GetBackend", func(context.Context) (string, error) {
+		return "", &azcore.ResponseError{StatusCode: http.StatusConflict, ErrorCode: "Conflict"}
+	})
+	span := endedChildSpan(t, parent, "APIMClient.GetBackend")
+	if span.Status().Code != codes.Error || span.Status().Description != "Conflict" {
+		t.Errorf("Status = %+v, want error Conflict", span.Status())
+	}
+	if len(span.Events()) != 1 || span.Events()[0].Name != "exception" {
+		t.Errorf("Events = %+v, want the recorded error", span.Events())
+	}
+}
+
+func TestStartResumeOperationSpan(t *testing.T) {
+	ctx, parent := startTestSpan(t)
+	defer parent.End()
+	transport := &fakeTransport{responses: map[string][]*http.Response{
+		"GET " + operationURL: {fakeResponse(http.StatusOK, `{"status":"InProgress"}`, nil)},
+	}}
+	poller := startPoller(t, testPipeline(transport), fakeResponse(http.StatusAccepted, "", http.Header{"Azure-AsyncOperation": []string{operationURL}}))
+
+	if _, err := StartResumeOperation(ctx, poller); err != nil {
+		t.Fatalf("StartResumeOperation() error = %v", err)
+	}
+	span := endedChildSpan(t, parent, "StartResumeOperation")
+	if got := spanAttribute(span, "operation.status").AsString(); got != string(OperationStatusInProgress) {
+		t.Errorf("operation.status = %s, want %s", got, OperationStatusInProgress)
+	}
+	if span.Status().Code == codes.Error {
+		t.Errorf("Status = %+v, want no error while the operation is in progress", span.Status())
+	}
+}
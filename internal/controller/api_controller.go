@@ -21,32 +21,40 @@ import (
 	"fmt"
 	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
 	"github.com/tjololo/stilas-az/internal/azure"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"os"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
 )
 
-type newApimCLient func(config azure.ApimClientConfig) (*azure.APIMClient, error)
+type newApimCLient func(config azure.ApimClientConfig) (azure.Client, error)
 
 // ApiReconciler reconciles a Api object
 type ApiReconciler struct {
 	client.Client
-	NewClient  newApimCLient
-	Scheme     *runtime.Scheme
-	apimClient *azure.APIMClient
+	NewClient    newApimCLient
+	Scheme       *runtime.Scheme
+	Recorder     record.EventRecorder
+	ResyncPeriod time.Duration
+	apimClient   azure.Client
 }
 
 // +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apis,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apis/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apis/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -58,6 +66,8 @@ type ApiReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.19.0/pkg/reconcile
 func (r *ApiReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "Api", req)
+	defer span.End()
 	logger := log.FromContext(ctx)
 	var api apimv1alpha1.Api
 	if err := r.Get(ctx, req.NamespacedName, &api); err != nil {
@@ -74,6 +84,10 @@ func (r *ApiReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			return ctrl.Result{}, err
 		}
 	}
+	if api.DeletionTimestamp == nil && api.Status.PermanentlyFailed(api.Generation) {
+		logger.Info("Generation failed permanently, waiting for spec change", "generation", api.Generation)
+		return ctrl.Result{}, nil
+	}
 	logger.Info("Reconciling Api")
 	subscriptionID, resourcesGroup, apimName, err := getConfigFromEnv()
 	if err != nil {
@@ -105,8 +119,10 @@ func (r *ApiReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		err = r.deleteAzureResources(ctx, apiName)
 		if err != nil {
 			logger.Error(err, "Failed to delete Azure resources")
-			return ctrl.Result{}, err
+			r.Recorder.Event(&api, corev1.EventTypeWarning, ReasonDeleteFailed, eventMessage("Failed to delete API version set", err))
+			return azureErrorResult(ctx, r, &api, &api.Status.AzureResourceStatus, err)
 		}
+		r.Recorder.Event(&api, corev1.EventTypeNormal, ReasonDeleted, "Deleted API version set "+apiName)
 		controllerutil.RemoveFinalizer(&api, "api.finalizers.stilas.418.cloud")
 		err = r.Update(ctx, &api)
 		if err != nil {
@@ -119,37 +135,63 @@ func (r *ApiReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 
 	getRes, err := r.apimClient.GetApiVersionSet(ctx, apiName, nil)
 	if azure.IsNotFoundError(err) {
-		result, err := r.apimClient.CreateUpdateApiVersionSet(
-			ctx,
-			apiName,
-			apim.APIVersionSetContract{
-				Properties: &apim.APIVersionSetContractProperties{
-					DisplayName:      &api.Spec.DisplayName,
-					VersioningScheme: api.Spec.VersioningScheme.AzureAPIVersionScheme(),
-					Description:      api.Spec.Description,
-				},
-				Name: &apiName,
-			},
-			nil)
-
+		result, err := r.apimClient.CreateUpdateApiVersionSet(ctx, apiName, *api.ToAzureApiVersionSetContract(), nil)
 		if err != nil {
 			logger.Error(err, "Failed to create or update API version")
-			return ctrl.Result{}, err
+			r.Recorder.Event(&api, corev1.EventTypeWarning, ReasonVersionSetFailed, eventMessage("Failed to create API version set", err))
+			return azureErrorResult(ctx, r, &api, &api.Status.AzureResourceStatus, err)
 		}
+		r.Recorder.Event(&api, corev1.EventTypeNormal, ReasonVersionSetCreated, "Created API version set "+apiName)
 		resId = result.ID
+		api.Status.ETag = stringValue(result.ETag)
 	} else if err != nil {
 		logger.Error(err, "Failed to get API version")
-		return ctrl.Result{}, err
+		return azureErrorResult(ctx, r, &api, &api.Status.AzureResourceStatus, err)
+	} else if api.RequireVersionSetUpdate(getRes.Properties) {
+		logger.Info("Updating API version set")
+		desired := api.ToAzureApiVersionSetContract()
+		api.Status.ETag = stringValue(getRes.ETag)
+		if getRes.Properties == nil || !reflect.DeepEqual(getRes.Properties.VersioningScheme, desired.Properties.VersioningScheme) {
+			// the change is recorded before the version set is written so it reaches the ApiVersions even if this
+			// reconcile does not complete
+			api.Status.PendingVersioningScheme = api.Spec.VersioningScheme
+			if err := r.Status().Update(ctx, &api); err != nil {
+				logger.Error(err, "Failed to record pending versioning scheme change")
+				return ctrl.Result{}, err
+			}
+		}
+		result, err := r.apimClient.CreateUpdateApiVersionSet(ctx, apiName, *desired, &apim.APIVersionSetClientCreateOrUpdateOptions{IfMatch: getRes.ETag})
+		if azure.IsPreconditionFailedError(err) {
+			logger.Info("API version set was modified since it was read, retrying")
+			return azureErrorResult(ctx, r, &api, &api.Status.AzureResourceStatus, err)
+		}
+		if err != nil {
+			logger.Error(err, "Failed to update API version set")
+			r.Recorder.Event(&api, corev1.EventTypeWarning, ReasonVersionSetFailed, eventMessage("Failed to update API version set", err))
+			return azureErrorResult(ctx, r, &api, &api.Status.AzureResourceStatus, err)
+		}
+		r.Recorder.Event(&api, corev1.EventTypeNormal, ReasonVersionSetUpdated, "Updated API version set "+apiName)
+		resId = result.ID
+		api.Status.ETag = stringValue(result.ETag)
 	} else {
 		resId = getRes.ID
+		api.Status.ETag = stringValue(getRes.ETag)
 	}
 	if resId == nil {
 		logger.Info("No result returned")
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 	}
+	if api.Status.PendingVersioningScheme != "" {
+		if err := r.propagateVersionSchemeChange(ctx, &api); err != nil {
+			logger.Error(err, "Failed to propagate versioning scheme change")
+			return ctrl.Result{}, err
+		}
+		api.Status.PendingVersioningScheme = ""
+	}
 
 	api.Status.ProvisioningState = "Succeeded"
 	api.Status.ApiVersionSetID = *resId
+	clearAzureError(&api.Status.AzureResourceStatus, api.Generation)
 	err = r.reconcileVersions(ctx, &api)
 	if err != nil {
 		logger.Error(err, "Failed to reconcile versions")
@@ -160,29 +202,32 @@ func (r *ApiReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		logger.Error(err, "Failed to update status of product api version")
 		return ctrl.Result{}, err
 	}
-	return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+	return resyncAfter(r.ResyncPeriod), nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ApiReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Create an index for the ownerReferences.uid field
-	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &apimv1alpha1.ApiVersion{}, "metadata.ownerReferences.uid", func(rawObj client.Object) []string {
-		// Extract the owner UID from the ownerReferences
-		apiVersion := rawObj.(*apimv1alpha1.ApiVersion)
-		ownerRefs := apiVersion.GetOwnerReferences()
-		if len(ownerRefs) == 0 {
-			return nil
-		}
-		return []string{string(ownerRefs[0].UID)}
-	}); err != nil {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &apimv1alpha1.ApiVersion{}, "metadata.ownerReferences.uid", ownerUIDIndex); err != nil {
 		return err
 	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&apimv1alpha1.Api{}).
+		For(&apimv1alpha1.Api{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Owns(&apimv1alpha1.ApiVersion{}).
 		Complete(r)
 }
 
+// ownerUIDIndex indexes ApiVersions by the UID of the Api that owns them
+func ownerUIDIndex(rawObj client.Object) []string {
+	// Extract the owner UID from the ownerReferences
+	apiVersion := rawObj.(*apimv1alpha1.ApiVersion)
+	ownerRefs := apiVersion.GetOwnerReferences()
+	if len(ownerRefs) == 0 {
+		return nil
+	}
+	return []string{string(ownerRefs[0].UID)}
+}
+
 func (r *ApiReconciler) reconcileVersions(ctx context.Context, api *apimv1alpha1.Api) error {
 	logger := log.FromContext(ctx)
 	for _, version := range api.Spec.Versions {
@@ -226,6 +271,29 @@ func (r *ApiReconciler) reconcileVersions(ctx context.Context, api *apimv1alpha1
 	return nil
 }
 
+// propagateVersionSchemeChange resets the applied state of every ApiVersion owned by the Api so each version is
+// applied to Azure again with the new versioning scheme. Status changes do not trigger a reconcile of the ApiVersion, so
+// the scheme is also written to an annotation. The change stays pending in the Api status until every ApiVersion is reset.
+func (r *ApiReconciler) propagateVersionSchemeChange(ctx context.Context, api *apimv1alpha1.Api) error {
+	var versions apimv1alpha1.ApiVersionList
+	if err := r.List(ctx, &versions, client.InNamespace(api.Namespace), client.MatchingFields{"metadata.ownerReferences.uid": string(api.GetUID())}); err != nil {
+		return err
+	}
+	for _, version := range versions.Items {
+		patch := client.MergeFrom(version.DeepCopy())
+		version.Status.LastAppliedSpecSha = ""
+		if err := r.Status().Patch(ctx, &version, patch); err != nil {
+			return fmt.Errorf("failed to reset applied state of %s: %w", version.Name, err)
+		}
+		patch = client.MergeFrom(version.DeepCopy())
+		metav1.SetMetaDataAnnotation(&version.ObjectMeta, apimv1alpha1.VersioningSchemeAnnotation, string(api.Spec.VersioningScheme))
+		if err := r.Patch(ctx, &version, patch); err != nil {
+			return fmt.Errorf("failed to enqueue %s: %w", version.Name, err)
+		}
+	}
+	return nil
+}
+
 func (r *ApiReconciler) deleteOwnedResources(ctx context.Context, api *apimv1alpha1.Api) (done bool, err error) {
 	var versions apimv1alpha1.ApiVersionList
 	apiVersionErr := r.List(ctx, &versions, client.InNamespace(api.Namespace), client.MatchingFields{"metadata.ownerReferences.uid": string(api.GetUID())})
@@ -233,7 +301,7 @@ func (r *ApiReconciler) deleteOwnedResources(ctx context.Context, api *apimv1alp
 		return false, apiVersionErr
 	}
 	for _, version := range versions.Items {
-		if version.DeletionTimestamp != nil {
+		if version.DeletionTimestamp == nil {
 			deleteErr := r.Delete(ctx, &version)
 			if deleteErr != nil {
 				return false, deleteErr
@@ -244,12 +312,12 @@ func (r *ApiReconciler) deleteOwnedResources(ctx context.Context, api *apimv1alp
 }
 
 func (r *ApiReconciler) deleteAzureResources(ctx context.Context, apiName string) error {
-	_, err := r.apimClient.GetApiVersionSet(ctx, apiName, nil)
+	res, err := r.apimClient.GetApiVersionSet(ctx, apiName, nil)
 	if azure.IgnoreNotFound(err) != nil {
 		return fmt.Errorf("failed to get API version set: %w", err)
 	}
 	if err == nil {
-		_, err = r.apimClient.DeleteApiVersionSet(ctx, apiName, "*", nil)
+		_, err = r.apimClient.DeleteApiVersionSet(ctx, apiName, stringValue(res.ETag), nil)
 		if err != nil {
 			return fmt.Errorf("failed to delete API version set: %w", err)
 		}
@@ -282,6 +350,13 @@ func toPointer[T any](t T) *T {
 	return &t
 }
 
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func getConfigFromEnv() (subscriptionID string, resourcesGroup string, apimName string, err error) {
 	subscriptionID = os.Getenv("STILAS_AZ_SUBSCRIPTION_ID")
 	if subscriptionID == "" {
@@ -18,67 +18,324 @@ package controller
 
 import (
 	"context"
+	"net/http"
+	"time"
 
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+	"github.com/tjololo/stilas-az/internal/azure/apimfake"
+	"github.com/tjololo/stilas-az/internal/azure/mock"
 )
 
 var _ = Describe("Api Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
+		const azureName = "default-" + resourceName
+		const versionSetId = "/apiVersionSets/" + azureName
 
 		ctx := context.Background()
 
 		typeNamespacedName := types.NamespacedName{
 			Name:      resourceName,
-			Namespace: "default", // TODO(user):Modify as needed
+			Namespace: "default",
 		}
-		api := &apimv1alpha1.Api{}
+		var (
+			k8s        client.Client
+			apimClient *mock.MockClient
+			reconciler *ApiReconciler
+		)
 
 		BeforeEach(func() {
+			setAzureEnv()
 			By("creating the custom resource for the Kind Api")
-			err := k8sClient.Get(ctx, typeNamespacedName, api)
-			if err != nil && errors.IsNotFound(err) {
-				resource := &apimv1alpha1.Api{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      resourceName,
-						Namespace: "default",
+			k8s = newFakeClient(&apimv1alpha1.Api{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       resourceName,
+					Namespace:  "default",
+					Generation: 1,
+					UID:        "api-uid",
+					Finalizers: []string{"api.finalizers.stilas.418.cloud"},
+				},
+				Spec: apimv1alpha1.ApiSpec{
+					DisplayName:      "Test API",
+					VersioningScheme: apimv1alpha1.APIVersionSetContractDetailsVersioningSchemeSegment,
+					Path:             "test",
+					ApiType:          toPointer(apimv1alpha1.APITypeHTTP),
+					Versions: []apimv1alpha1.ApiVersionSubSpec{
+						{Name: toPointer("v1"), DisplayName: "Test API v1", ContentFormat: toPointer(apimv1alpha1.ContentFormatOpenapiJSON), Content: toPointer(`{"openapi":"3.0.1"}`)},
 					},
-					// TODO(user): Specify other spec details if needed.
-				}
-				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+				},
+			})
+			apimClient = mock.NewMockClient(gomock.NewController(GinkgoT()))
+			reconciler = &ApiReconciler{
+				Client:    k8s,
+				Scheme:    k8s.Scheme(),
+				NewClient: newClientFor(apimClient),
+				Recorder:  record.NewFakeRecorder(10),
 			}
 		})
 
-		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
-			resource := &apimv1alpha1.Api{}
-			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+		reconcileApi := func() (reconcile.Result, error) {
+			return reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		}
+		getApi := func() *apimv1alpha1.Api {
+			api := &apimv1alpha1.Api{}
+			Expect(k8s.Get(ctx, typeNamespacedName, api)).To(Succeed())
+			return api
+		}
+		existingVersionSet := func(displayName string) apim.APIVersionSetClientGetResponse {
+			return apim.APIVersionSetClientGetResponse{
+				APIVersionSetContract: apim.APIVersionSetContract{
+					ID: toPointer(versionSetId),
+					Properties: &apim.APIVersionSetContractProperties{
+						DisplayName:      toPointer(displayName),
+						VersioningScheme: toPointer(apim.VersioningSchemeSegment),
+					},
+				},
+				ETag: toPointer(`"1"`),
+			}
+		}
+
+		It("should create the version set and the ApiVersions", func() {
+			apimClient.EXPECT().GetApiVersionSet(gomock.Any(), azureName, nil).
+				Return(apim.APIVersionSetClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+			apimClient.EXPECT().CreateUpdateApiVersionSet(gomock.Any(), azureName, gomock.Any(), nil).
+				Return(apim.APIVersionSetClientCreateOrUpdateResponse{
+					APIVersionSetContract: apim.APIVersionSetContract{ID: toPointer(versionSetId)},
+					ETag:                  toPointer(`"1"`),
+				}, nil)
+
+			_, err := reconcileApi()
 			Expect(err).NotTo(HaveOccurred())
 
-			By("Cleanup the specific resource instance Api")
-			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			api := getApi()
+			Expect(api.Status.ProvisioningState).To(Equal("Succeeded"))
+			Expect(api.Status.ApiVersionSetID).To(Equal(versionSetId))
+			Expect(api.Status.ETag).To(Equal(`"1"`))
+
+			By("Creating an ApiVersion for each version owned by the Api")
+			apiVersion := &apimv1alpha1.ApiVersion{}
+			Expect(k8s.Get(ctx, types.NamespacedName{Name: azureName + "-v1", Namespace: "default"}, apiVersion)).To(Succeed())
+			Expect(apiVersion.Spec.ApiVersionSetId).To(Equal(versionSetId))
+			Expect(apiVersion.Spec.Path).To(Equal("test"))
+			Expect(metav1.IsControlledBy(apiVersion, api)).To(BeTrue())
+		})
+
+		It("should update the version set with If-Match when the spec changed", func() {
+			apimClient.EXPECT().GetApiVersionSet(gomock.Any(), azureName, nil).Return(existingVersionSet("Old name"), nil)
+			apimClient.EXPECT().CreateUpdateApiVersionSet(gomock.Any(), azureName, gomock.Any(), &apim.APIVersionSetClientCreateOrUpdateOptions{IfMatch: toPointer(`"1"`)}).
+				DoAndReturn(func(_ context.Context, _ string, versionSet apim.APIVersionSetContract, _ *apim.APIVersionSetClientCreateOrUpdateOptions) (apim.APIVersionSetClientCreateOrUpdateResponse, error) {
+					Expect(*versionSet.Properties.DisplayName).To(Equal("Test API"))
+					return apim.APIVersionSetClientCreateOrUpdateResponse{
+						APIVersionSetContract: apim.APIVersionSetContract{ID: toPointer(versionSetId)},
+						ETag:                  toPointer(`"2"`),
+					}, nil
+				})
+
+			_, err := reconcileApi()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getApi().Status.ETag).To(Equal(`"2"`))
 		})
-		It("should successfully reconcile the resource", func() {
-			By("Reconciling the created resource")
-			controllerReconciler := &ApiReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+
+		It("should not update the version set when it is up to date", func() {
+			apimClient.EXPECT().GetApiVersionSet(gomock.Any(), azureName, nil).Return(existingVersionSet("Test API"), nil)
+
+			_, err := reconcileApi()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getApi().Status.ApiVersionSetID).To(Equal(versionSetId))
+		})
+
+		It("should requeue after the retry delay when Azure throttles", func() {
+			apimClient.EXPECT().GetApiVersionSet(gomock.Any(), azureName, nil).
+				Return(apim.APIVersionSetClientGetResponse{}, responseError(http.StatusTooManyRequests, "TooManyRequests"))
+
+			result, err := reconcileApi()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(azure.DefaultRetryAfter))
+			Expect(getApi().Status.ErrorClass).To(Equal(string(azure.ErrorClassThrottled)))
+		})
+
+		It("should count conflicts when the version set was modified concurrently", func() {
+			apimClient.EXPECT().GetApiVersionSet(gomock.Any(), azureName, nil).Return(existingVersionSet("Old name"), nil)
+			apimClient.EXPECT().CreateUpdateApiVersionSet(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+				Return(apim.APIVersionSetClientCreateOrUpdateResponse{}, responseError(http.StatusPreconditionFailed, "PreconditionFailed"))
+
+			result, err := reconcileApi()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(getApi().Status.ConflictCount).To(Equal(int32(1)))
+		})
+
+		Context("when the versioning scheme changes", func() {
+			var apiVersion *apimv1alpha1.ApiVersion
+
+			BeforeEach(func() {
+				api := getApi()
+				api.Spec.VersioningScheme = apimv1alpha1.APIVersionSetContractDetailsVersioningSchemeQuery
+				api.Spec.VersionQueryName = toPointer("api-version")
+				Expect(k8s.Update(ctx, api)).To(Succeed())
+				apiVersion = &apimv1alpha1.ApiVersion{
+					ObjectMeta: metav1.ObjectMeta{Name: azureName + "-v1", Namespace: "default"},
+				}
+				Expect(controllerutil.SetControllerReference(api, apiVersion, k8s.Scheme())).To(Succeed())
+				Expect(k8s.Create(ctx, apiVersion)).To(Succeed())
+				apiVersion.Status.LastAppliedSpecSha = "applied"
+				Expect(k8s.Status().Update(ctx, apiVersion)).To(Succeed())
+			})
+
+			getAppliedSpecSha := func() string {
+				Expect(k8s.Get(ctx, client.ObjectKeyFromObject(apiVersion), apiVersion)).To(Succeed())
+				return apiVersion.Status.LastAppliedSpecSha
 			}
 
-			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
-				NamespacedName: typeNamespacedName,
+			It("should reset and enqueue the ApiVersions", func() {
+				apimClient.EXPECT().GetApiVersionSet(gomock.Any(), azureName, nil).Return(existingVersionSet("Test API"), nil)
+				apimClient.EXPECT().CreateUpdateApiVersionSet(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					Return(apim.APIVersionSetClientCreateOrUpdateResponse{
+						APIVersionSetContract: apim.APIVersionSetContract{ID: toPointer(versionSetId)},
+						ETag:                  toPointer(`"2"`),
+					}, nil)
+
+				_, err := reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getAppliedSpecSha()).To(BeEmpty())
+				Expect(apiVersion.Annotations).To(HaveKeyWithValue(apimv1alpha1.VersioningSchemeAnnotation, "Query"))
+				Expect(getApi().Status.PendingVersioningScheme).To(BeEmpty())
+			})
+
+			It("should keep the change pending until the ApiVersions are reset", func() {
+				apimClient.EXPECT().GetApiVersionSet(gomock.Any(), azureName, nil).Return(existingVersionSet("Test API"), nil)
+				apimClient.EXPECT().CreateUpdateApiVersionSet(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					Return(apim.APIVersionSetClientCreateOrUpdateResponse{}, responseError(http.StatusTooManyRequests, "TooManyRequests"))
+
+				_, err := reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApi().Status.PendingVersioningScheme).To(Equal(apimv1alpha1.APIVersionSetContractDetailsVersioningSchemeQuery))
+				Expect(getAppliedSpecSha()).To(Equal("applied"))
+
+				By("Resetting the ApiVersions once the version set has the new scheme")
+				updated := existingVersionSet("Test API")
+				updated.Properties.VersioningScheme = toPointer(apim.VersioningSchemeQuery)
+				updated.Properties.VersionQueryName = toPointer("api-version")
+				apimClient.EXPECT().GetApiVersionSet(gomock.Any(), azureName, nil).Return(updated, nil)
+
+				_, err = reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getAppliedSpecSha()).To(BeEmpty())
+				Expect(getApi().Status.PendingVersioningScheme).To(BeEmpty())
 			})
+		})
+
+		It("should delete owned ApiVersions before the version set", func() {
+			api := getApi()
+			apiVersion := &apimv1alpha1.ApiVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: azureName + "-v1", Namespace: "default"},
+			}
+			Expect(controllerutil.SetControllerReference(api, apiVersion, k8s.Scheme())).To(Succeed())
+			Expect(k8s.Create(ctx, apiVersion)).To(Succeed())
+			Expect(k8s.Delete(ctx, api)).To(Succeed())
+
+			result, err := reconcileApi()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			err = k8s.Get(ctx, client.ObjectKeyFromObject(apiVersion), &apimv1alpha1.ApiVersion{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("Deleting the version set once the ApiVersions are gone")
+			apimClient.EXPECT().GetApiVersionSet(gomock.Any(), azureName, nil).Return(existingVersionSet("Test API"), nil)
+			apimClient.EXPECT().DeleteApiVersionSet(gomock.Any(), azureName, `"1"`, nil).Return(apim.APIVersionSetClientDeleteResponse{}, nil)
+			_, err = reconcileApi()
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+			err = k8s.Get(ctx, typeNamespacedName, &apimv1alpha1.Api{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		Context("with the fake APIM server", func() {
+			var server *apimfake.Server
+
+			BeforeEach(func() {
+				server = apimfake.NewServer()
+				DeferCleanup(server.Close)
+				reconciler.NewClient = server.NewClient
+			})
+
+			It("should create the version set and import the ApiVersions", func() {
+				_, err := reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				versionSet, found := server.Resource("apiVersionSets/" + azureName)
+				Expect(found).To(BeTrue())
+				Expect(versionSet["properties"]).To(HaveKeyWithValue("displayName", "Test API"))
+				Expect(versionSet["properties"]).To(HaveKeyWithValue("versioningScheme", "Segment"))
+				Expect(getApi().Status.ApiVersionSetID).To(Equal(versionSetId))
+
+				By("Importing the ApiVersion into the version set")
+				versionReconciler := &ApiVersionReconciler{Client: k8s, Scheme: k8s.Scheme(), NewClient: server.NewClient, Recorder: record.NewFakeRecorder(10)}
+				versionName := types.NamespacedName{Name: azureName + "-v1", Namespace: "default"}
+				_, err = versionReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: versionName})
+				Expect(err).NotTo(HaveOccurred())
+				api, found := server.Resource("apis/default-" + versionName.Name)
+				Expect(found).To(BeTrue())
+				Expect(api["properties"]).To(HaveKeyWithValue("apiVersionSetId", versionSetId))
+				Expect(api["properties"]).To(HaveKeyWithValue("apiVersion", "v1"))
+
+				By("Not writing an unchanged version set again")
+				served := len(server.Requests())
+				_, err = reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.Requests()[served:]).To(Equal([]string{"GET apiVersionSets/" + azureName}))
+			})
+
+			It("should requeue after the delay requested by Azure when it throttles", func() {
+				server.AddFault(apimfake.Fault{Method: http.MethodGet, Path: "apiVersionSets/", StatusCode: http.StatusTooManyRequests, RetryAfter: 7 * time.Second, Times: 1})
+
+				result, err := reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(7 * time.Second))
+				Expect(getApi().Status.ErrorClass).To(Equal(string(azure.ErrorClassThrottled)))
+
+				By("Clearing the error once Azure accepts the request")
+				_, err = reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApi().Status.ErrorClass).To(BeEmpty())
+				Expect(getApi().Status.ProvisioningState).To(Equal("Succeeded"))
+			})
+
+			It("should requeue when Azure reports a conflict", func() {
+				server.AddFault(apimfake.Fault{Method: http.MethodPut, Path: "apiVersionSets/", StatusCode: http.StatusConflict, Code: "Conflict", Times: 1})
+
+				result, err := reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeTrue())
+				Expect(getApi().Status.ErrorClass).To(Equal(string(azure.ErrorClassConflict)))
+				Expect(getApi().Status.ConflictCount).To(Equal(int32(1)))
+
+				_, err = reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApi().Status.ConflictCount).To(BeZero())
+			})
+
+			It("should remove the finalizer when the version set is already gone in Azure", func() {
+				Expect(k8s.Delete(ctx, getApi())).To(Succeed())
+
+				_, err := reconcileApi()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.Requests()).To(Equal([]string{"GET apiVersionSets/" + azureName}))
+				err = k8s.Get(ctx, typeNamespacedName, &apimv1alpha1.Api{})
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			})
 		})
 	})
 })
@@ -0,0 +1,423 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+	"github.com/tjololo/stilas-az/internal/utils"
+)
+
+// ApiOperationReconciler reconciles a ApiOperation object
+type ApiOperationReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	NewClient    newApimCLient
+	Recorder     record.EventRecorder
+	ResyncPeriod time.Duration
+	apimClient   azure.Client
+}
+
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apioperations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apioperations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apioperations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apiversions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile creates the operation under the blank API of the referenced ApiVersion once the API exists in APIM. The
+// operation is updated when its spec changed since it was last applied or it is missing in APIM.
+func (r *ApiOperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "ApiOperation", req)
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	var operation apimv1alpha1.ApiOperation
+	if err := r.Get(ctx, req.NamespacedName, &operation); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !controllerutil.ContainsFinalizer(&operation, "apioperation.finalizers.stilas.418.cloud") {
+		controllerutil.AddFinalizer(&operation, "apioperation.finalizers.stilas.418.cloud")
+		if err := r.Update(ctx, &operation); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+	if operation.DeletionTimestamp == nil && operation.Status.PermanentlyFailed(operation.Generation) {
+		logger.Info("Generation failed permanently, waiting for spec change", "generation", operation.Generation)
+		return ctrl.Result{}, nil
+	}
+	var apiVersion apimv1alpha1.ApiVersion
+	apiVersionErr := r.Get(ctx, types.NamespacedName{Namespace: operation.Namespace, Name: operation.Spec.ApiVersionRef}, &apiVersion)
+	if client.IgnoreNotFound(apiVersionErr) != nil {
+		logger.Error(apiVersionErr, "Failed to get ApiVersion")
+		return ctrl.Result{}, apiVersionErr
+	}
+	if apierrors.IsNotFound(apiVersionErr) {
+		if operation.DeletionTimestamp != nil {
+			// the operation was deleted in APIM together with the API
+			return r.removeFinalizer(ctx, &operation)
+		}
+		logger.Info("Waiting for ApiVersion", "apiVersion", operation.Spec.ApiVersionRef)
+		return r.updatePendingStatus(ctx, &operation)
+	}
+	subscriptionID, resourcesGroup, apimName, err := getConfigFromEnv()
+	if err != nil {
+		logger.Error(err, "Failed to get configuration. No reason to requeue")
+		return ctrl.Result{}, nil
+	}
+	r.apimClient, err = r.NewClient(azure.ApimClientConfig{
+		SubscriptionId:  subscriptionID,
+		ResourceGroup:   resourcesGroup,
+		ApimServiceName: apimName,
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	apiId := getApiVersionName(apiVersion)
+	operationId := getApiOperationName(operation)
+	azureOperation, err := r.apimClient.GetApiOperation(ctx, apiId, operationId, nil)
+	if operation.DeletionTimestamp != nil {
+		return r.deleteApiOperation(ctx, &operation, apiId, azureOperation, err)
+	}
+	if azure.IgnoreNotFound(err) != nil {
+		logger.Error(err, "Failed to get operation")
+		return azureErrorResult(ctx, r, &operation, &operation.Status.AzureResourceStatus, err)
+	}
+	operationExists := err == nil
+	if !metav1.IsControlledBy(&operation, &apiVersion) {
+		if err := controllerutil.SetControllerReference(&apiVersion, &operation, r.Scheme); err != nil {
+			logger.Error(err, "Failed to set owner reference")
+			return ctrl.Result{}, err
+		}
+		if err := r.Update(ctx, &operation); err != nil {
+			logger.Error(err, "Failed to set owner reference")
+			return ctrl.Result{}, err
+		}
+	}
+	if apiVersion.Spec.ContentFormat == nil || *apiVersion.Spec.ContentFormat != apimv1alpha1.ContentFormatNone {
+		// operations of an imported API are replaced on the next import
+		return r.invalidOperationResult(ctx, &operation, fmt.Sprintf("ApiVersion %s imports its operations from its content, operations can only be added to ApiVersions with contentFormat none", apiVersion.Name))
+	}
+	if message := validateUrlTemplate(operation); message != "" {
+		return r.invalidOperationResult(ctx, &operation, message)
+	}
+	if apiVersion.Status.LastAppliedSpecSha == "" {
+		logger.Info("Waiting for the API to be created", "apiVersion", apiVersion.Name)
+		return r.updatePendingStatus(ctx, &operation)
+	}
+
+	previousStatus := operation.Status.DeepCopy()
+	operation.Status.ETag = stringValue(azureOperation.ETag)
+	desired := toAzureOperation(operation)
+	desiredJson, err := json.Marshal(desired)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	specSha, err := utils.Sha256FromContent(ctx, string(desiredJson))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !operationExists || operation.Status.LastAppliedSpecSha != specSha || operationRequiresUpdate(azureOperation.Properties, desired.Properties) {
+		logger.Info("Creating or updating operation")
+		var options *apim.APIOperationClientCreateOrUpdateOptions
+		if operationExists {
+			options = &apim.APIOperationClientCreateOrUpdateOptions{IfMatch: azureOperation.ETag}
+		}
+		result, err := r.apimClient.CreateUpdateApiOperation(ctx, apiId, operationId, desired, options)
+		if err != nil {
+			logger.Error(err, "Failed to create/update operation")
+			r.Recorder.Event(&operation, corev1.EventTypeWarning, ReasonOperationFailed, eventMessage("Failed to apply operation", err))
+			operation.Status.ProvisioningState = "Failed"
+			return azureErrorResult(ctx, r, &operation, &operation.Status.AzureResourceStatus, err)
+		}
+		if operationExists {
+			r.Recorder.Event(&operation, corev1.EventTypeNormal, ReasonOperationUpdated, "Updated operation "+operationId)
+		} else {
+			r.Recorder.Event(&operation, corev1.EventTypeNormal, ReasonOperationCreated, "Created operation "+operationId)
+		}
+		operation.Status.OperationID = stringValue(result.ID)
+		operation.Status.ETag = stringValue(result.ETag)
+		operation.Status.LastAppliedSpecSha = specSha
+	}
+	operation.Status.ProvisioningState = "Succeeded"
+	clearAzureError(&operation.Status.AzureResourceStatus, operation.Generation)
+	if !reflect.DeepEqual(*previousStatus, operation.Status) {
+		if err := r.Status().Update(ctx, &operation); err != nil {
+			logger.Error(err, "Failed to update status")
+			return ctrl.Result{}, err
+		}
+	}
+	return resyncAfter(r.ResyncPeriod), nil
+}
+
+// deleteApiOperation deletes the operation in APIM using the ETag read from Azure as If-Match
+func (r *ApiOperationReconciler) deleteApiOperation(ctx context.Context, operation *apimv1alpha1.ApiOperation, apiId string, azureOperation apim.APIOperationClientGetResponse, getErr error) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Deleting operation")
+	if azure.IgnoreNotFound(getErr) != nil {
+		logger.Error(getErr, "Failed to get operation")
+		return azureErrorResult(ctx, r, operation, &operation.Status.AzureResourceStatus, getErr)
+	}
+	if getErr == nil {
+		_, err := r.apimClient.DeleteApiOperation(ctx, apiId, getApiOperationName(*operation), stringValue(azureOperation.ETag), nil)
+		if azure.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to delete operation")
+			r.Recorder.Event(operation, corev1.EventTypeWarning, ReasonDeleteFailed, eventMessage("Failed to delete operation", err))
+			return azureErrorResult(ctx, r, operation, &operation.Status.AzureResourceStatus, err)
+		}
+	}
+	r.Recorder.Event(operation, corev1.EventTypeNormal, ReasonDeleted, "Deleted operation "+getApiOperationName(*operation))
+	return r.removeFinalizer(ctx, operation)
+}
+
+func (r *ApiOperationReconciler) removeFinalizer(ctx context.Context, operation *apimv1alpha1.ApiOperation) (ctrl.Result, error) {
+	controllerutil.RemoveFinalizer(operation, "apioperation.finalizers.stilas.418.cloud")
+	if err := r.Update(ctx, operation); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to remove finalizer")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// updatePendingStatus marks the operation as waiting for its ApiVersion. The operation is reconciled again through the
+// ApiVersion watch once the ApiVersion is created or its API is imported.
+func (r *ApiOperationReconciler) updatePendingStatus(ctx context.Context, operation *apimv1alpha1.ApiOperation) (ctrl.Result, error) {
+	if operation.Status.ProvisioningState == "Pending" {
+		return ctrl.Result{}, nil
+	}
+	operation.Status.ProvisioningState = "Pending"
+	if err := r.Status().Update(ctx, operation); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// invalidOperationResult records that the operation can not be applied to its ApiVersion. It is not retried until the
+// operation or the ApiVersion changes, both trigger a reconcile through the watches.
+func (r *ApiOperationReconciler) invalidOperationResult(ctx context.Context, operation *apimv1alpha1.ApiOperation, message string) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("Operation is invalid", "reason", message)
+	if operation.Status.ProvisioningState == "Invalid" && operation.Status.ErrorMessage == message {
+		return ctrl.Result{}, nil
+	}
+	r.Recorder.Event(operation, corev1.EventTypeWarning, ReasonOperationInvalid, message)
+	operation.Status.ProvisioningState = "Invalid"
+	operation.Status.ErrorMessage = message
+	if err := r.Status().Update(ctx, operation); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ApiOperationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// ApiVersions are watched without predicates since operations wait for the import recorded in the ApiVersion status
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apimv1alpha1.ApiOperation{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&apimv1alpha1.ApiVersion{}, handler.EnqueueRequestsFromMapFunc(r.operationsForApiVersion)).
+		Complete(r)
+}
+
+// operationsForApiVersion maps an ApiVersion to the ApiOperations referencing it
+func (r *ApiOperationReconciler) operationsForApiVersion(ctx context.Context, obj client.Object) []reconcile.Request {
+	var operations apimv1alpha1.ApiOperationList
+	if err := r.List(ctx, &operations, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list ApiOperations for ApiVersion", "apiVersion", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, operation := range operations.Items {
+		if operation.Spec.ApiVersionRef == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&operation)})
+		}
+	}
+	return requests
+}
+
+func getApiOperationName(operation apimv1alpha1.ApiOperation) string {
+	return fmt.Sprintf("%s-%s", operation.Namespace, operation.Name)
+}
+
+// validateUrlTemplate returns a message describing the mismatch when the parameters of the URL template are not the
+// template parameters of the operation. APIM rejects operations where they differ.
+func validateUrlTemplate(operation apimv1alpha1.ApiOperation) string {
+	used := urlTemplateParameters(operation.Spec.UrlTemplate)
+	var described []string
+	for _, parameter := range operation.Spec.TemplateParameters {
+		described = append(described, parameter.Name)
+		if !slices.Contains(used, parameter.Name) {
+			return fmt.Sprintf("Template parameter %s is not used in urlTemplate %s", parameter.Name, operation.Spec.UrlTemplate)
+		}
+	}
+	for _, name := range used {
+		if !slices.Contains(described, name) {
+			return fmt.Sprintf("Parameter %s of urlTemplate %s is not described in templateParameters", name, operation.Spec.UrlTemplate)
+		}
+	}
+	return ""
+}
+
+// urlTemplateParameters returns the names of the {parameter} placeholders of the URL template
+func urlTemplateParameters(urlTemplate string) []string {
+	var names []string
+	for {
+		start := strings.Index(urlTemplate, "{")
+		if start < 0 {
+			return names
+		}
+		end := strings.Index(urlTemplate[start:], "}")
+		if end < 0 {
+			return names
+		}
+		names = append(names, strings.TrimPrefix(urlTemplate[start+1:start+end], "*"))
+		urlTemplate = urlTemplate[start+end+1:]
+	}
+}
+
+// operationRequiresUpdate returns true if the operation in Azure was changed outside the operator. The properties APIM
+// returns as they were sent are compared, changes to the other properties of the spec are found by the spec sha.
+func operationRequiresUpdate(current *apim.OperationContractProperties, desired *apim.OperationContractProperties) bool {
+	if current == nil {
+		return true
+	}
+	return stringValue(current.DisplayName) != stringValue(desired.DisplayName) ||
+		!strings.EqualFold(stringValue(current.Method), stringValue(desired.Method)) ||
+		stringValue(current.URLTemplate) != stringValue(desired.URLTemplate) ||
+		stringValue(current.Description) != stringValue(desired.Description) ||
+		!slices.Equal(parameterNames(current.TemplateParameters), parameterNames(desired.TemplateParameters)) ||
+		!slices.Equal(requestParameterNames(current.Request), requestParameterNames(desired.Request)) ||
+		!slices.Equal(responseStatusCodes(current.Responses), responseStatusCodes(desired.Responses))
+}
+
+func parameterNames(parameters []*apim.ParameterContract) []string {
+	var names []string
+	for _, parameter := range parameters {
+		names = append(names, stringValue(parameter.Name))
+	}
+	return names
+}
+
+// requestParameterNames returns the names of the query parameters followed by the names of the headers of the request
+func requestParameterNames(request *apim.RequestContract) []string {
+	if request == nil {
+		return nil
+	}
+	return append(parameterNames(request.QueryParameters), parameterNames(request.Headers)...)
+}
+
+func responseStatusCodes(responses []*apim.ResponseContract) []int32 {
+	var codes []int32
+	for _, response := range responses {
+		if response.StatusCode != nil {
+			codes = append(codes, *response.StatusCode)
+		}
+	}
+	return codes
+}
+
+func toAzureOperation(operation apimv1alpha1.ApiOperation) apim.OperationContract {
+	templateParameters := toAzureParameters(operation.Spec.TemplateParameters)
+	for _, parameter := range templateParameters {
+		// APIM requires template parameters to be required
+		parameter.Required = toPointer(true)
+	}
+	properties := &apim.OperationContractProperties{
+		DisplayName:        toPointer(operation.Spec.DisplayName),
+		Method:             toPointer(operation.Spec.Method),
+		URLTemplate:        toPointer(operation.Spec.UrlTemplate),
+		Description:        operation.Spec.Description,
+		TemplateParameters: templateParameters,
+	}
+	if request := operation.Spec.Request; request != nil {
+		properties.Request = &apim.RequestContract{
+			Description:     request.Description,
+			QueryParameters: toAzureParameters(request.QueryParameters),
+			Headers:         toAzureParameters(request.Headers),
+			Representations: toAzureRepresentations(request.Representations),
+		}
+	}
+	for _, response := range operation.Spec.Responses {
+		properties.Responses = append(properties.Responses, &apim.ResponseContract{
+			StatusCode:      toPointer(response.StatusCode),
+			Description:     response.Description,
+			Headers:         toAzureParameters(response.Headers),
+			Representations: toAzureRepresentations(response.Representations),
+		})
+	}
+	return apim.OperationContract{Properties: properties}
+}
+
+func toAzureParameters(parameters []apimv1alpha1.ApiOperationParameter) []*apim.ParameterContract {
+	var azureParameters []*apim.ParameterContract
+	for _, parameter := range parameters {
+		parameterType := parameter.Type
+		if parameterType == "" {
+			parameterType = "string"
+		}
+		azureParameter := &apim.ParameterContract{
+			Name:         toPointer(parameter.Name),
+			Type:         toPointer(parameterType),
+			Description:  parameter.Description,
+			Required:     parameter.Required,
+			DefaultValue: parameter.DefaultValue,
+		}
+		for _, value := range parameter.Values {
+			azureParameter.Values = append(azureParameter.Values, toPointer(value))
+		}
+		azureParameters = append(azureParameters, azureParameter)
+	}
+	return azureParameters
+}
+
+func toAzureRepresentations(representations []apimv1alpha1.ApiOperationRepresentation) []*apim.RepresentationContract {
+	var azureRepresentations []*apim.RepresentationContract
+	for _, representation := range representations {
+		azureRepresentation := &apim.RepresentationContract{ContentType: toPointer(representation.ContentType)}
+		if representation.Example != nil {
+			azureRepresentation.Examples = map[string]*apim.ParameterExampleContract{
+				"default": {Value: *representation.Example},
+			}
+		}
+		azureRepresentations = append(azureRepresentations, azureRepresentation)
+	}
+	return azureRepresentations
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure/mock"
+)
+
+var _ = Describe("ApiOperation Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+		const azureName = "default-" + resourceName
+		const apiId = "default-orders"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		var (
+			k8s           client.Client
+			apimClient    *mock.MockClient
+			reconciler    *ApiOperationReconciler
+			contentFormat apimv1alpha1.ContentFormat
+			specSha       string
+			urlTemplate   string
+		)
+
+		BeforeEach(func() {
+			contentFormat = apimv1alpha1.ContentFormatNone
+			specSha = "applied"
+			urlTemplate = "/orders/{orderId}"
+		})
+
+		JustBeforeEach(func() {
+			setAzureEnv()
+			By("creating the custom resource for the Kind ApiOperation")
+			k8s = newFakeClient(
+				&apimv1alpha1.ApiVersion{
+					ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default", UID: "orders-uid"},
+					Spec: apimv1alpha1.ApiVersionSpec{
+						Path:    "orders",
+						APIType: toPointer(apimv1alpha1.APITypeHTTP),
+						ApiVersionSubSpec: apimv1alpha1.ApiVersionSubSpec{
+							DisplayName:   "Orders",
+							ContentFormat: toPointer(contentFormat),
+						},
+					},
+					Status: apimv1alpha1.ApiVersionStatus{LastAppliedSpecSha: specSha},
+				},
+				&apimv1alpha1.ApiOperation{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       resourceName,
+						Namespace:  "default",
+						Generation: 1,
+						Finalizers: []string{"apioperation.finalizers.stilas.418.cloud"},
+					},
+					Spec: apimv1alpha1.ApiOperationSpec{
+						ApiVersionRef: "orders",
+						DisplayName:   "Get order",
+						Method:        "GET",
+						UrlTemplate:   urlTemplate,
+						TemplateParameters: []apimv1alpha1.ApiOperationParameter{
+							{Name: "orderId", Type: "string"},
+						},
+						Responses: []apimv1alpha1.ApiOperationResponse{
+							{
+								StatusCode:      200,
+								Representations: []apimv1alpha1.ApiOperationRepresentation{{ContentType: "application/json", Example: toPointer(`{"id":"1"}`)}},
+							},
+						},
+					},
+				},
+			)
+			apimClient = mock.NewMockClient(gomock.NewController(GinkgoT()))
+			reconciler = &ApiOperationReconciler{
+				Client:    k8s,
+				Scheme:    k8s.Scheme(),
+				NewClient: newClientFor(apimClient),
+				Recorder:  record.NewFakeRecorder(10),
+			}
+		})
+
+		reconcileOperation := func() (reconcile.Result, error) {
+			return reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		}
+		getOperation := func() *apimv1alpha1.ApiOperation {
+			operation := &apimv1alpha1.ApiOperation{}
+			Expect(k8s.Get(ctx, typeNamespacedName, operation)).To(Succeed())
+			return operation
+		}
+		// applied is the operation sent to Azure by the last create or update
+		var applied apim.OperationContract
+		expectCreate := func() {
+			apimClient.EXPECT().GetApiOperation(gomock.Any(), apiId, azureName, nil).
+				Return(apim.APIOperationClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+			apimClient.EXPECT().CreateUpdateApiOperation(gomock.Any(), apiId, azureName, gomock.Any(), nil).
+				DoAndReturn(func(_ context.Context, _ string, _ string, operation apim.OperationContract, _ *apim.APIOperationClientCreateOrUpdateOptions) (apim.APIOperationClientCreateOrUpdateResponse, error) {
+					applied = operation
+					Expect(*operation.Properties.Method).To(Equal("GET"))
+					Expect(*operation.Properties.URLTemplate).To(Equal("/orders/{orderId}"))
+					Expect(operation.Properties.TemplateParameters).To(HaveLen(1))
+					Expect(*operation.Properties.TemplateParameters[0].Required).To(BeTrue())
+					Expect(operation.Properties.Responses).To(HaveLen(1))
+					Expect(operation.Properties.Responses[0].Representations[0].Examples["default"].Value).To(Equal(`{"id":"1"}`))
+					return apim.APIOperationClientCreateOrUpdateResponse{
+						OperationContract: apim.OperationContract{ID: toPointer("/apis/" + apiId + "/operations/" + azureName)},
+						ETag:              toPointer(`"1"`),
+					}, nil
+				})
+		}
+
+		It("should create the operation under the ApiVersion", func() {
+			expectCreate()
+
+			result, err := reconcileOperation()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			operation := getOperation()
+			Expect(operation.Status.ProvisioningState).To(Equal("Succeeded"))
+			Expect(operation.Status.OperationID).To(Equal("/apis/" + apiId + "/operations/" + azureName))
+			Expect(operation.Status.ETag).To(Equal(`"1"`))
+			Expect(operation.Status.LastAppliedSpecSha).NotTo(BeEmpty())
+			Expect(operation.OwnerReferences).To(HaveLen(1))
+			Expect(operation.OwnerReferences[0].UID).To(Equal(types.UID("orders-uid")))
+		})
+
+		It("should not update the operation when the spec is unchanged", func() {
+			expectCreate()
+			_, err := reconcileOperation()
+			Expect(err).NotTo(HaveOccurred())
+
+			apimClient.EXPECT().GetApiOperation(gomock.Any(), apiId, azureName, nil).
+				Return(apim.APIOperationClientGetResponse{OperationContract: applied, ETag: toPointer(`"1"`)}, nil)
+
+			_, err = reconcileOperation()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getOperation().Status.ProvisioningState).To(Equal("Succeeded"))
+		})
+
+		It("should repair the operation when it was changed in Azure", func() {
+			expectCreate()
+			_, err := reconcileOperation()
+			Expect(err).NotTo(HaveOccurred())
+
+			changed := applied
+			properties := *applied.Properties
+			properties.URLTemplate = toPointer("/orders")
+			properties.TemplateParameters = nil
+			changed.Properties = &properties
+			apimClient.EXPECT().GetApiOperation(gomock.Any(), apiId, azureName, nil).
+				Return(apim.APIOperationClientGetResponse{OperationContract: changed, ETag: toPointer(`"2"`)}, nil)
+			apimClient.EXPECT().CreateUpdateApiOperation(gomock.Any(), apiId, azureName, gomock.Any(), &apim.APIOperationClientCreateOrUpdateOptions{IfMatch: toPointer(`"2"`)}).
+				DoAndReturn(func(_ context.Context, _ string, _ string, operation apim.OperationContract, _ *apim.APIOperationClientCreateOrUpdateOptions) (apim.APIOperationClientCreateOrUpdateResponse, error) {
+					Expect(*operation.Properties.URLTemplate).To(Equal("/orders/{orderId}"))
+					return apim.APIOperationClientCreateOrUpdateResponse{OperationContract: operation, ETag: toPointer(`"3"`)}, nil
+				})
+
+			_, err = reconcileOperation()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getOperation().Status.ETag).To(Equal(`"3"`))
+		})
+
+		Context("when the ApiVersion imports its operations", func() {
+			BeforeEach(func() {
+				contentFormat = apimv1alpha1.ContentFormatOpenapiJSON
+			})
+
+			It("should not create the operation", func() {
+				apimClient.EXPECT().GetApiOperation(gomock.Any(), apiId, azureName, nil).
+					Return(apim.APIOperationClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+
+				result, err := reconcileOperation()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(reconcile.Result{}))
+
+				operation := getOperation()
+				Expect(operation.Status.ProvisioningState).To(Equal("Invalid"))
+				Expect(operation.Status.ErrorMessage).To(ContainSubstring("contentFormat none"))
+			})
+		})
+
+		Context("when the urlTemplate has an undescribed parameter", func() {
+			BeforeEach(func() {
+				urlTemplate = "/orders/{orderId}/lines/{lineId}"
+			})
+
+			It("should not create the operation", func() {
+				apimClient.EXPECT().GetApiOperation(gomock.Any(), apiId, azureName, nil).
+					Return(apim.APIOperationClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+
+				_, err := reconcileOperation()
+				Expect(err).NotTo(HaveOccurred())
+
+				operation := getOperation()
+				Expect(operation.Status.ProvisioningState).To(Equal("Invalid"))
+				Expect(operation.Status.ErrorMessage).To(ContainSubstring("lineId"))
+			})
+		})
+
+		Context("when the API is not created yet", func() {
+			BeforeEach(func() {
+				specSha = ""
+			})
+
+			It("should wait for the API", func() {
+				apimClient.EXPECT().GetApiOperation(gomock.Any(), apiId, azureName, nil).
+					Return(apim.APIOperationClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+
+				_, err := reconcileOperation()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getOperation().Status.ProvisioningState).To(Equal("Pending"))
+			})
+		})
+
+		It("should delete the operation in Azure before removing the finalizer", func() {
+			Expect(k8s.Delete(ctx, getOperation())).To(Succeed())
+			apimClient.EXPECT().GetApiOperation(gomock.Any(), apiId, azureName, nil).
+				Return(apim.APIOperationClientGetResponse{ETag: toPointer(`"2"`)}, nil)
+			apimClient.EXPECT().DeleteApiOperation(gomock.Any(), apiId, azureName, `"2"`, nil).
+				Return(apim.APIOperationClientDeleteResponse{}, nil)
+
+			_, err := reconcileOperation()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8s.Get(ctx, typeNamespacedName, &apimv1alpha1.ApiOperation{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should remove the finalizer without calling Azure when the ApiVersion is gone", func() {
+			Expect(k8s.Delete(ctx, &apimv1alpha1.ApiVersion{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"}})).To(Succeed())
+			Expect(k8s.Delete(ctx, getOperation())).To(Succeed())
+
+			_, err := reconcileOperation()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8s.Get(ctx, typeNamespacedName, &apimv1alpha1.ApiOperation{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
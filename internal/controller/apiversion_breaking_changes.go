@@ -0,0 +1,170 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/utils"
+)
+
+// appliedOpenAPIKey is the key of the last imported OpenAPI document in the ConfigMap named by appliedOpenAPIConfigMapName
+const appliedOpenAPIKey = "openapi"
+
+// maxAppliedOpenAPISize leaves room for the metadata of the ConfigMap below the 1MiB limit of Kubernetes objects.
+// Larger documents are not stored and the import after them is not compared.
+const maxAppliedOpenAPISize = 900 * 1024
+
+// maxBreakingChanges is the number of breaking changes kept in status
+const maxBreakingChanges = 100
+
+// appliedOpenAPIConfigMapName is the name of the ConfigMap holding the last OpenAPI document imported for the ApiVersion
+func appliedOpenAPIConfigMapName(apiVersion apimv1alpha1.ApiVersion) string {
+	return apiVersion.Name + "-applied-openapi"
+}
+
+// appliedOpenAPIConfigMap returns the ConfigMap holding the last OpenAPI document imported for the ApiVersion, or nil
+// when it does not exist. A ConfigMap with the name that is not controlled by the ApiVersion is reported as an error
+// so it is neither compared with nor overwritten.
+func (r *ApiVersionReconciler) appliedOpenAPIConfigMap(ctx context.Context, apiVersion apimv1alpha1.ApiVersion) (*corev1.ConfigMap, error) {
+	configMap := &corev1.ConfigMap{}
+	name := appliedOpenAPIConfigMapName(apiVersion)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: apiVersion.Namespace, Name: name}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !metav1.IsControlledBy(configMap, &apiVersion) {
+		return nil, fmt.Errorf("ConfigMap %s is not controlled by ApiVersion %s", name, apiVersion.Name)
+	}
+	return configMap, nil
+}
+
+// checkBreakingChanges compares the OpenAPI document that is about to be imported with the last imported document and
+// records the breaking changes in status. It returns a message when the import is blocked by the breaking change policy.
+// The document is the content of the ApiVersion, downloaded when the content is a link. Nothing is compared before the
+// first import with a breaking change policy or when a document can not be parsed, the import reports invalid documents.
+func (r *ApiVersionReconciler) checkBreakingChanges(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion, document string) (string, error) {
+	logger := log.FromContext(ctx)
+	policy := apiVersion.Spec.BreakingChangePolicy
+	apiVersion.Status.BreakingChanges = nil
+	if policy == nil {
+		return "", nil
+	}
+	configMap, err := r.appliedOpenAPIConfigMap(ctx, *apiVersion)
+	if err != nil {
+		return "", err
+	}
+	previous, ok := "", false
+	if configMap != nil {
+		previous, ok = configMap.Data[appliedOpenAPIKey]
+	}
+	if !ok {
+		return "", nil
+	}
+	changes, err := utils.OpenAPIBreakingChanges(previous, document)
+	if err != nil {
+		logger.Error(err, "Failed to compare OpenAPI document with the last imported document")
+		return "", nil
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+	for _, change := range changes[:min(len(changes), maxBreakingChanges)] {
+		apiVersion.Status.BreakingChanges = append(apiVersion.Status.BreakingChanges, apimv1alpha1.BreakingChangeStatus{
+			Type:     change.Type,
+			Location: change.Location,
+			Message:  change.Message,
+		})
+	}
+	logger.Info("Found breaking changes in OpenAPI document", "count", len(changes), "policy", *policy)
+	switch *policy {
+	case apimv1alpha1.BreakingChangePolicyWarn:
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonBreakingChanges, "Importing "+breakingChangesMessage(changes))
+	case apimv1alpha1.BreakingChangePolicyBlock:
+		return "Blocked import of " + breakingChangesMessage(changes), nil
+	}
+	return "", nil
+}
+
+// breakingChangesMessage summarizes the breaking changes with the first few changes
+func breakingChangesMessage(changes []utils.BreakingChange) string {
+	var details []string
+	for _, change := range changes[:min(len(changes), 3)] {
+		details = append(details, fmt.Sprintf("%s: %s", change.Location, change.Message))
+	}
+	if len(changes) > len(details) {
+		details = append(details, fmt.Sprintf("and %d more", len(changes)-len(details)))
+	}
+	return fmt.Sprintf("OpenAPI document with %d breaking changes: %s", len(changes), strings.Join(details, "; "))
+}
+
+// blockImportResult records that the import is blocked by breaking changes or lint errors. It is not retried until the
+// spec or a LintRuleSet of the ApiVersion changes.
+func (r *ApiVersionReconciler) blockImportResult(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion, message string) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("Import is blocked", "reason", message)
+	if apiVersion.Status.ProvisioningState != "Blocked" || apiVersion.Status.ErrorMessage != message {
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonImportBlocked, message)
+	}
+	apiVersion.Status.ProvisioningState = "Blocked"
+	apiVersion.Status.ErrorMessage = message
+	if err := r.Status().Update(ctx, apiVersion); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// storeAppliedOpenAPI stores the imported OpenAPI document so the next import can be compared with it. The ConfigMap is
+// owned by the ApiVersion and removed with it. The stored document is removed when the document is too large to store.
+func (r *ApiVersionReconciler) storeAppliedOpenAPI(ctx context.Context, apiVersion apimv1alpha1.ApiVersion, document string) error {
+	configMap, err := r.appliedOpenAPIConfigMap(ctx, apiVersion)
+	if err != nil {
+		return err
+	}
+	if len(document) > maxAppliedOpenAPISize {
+		log.FromContext(ctx).Info("OpenAPI document is too large to store for comparison", "size", len(document))
+		if configMap == nil {
+			return nil
+		}
+		return client.IgnoreNotFound(r.Delete(ctx, configMap))
+	}
+	if configMap == nil {
+		configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      appliedOpenAPIConfigMapName(apiVersion),
+			Namespace: apiVersion.Namespace,
+		}}
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = map[string]string{appliedOpenAPIKey: document}
+		return controllerutil.SetControllerReference(&apiVersion, configMap, r.Scheme)
+	})
+	return err
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+)
+
+// apiVersionContent returns the content imported for the ApiVersion, read inline or from the ConfigMap referenced by
+// ContentFrom
+func apiVersionContent(ctx context.Context, c client.Reader, apiVersion apimv1alpha1.ApiVersion) (string, error) {
+	source := apiVersion.Spec.ContentFrom
+	if source == nil || source.ConfigMapKeyRef == nil {
+		return stringValue(apiVersion.Spec.Content), nil
+	}
+	return configMapKeyValue(ctx, c, apiVersion.Namespace, *source.ConfigMapKeyRef)
+}
+
+// configMapKeyValue returns the value of a key of a ConfigMap in the namespace
+func configMapKeyValue(ctx context.Context, c client.Reader, namespace string, ref corev1.ConfigMapKeySelector) (string, error) {
+	var configMap corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &configMap); err != nil {
+		return "", err
+	}
+	value, ok := configMap.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in ConfigMap %s", ref.Key, ref.Name)
+	}
+	return value, nil
+}
+
+// apiVersionsForConfigMap maps a ConfigMap to the ApiVersions in its namespace reading their content or GraphQL schema
+// from it
+func (r *ApiVersionReconciler) apiVersionsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	var apiVersions apimv1alpha1.ApiVersionList
+	if err := r.List(ctx, &apiVersions, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list ApiVersions for ConfigMap", "configMap", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, apiVersion := range apiVersions.Items {
+		if referencesConfigMap(apiVersion, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&apiVersion)})
+		}
+	}
+	return requests
+}
+
+func referencesConfigMap(apiVersion apimv1alpha1.ApiVersion, name string) bool {
+	if source := apiVersion.Spec.ContentFrom; source != nil && source.ConfigMapKeyRef != nil && source.ConfigMapKeyRef.Name == name {
+		return true
+	}
+	source := apiVersion.Spec.GraphQLSchema
+	return source != nil && source.ConfigMapKeyRef != nil && source.ConfigMapKeyRef.Name == name
+}
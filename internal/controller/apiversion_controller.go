@@ -18,17 +18,25 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
 	"github.com/tjololo/stilas-az/internal/azure"
 	"github.com/tjololo/stilas-az/internal/utils"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
 )
@@ -36,14 +44,20 @@ import (
 // ApiVersionReconciler reconciles a ApiVersion object
 type ApiVersionReconciler struct {
 	client.Client
-	Scheme     *runtime.Scheme
-	NewClient  newApimCLient
-	apimClient *azure.APIMClient
+	Scheme       *runtime.Scheme
+	NewClient    newApimCLient
+	Recorder     record.EventRecorder
+	ResyncPeriod time.Duration
+	apimClient   azure.Client
 }
 
 // +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apiversions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apiversions/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apiversions/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=backends,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=lintrulesets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -55,6 +69,8 @@ type ApiVersionReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.19.0/pkg/reconcile
 func (r *ApiVersionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "ApiVersion", req)
+	defer span.End()
 	logger := log.FromContext(ctx)
 	var apiVersion apimv1alpha1.ApiVersion
 	if err := r.Get(ctx, req.NamespacedName, &apiVersion); err != nil {
@@ -71,6 +87,10 @@ func (r *ApiVersionReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			return ctrl.Result{}, err
 		}
 	}
+	if apiVersion.DeletionTimestamp == nil && apiVersion.Status.PermanentlyFailed(apiVersion.Generation) {
+		logger.Info("Generation failed permanently, waiting for spec change", "generation", apiVersion.Generation)
+		return ctrl.Result{}, nil
+	}
 	subscriptionID, resourcesGroup, apimName, err := getConfigFromEnv()
 	if err != nil {
 		logger.Error(err, "Failed to get configuration. No reason to requeue")
@@ -85,42 +105,168 @@ func (r *ApiVersionReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		logger.Error(err, "Failed to create APIM client")
 		return ctrl.Result{}, err
 	}
-	_, err = r.apimClient.GetApi(ctx, getApiVersionName(apiVersion), nil)
+	azureApi, err := r.apimClient.GetApi(ctx, getApiVersionName(apiVersion), nil)
 	if apiVersion.DeletionTimestamp != nil {
-		return r.deleteApiVersion(ctx, apiVersion)
+		return r.deleteApiVersion(ctx, apiVersion, azureApi, err)
+	}
+	now := time.Now()
+	if apiVersion.Spec.Deprecation.IsSunset(now) && apiVersion.Spec.Deprecation.AfterSunset == apimv1alpha1.SunsetActionRemove {
+		return r.removeSunsetApi(ctx, apiVersion, azureApi, err, now)
 	}
 	if azure.IgnoreNotFound(err) != nil {
 		logger.Error(err, "Failed to get API")
-		return ctrl.Result{}, err
+		return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
 	} else {
-		latestSha, shaErr := utils.Sha256FromContent(*apiVersion.Spec.Content)
+		previousStatus := apiVersion.Status.DeepCopy()
+		apiVersion.Status.ETag = stringValue(azureApi.ETag)
+		content, contentErr := apiVersionContent(ctx, r.Client, apiVersion)
+		if contentErr != nil {
+			logger.Error(contentErr, "Failed to read content")
+			return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, contentErr)
+		}
+		// the document is downloaded once when a link is compared with the last import, the sha is the same as the sha
+		// of the link
+		document := content
+		if apiVersion.Spec.BreakingChangePolicy != nil {
+			if document, contentErr = utils.ReadContent(ctx, content); contentErr != nil {
+				logger.Error(contentErr, "Failed to download content")
+				return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, contentErr)
+			}
+		}
+		latestSha, shaErr := contentSha(ctx, document, apiVersion.Spec)
 		if shaErr != nil {
-			logger.Error(err, "Failed to get content sha")
-			return ctrl.Result{}, err
+			logger.Error(shaErr, "Failed to get content sha")
+			return ctrl.Result{}, shaErr
 		}
 		if apiVersion.Status.LastAppliedSpecSha != latestSha || azure.IsNotFoundError(err) {
-			return r.createUpdateApimApi(ctx, apiVersion)
+			if azure.IsNotFoundError(err) {
+				// a new API has no schema, so it is uploaded again after the import
+				apiVersion.Status.LastAppliedSchemaSha = ""
+			}
+			if apiVersion.Status.ResumeToken != "" && apiVersion.Status.ResumeSpecSha != latestSha {
+				// resuming would complete the import of the previous spec, so the import is started again
+				logger.Info("Spec changed while the import was in progress, starting a new import")
+				apiVersion.Status.ResumeToken = ""
+				apiVersion.Status.ResumeSpecSha = ""
+			}
+			if apiVersion.Status.ResumeToken == "" {
+				// an import that is in progress was linted and compared when it was started
+				blockMessage, lintErr := r.lintOpenAPI(ctx, &apiVersion, content)
+				if lintErr != nil {
+					logger.Error(lintErr, "Failed to lint OpenAPI document")
+					return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, lintErr)
+				}
+				if blockMessage != "" {
+					return r.blockImportResult(ctx, &apiVersion, blockMessage)
+				}
+				blockMessage, checkErr := r.checkBreakingChanges(ctx, &apiVersion, document)
+				if checkErr != nil {
+					logger.Error(checkErr, "Failed to check for breaking changes")
+					return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, checkErr)
+				}
+				if blockMessage != "" {
+					return r.blockImportResult(ctx, &apiVersion, blockMessage)
+				}
+			}
+			if apiVersion.Spec.Revision.IsEnabled() && err == nil {
+				return r.createUpdateApimApi(ctx, apiVersion, content, document, latestSha, nextRevision(apiVersion, currentRevision(azureApi)))
+			}
+			return r.createUpdateApimApi(ctx, apiVersion, content, document, latestSha, "")
 		}
-		_, policyErr := r.apimClient.GetApiPolicy(ctx, getApiVersionName(apiVersion), nil)
-		lastPolicySha, shaErr := utils.Sha256FromContent(*apiVersion.Spec.Policy.PolicyContent)
-		if shaErr != nil {
-			logger.Error(err, "Failed to get policy sha")
-			return ctrl.Result{}, err
+		if apiVersion.Spec.Revision.IsEnabled() {
+			if err := r.reconcileRevision(ctx, &apiVersion, currentRevision(azureApi)); err != nil {
+				logger.Error(err, "Failed to reconcile revision")
+				return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
+			}
+		}
+		if !apiVersion.Spec.Revision.IsEnabled() {
+			if err := r.releaseAppliedSpec(ctx, &apiVersion); err != nil {
+				return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
+			}
+		}
+		schemaPending := false
+		if apiVersion.Spec.GraphQLSchema != nil {
+			applied, schemaErr := r.applyGraphQLSchema(ctx, &apiVersion)
+			if schemaErr != nil {
+				logger.Error(schemaErr, "Failed to apply GraphQL schema")
+				return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, schemaErr)
+			}
+			schemaPending = !applied
 		}
-		if apiVersion.Spec.Policy != nil && apiVersion.Status.LastAppliedPolicySha != lastPolicySha || azure.IsNotFoundError(policyErr) {
-			if err := r.createUpdatePolicy(ctx, apiVersion); err != nil {
-				logger.Error(err, "Failed to create/update policy")
+		policyContent, policyFormat, renderErr := r.apiPolicyContent(ctx, apiVersion, now)
+		if renderErr != nil {
+			logger.Error(renderErr, "Failed to render policy")
+			r.Recorder.Event(&apiVersion, corev1.EventTypeWarning, ReasonPolicyFailed, eventMessage("Failed to render policy", renderErr))
+			return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, renderErr)
+		}
+		if policyContent != "" {
+			azurePolicy, policyErr := r.apimClient.GetApiPolicy(ctx, getApiVersionName(apiVersion), nil)
+			if azure.IgnoreNotFound(policyErr) != nil {
+				logger.Error(policyErr, "Failed to get policy")
+				return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, policyErr)
+			}
+			apiVersion.Status.PolicyETag = stringValue(azurePolicy.ETag)
+			lastPolicySha, shaErr := utils.Sha256FromContent(ctx, policyContent)
+			if shaErr != nil {
+				logger.Error(shaErr, "Failed to get policy sha")
+				return ctrl.Result{}, shaErr
+			}
+			if apiVersion.Status.LastAppliedPolicySha != lastPolicySha || azure.IsNotFoundError(policyErr) {
+				if err := r.createUpdatePolicy(ctx, &apiVersion, policyContent, policyFormat, lastPolicySha); err != nil {
+					logger.Error(err, "Failed to create/update policy")
+					return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
+				}
+			}
+		} else if apiVersion.Status.LastAppliedPolicySha != "" {
+			// the policy or the deprecation headers were removed from the spec
+			if err := r.deletePolicy(ctx, &apiVersion); err != nil {
+				logger.Error(err, "Failed to delete policy")
+				return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
+			}
+		}
+		if apiVersion.Spec.WebSocket != nil && apiVersion.Spec.WebSocket.OnHandshakePolicy != nil {
+			if err := r.applyOnHandshakePolicy(ctx, &apiVersion); err != nil {
+				logger.Error(err, "Failed to apply onHandshake policy")
+				return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
+			}
+		} else if apiVersion.Status.LastAppliedOnHandshakePolicySha != "" {
+			// the onHandshake policy was removed from the spec
+			if err := r.deleteOnHandshakePolicy(ctx, &apiVersion); err != nil {
+				logger.Error(err, "Failed to delete onHandshake policy")
+				return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
+			}
+		}
+		if apiVersion.Spec.Lint == nil {
+			// the findings are cleared when linting is turned off, without importing the document again
+			apiVersion.Status.LintFindings = nil
+			meta.RemoveStatusCondition(&apiVersion.Status.Conditions, apimv1alpha1.ConditionTypeLintWarnings)
+		}
+		r.recordDeprecationStage(&apiVersion, now)
+		clearAzureError(&apiVersion.Status.AzureResourceStatus, apiVersion.Generation)
+		if !reflect.DeepEqual(*previousStatus, apiVersion.Status) {
+			if err := r.Status().Update(ctx, &apiVersion); err != nil {
+				logger.Error(err, "Failed to update status")
 				return ctrl.Result{}, err
 			}
 		}
-		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		if schemaPending {
+			return ctrl.Result{RequeueAfter: lroPollInterval}, nil
+		}
+		return deprecationRequeue(resyncAfter(r.ResyncPeriod), apiVersion.Spec.Deprecation, now), nil
 	}
 }
 
+// apiVersionPredicate skips status-only updates of an ApiVersion. Annotation changes are reconciled as well since
+// revisions are approved and rolled back with annotations, and label changes since labels and annotations are
+// available to policy templates.
+var apiVersionPredicate = predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}, predicate.LabelChangedPredicate{})
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ApiVersionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&apimv1alpha1.ApiVersion{}).
+		For(&apimv1alpha1.ApiVersion{}, builder.WithPredicates(apiVersionPredicate)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.apiVersionsForConfigMap)).
+		Watches(&apimv1alpha1.LintRuleSet{}, handler.EnqueueRequestsFromMapFunc(r.apiVersionsForLintRuleSet)).
 		Complete(r)
 }
 
@@ -128,33 +274,58 @@ func getApiVersionName(apiVersion apimv1alpha1.ApiVersion) string {
 	return fmt.Sprintf("%s-%s", apiVersion.Namespace, apiVersion.Name)
 }
 
-func (r *ApiVersionReconciler) createUpdateApimApi(ctx context.Context, apiVesrion apimv1alpha1.ApiVersion) (ctrl.Result, error) {
+// createUpdateApimApi creates or updates the API in APIM with the content read by apiVersionContent. document is the
+// content, downloaded when it is a link and compared for breaking changes, and is stored when the import completes.
+// specSha is the contentSha of the spec and is recorded as applied when the import completes. If revision is set the
+// changes are staged in that revision and the revision is tracked as pending until it is promoted.
+func (r *ApiVersionReconciler) createUpdateApimApi(ctx context.Context, apiVesrion apimv1alpha1.ApiVersion, content string, document string, specSha string, revision string) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	resumeToken := apiVesrion.Status.ResumeToken
-	logger.Info("Creating or updating API")
-	apimApiParams := apiVersionToUpdateParameter(apiVesrion)
-	poller, err := r.apimClient.CreateUpdateApi(
-		ctx,
-		getApiVersionName(apiVesrion),
-		apimApiParams,
-		&apim.APIClientBeginCreateOrUpdateOptions{ResumeToken: resumeToken})
+	logger.Info("Creating or updating API", "revision", revision)
+	apiId := getApiVersionName(apiVesrion)
+	apimApiParams := apiVersionToUpdateParameter(apiVesrion, content)
+	if revision != "" {
+		apimApiParams.Properties.SourceAPIID = toPointer("/apis/" + apiId)
+		apiId = getApiRevisionName(apiVesrion, revision)
+		apiVesrion.Status.PendingRevision = revision
+	}
+	options := &apim.APIClientBeginCreateOrUpdateOptions{ResumeToken: resumeToken}
+	if revision == "" && apiVesrion.Status.ETag != "" {
+		options.IfMatch = toPointer(apiVesrion.Status.ETag)
+	}
+	poller, err := r.apimClient.CreateUpdateApi(ctx, apiId, apimApiParams, options)
 
 	if err != nil {
 		logger.Error(err, "Failed to create/update API")
-		return ctrl.Result{}, err
+		r.Recorder.Event(&apiVesrion, corev1.EventTypeWarning, ReasonImportFailed, eventMessage("Failed to start API import", err))
+		// a resume token that cannot be resumed is dropped so the import is started again
+		apiVesrion.Status.ResumeToken = ""
+		apiVesrion.Status.ResumeSpecSha = ""
+		return azureErrorResult(ctx, r, &apiVesrion, &apiVesrion.Status.AzureResourceStatus, err)
+	}
+	if resumeToken == "" {
+		r.Recorder.Event(&apiVesrion, corev1.EventTypeNormal, ReasonImportStarted, "Started import of API "+apiId)
 	}
 	logger.Info("Watching LR operation")
-	status, _, token, err := azure.StartResumeOperation(ctx, poller)
+	op, err := azure.StartResumeOperation(ctx, poller)
 	if err != nil {
 		logger.Error(err, "Failed to watch LR operation")
-		return ctrl.Result{}, err
+		r.Recorder.Event(&apiVesrion, corev1.EventTypeWarning, ReasonImportFailed, eventMessage("Failed to poll import of API "+apiId, err))
+		if op.ResumeToken != "" {
+			apiVesrion.Status.ResumeToken = op.ResumeToken
+			apiVesrion.Status.ResumeSpecSha = specSha
+		}
+		return azureErrorResult(ctx, r, &apiVesrion, &apiVesrion.Status.AzureResourceStatus, err)
 	}
 
-	switch status {
+	switch op.Status {
 	case azure.OperationStatusFailed:
-		logger.Error(err, "Failed to watch LR operation")
+		logger.Error(op.Error, "LR operation failed", "target", op.Error.Target, "details", op.Error.Details)
+		r.Recorder.Event(&apiVesrion, corev1.EventTypeWarning, ReasonImportFailed, fmt.Sprintf("Import of API %s failed: %s", apiId, op.Error.Error()))
 		apiVesrion.Status.ResumeToken = ""
+		apiVesrion.Status.ResumeSpecSha = ""
 		apiVesrion.Status.ProvisioningState = "Failed"
+		apiVesrion.Status.LastOperationError = toOperationErrorStatus(op.Error)
 		err = r.Status().Update(ctx, &apiVesrion)
 		if err != nil {
 			logger.Error(err, "Failed to update status")
@@ -162,101 +333,231 @@ func (r *ApiVersionReconciler) createUpdateApimApi(ctx context.Context, apiVesri
 		return ctrl.Result{}, err
 	case azure.OperationStatusInProgress:
 		apiVesrion.Status.ProvisioningState = "Provisioning"
-		apiVesrion.Status.ResumeToken = token
+		apiVesrion.Status.ResumeToken = op.ResumeToken
+		apiVesrion.Status.ResumeSpecSha = specSha
+		clearAzureError(&apiVesrion.Status.AzureResourceStatus, apiVesrion.Generation)
 		err = r.Status().Update(ctx, &apiVesrion)
 		if err != nil {
 			logger.Error(err, "Failed to update status")
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: max(op.RetryAfter, lroPollInterval)}, nil
 	case azure.OperationStatusSucceeded:
 		logger.Info("Operation completed")
+		r.Recorder.Event(&apiVesrion, corev1.EventTypeNormal, ReasonImportCompleted, "Completed import of API "+apiId)
 		apiVesrion.Status.ResumeToken = ""
+		apiVesrion.Status.ResumeSpecSha = ""
 		apiVesrion.Status.ProvisioningState = "Succeeded"
-		apiVesrion.Status.LastAppliedSpecSha, err = utils.Sha256FromContent(*apiVesrion.Spec.Content)
+		apiVesrion.Status.LastOperationError = nil
+		clearAzureError(&apiVesrion.Status.AzureResourceStatus, apiVesrion.Generation)
+		apiVesrion.Status.LastAppliedSpecSha = specSha
+		if apiVesrion.Spec.BreakingChangePolicy != nil {
+			if storeErr := r.storeAppliedOpenAPI(ctx, apiVesrion, document); storeErr != nil {
+				// the next import is compared with the document stored before this import
+				logger.Error(storeErr, "Failed to store imported OpenAPI document")
+			}
+		}
+		if revision == "" {
+			if releaseErr := r.releaseAppliedSpec(ctx, &apiVesrion); releaseErr != nil {
+				// the applied spec is recorded with the error and the release is retried by the next reconcile
+				return azureErrorResult(ctx, r, &apiVesrion, &apiVesrion.Status.AzureResourceStatus, releaseErr)
+			}
+		}
 		err = r.Status().Update(ctx, &apiVesrion)
 		if err != nil {
 			logger.Error(err, "Failed to update status")
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+		if apiVesrion.Spec.GraphQLSchema != nil {
+			// the schema of a synthetic GraphQL API is uploaded once the API exists
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return resyncAfter(r.ResyncPeriod), nil
 	}
 
-	return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+	return resyncAfter(r.ResyncPeriod), nil
 }
 
-func (r *ApiVersionReconciler) createUpdatePolicy(ctx context.Context, apiVersion apimv1alpha1.ApiVersion) error {
+// createUpdatePolicy applies the policy to the API. The policy ETag in status is sent as If-Match when the policy exists.
+// The status is updated by the caller.
+func (r *ApiVersionReconciler) createUpdatePolicy(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion, policyContent string, policyFormat *apim.PolicyContentFormat, policySha string) error {
 	logger := log.FromContext(ctx)
-	if apiVersion.Spec.Policy == nil {
-		return nil
-	}
 	logger.Info("Creating or updating policy")
-	policy := apiVersion.Spec.Policy
-	policyContent := *policy.PolicyContent
-	policyFormat := policy.PolicyFormat.AzurePolicyFormat()
-	_, err := r.apimClient.CreateUpdateApiPolicy(
+	var options *apim.APIPolicyClientCreateOrUpdateOptions
+	if apiVersion.Status.PolicyETag != "" {
+		options = &apim.APIPolicyClientCreateOrUpdateOptions{IfMatch: toPointer(apiVersion.Status.PolicyETag)}
+	}
+	result, err := r.apimClient.CreateUpdateApiPolicy(
 		ctx,
-		getApiVersionName(apiVersion),
+		getApiVersionName(*apiVersion),
 		apim.PolicyContract{
 			Properties: &apim.PolicyContractProperties{
 				Value:  &policyContent,
 				Format: policyFormat,
 			}},
-		nil,
+		options,
 	)
 	if err != nil {
 		logger.Error(err, "Failed to create/update policy")
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonPolicyFailed, eventMessage("Failed to apply policy", err))
 		return err
 	}
-	apiVersion.Status.LastAppliedPolicySha, err = utils.Sha256FromContent(*apiVersion.Spec.Policy.PolicyContent)
-	if err != nil {
-		logger.Error(err, "Failed to get policy sha")
-		return err
+	r.Recorder.Event(apiVersion, corev1.EventTypeNormal, ReasonPolicyApplied, "Applied policy")
+	apiVersion.Status.LastAppliedPolicySha = policySha
+	apiVersion.Status.PolicyETag = stringValue(result.ETag)
+	return nil
+}
+
+// deletePolicy deletes the policy of the API using the ETag read from Azure as If-Match. The status is updated by the caller.
+func (r *ApiVersionReconciler) deletePolicy(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion) error {
+	log.FromContext(ctx).Info("Deleting policy")
+	azurePolicy, err := r.apimClient.GetApiPolicy(ctx, getApiVersionName(*apiVersion), nil)
+	if err == nil {
+		_, err = r.apimClient.DeleteApiPolicy(ctx, getApiVersionName(*apiVersion), stringValue(azurePolicy.ETag), nil)
 	}
-	err = r.Status().Update(ctx, &apiVersion)
-	if err != nil {
-		logger.Error(err, "Failed to update status")
+	if azure.IgnoreNotFound(err) != nil {
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonPolicyFailed, eventMessage("Failed to delete policy", err))
 		return err
 	}
+	r.Recorder.Event(apiVersion, corev1.EventTypeNormal, ReasonPolicyDeleted, "Deleted policy")
+	apiVersion.Status.LastAppliedPolicySha = ""
+	apiVersion.Status.PolicyETag = ""
 	return nil
 }
 
-func apiVersionToUpdateParameter(apiVesrion apimv1alpha1.ApiVersion) apim.APICreateOrUpdateParameter {
-	return apim.APICreateOrUpdateParameter{
+func apiVersionToUpdateParameter(apiVesrion apimv1alpha1.ApiVersion, content string) apim.APICreateOrUpdateParameter {
+	// synthetic GraphQL APIs get their schema uploaded separately, WebSocket APIs have no content and blank APIs get their
+	// operations from ApiOperation resources
+	var format *apim.ContentFormat
+	var value *string
+	blank := apiVesrion.Spec.ContentFormat != nil && *apiVesrion.Spec.ContentFormat == apimv1alpha1.ContentFormatNone
+	if apiVesrion.Spec.GraphQLSchema == nil && apiVesrion.Spec.WebSocket == nil && !blank {
+		format, value = apiVesrion.Spec.ContentFormat.AzureContentFormat(), &content
+	}
+	soapApiType := apiVesrion.Spec.SoapMode.AzureSoapApiType()
+	if apiVesrion.Spec.APIType != nil {
+		// gRPC and OData APIs are imported with the import type of the API type to get their front end
+		switch *apiVesrion.Spec.APIType {
+		case apimv1alpha1.APITypeGrpc:
+			soapApiType = toPointer(azure.SoapAPITypeGrpc)
+		case apimv1alpha1.APITypeOdata:
+			soapApiType = toPointer(azure.SoapAPITypeOdata)
+		}
+	}
+	params := apim.APICreateOrUpdateParameter{
 		Properties: &apim.APICreateOrUpdateProperties{
-			Path:                 &apiVesrion.Spec.Path,
-			APIType:              apiVesrion.Spec.APIType.AzureApiType(),
-			Contact:              apiVesrion.Spec.Contact.AzureAPIContactInformation(),
-			Description:          &apiVesrion.Spec.Description,
-			DisplayName:          &apiVesrion.Spec.DisplayName,
-			Format:               apiVesrion.Spec.ContentFormat.AzureContentFormat(),
-			IsCurrent:            apiVesrion.Spec.IsCurrent,
-			Protocols:            apimv1alpha1.ToApimProtocolSlice(apiVesrion.Spec.Protocols),
-			ServiceURL:           apiVesrion.Spec.ServiceUrl,
-			SubscriptionRequired: apiVesrion.Spec.SubscriptionRequired,
-			Value:                apiVesrion.Spec.Content,
-			APIVersionSetID:      toPointer(apiVesrion.Spec.ApiVersionSetId),
-			APIVersion:           apiVesrion.Spec.Name,
+			Path:                          &apiVesrion.Spec.Path,
+			APIType:                       apiVesrion.Spec.APIType.AzureApiType(),
+			Contact:                       apiVesrion.Spec.Contact.AzureAPIContactInformation(),
+			Description:                   &apiVesrion.Spec.Description,
+			DisplayName:                   &apiVesrion.Spec.DisplayName,
+			Format:                        format,
+			IsCurrent:                     apiVesrion.Spec.IsCurrent,
+			Protocols:                     apimv1alpha1.ToApimProtocolSlice(apiVesrion.Spec.Protocols),
+			ServiceURL:                    apiVesrion.Spec.ServiceUrl,
+			SubscriptionRequired:          apiVesrion.Spec.SubscriptionRequired,
+			Value:                         value,
+			SoapAPIType:                   soapApiType,
+			WsdlSelector:                  apiVesrion.Spec.WsdlSelector.AzureWsdlSelector(),
+			APIVersionDescription:         deprecationDescription(apiVesrion.Spec.ApiVersionSubSpec),
+			APIRevisionDescription:        apiVesrion.Spec.RevisionDescription,
+			License:                       apiVesrion.Spec.License.AzureAPILicenseInformation(),
+			TermsOfServiceURL:             apiVesrion.Spec.TermsOfServiceUrl,
+			AuthenticationSettings:        apiVesrion.Spec.AuthenticationSettings.AzureAuthenticationSettings(),
+			SubscriptionKeyParameterNames: apiVesrion.Spec.SubscriptionKeyParameterNames.AzureSubscriptionKeyParameterNames(),
 		},
 	}
+	// standalone ApiVersions are created as unversioned APIs, APIM rejects a version without a version set
+	if apiVesrion.Spec.ApiVersionSetId != "" {
+		params.Properties.APIVersionSetID = toPointer(apiVesrion.Spec.ApiVersionSetId)
+		params.Properties.APIVersion = apiVesrion.Spec.Name
+	}
+	return params
+}
+
+// contentSha returns the sha of the content that is imported. Inline documents, including WSDL, are hashed as is and
+// linked documents are downloaded. The WSDL import settings change the imported API and are hashed with the content when set,
+// as are the API properties that are not part of the content and the versioning scheme, so changing them applies the API again.
+func contentSha(ctx context.Context, content string, spec apimv1alpha1.ApiVersionSpec) (string, error) {
+	sha, err := utils.Sha256FromContent(ctx, content)
+	if err != nil {
+		return sha, err
+	}
+	if spec.WsdlSelector != nil || spec.SoapMode != nil {
+		var serviceName, endpointName string
+		if spec.WsdlSelector != nil {
+			serviceName, endpointName = stringValue(spec.WsdlSelector.ServiceName), stringValue(spec.WsdlSelector.EndpointName)
+		}
+		var soapMode apimv1alpha1.SoapMode
+		if spec.SoapMode != nil {
+			soapMode = *spec.SoapMode
+		}
+		sha, err = utils.Sha256FromContent(ctx, fmt.Sprintf("%s|%s|%s|%s", sha, serviceName, endpointName, soapMode))
+		if err != nil {
+			return sha, err
+		}
+	}
+	properties := apiProperties{
+		VersionDescription:            spec.VersionDescription,
+		RevisionDescription:           spec.RevisionDescription,
+		License:                       spec.License,
+		TermsOfServiceUrl:             spec.TermsOfServiceUrl,
+		AuthenticationSettings:        spec.AuthenticationSettings,
+		SubscriptionKeyParameterNames: spec.SubscriptionKeyParameterNames,
+		Deprecation:                   spec.Deprecation,
+		ApiVersionScheme:              spec.ApiVersionScheme,
+	}
+	if properties == (apiProperties{}) {
+		return sha, nil
+	}
+	propertiesJson, err := json.Marshal(properties)
+	if err != nil {
+		return "", err
+	}
+	return utils.Sha256FromContent(ctx, fmt.Sprintf("%s|%s", sha, propertiesJson))
 }
 
-func (r *ApiVersionReconciler) deleteApiVersion(ctx context.Context, apiVersion apimv1alpha1.ApiVersion) (ctrl.Result, error) {
+// apiProperties are the properties of the API that are applied with the content but not part of it
+type apiProperties struct {
+	VersionDescription            *string                                     `json:"versionDescription,omitempty"`
+	RevisionDescription           *string                                     `json:"revisionDescription,omitempty"`
+	License                       *apimv1alpha1.APILicenseInformation         `json:"license,omitempty"`
+	TermsOfServiceUrl             *string                                     `json:"termsOfServiceUrl,omitempty"`
+	AuthenticationSettings        *apimv1alpha1.AuthenticationSettings        `json:"authenticationSettings,omitempty"`
+	SubscriptionKeyParameterNames *apimv1alpha1.SubscriptionKeyParameterNames `json:"subscriptionKeyParameterNames,omitempty"`
+	Deprecation                   *apimv1alpha1.ApiDeprecationSpec            `json:"deprecation,omitempty"`
+	ApiVersionScheme              apimv1alpha1.APIVersionScheme               `json:"apiVersionScheme,omitempty"`
+}
+
+// deleteApiVersion deletes the policy and the API in APIM using the ETags read from Azure as If-Match.
+// A modification in Azure since the read fails the delete, and it is retried after the resources are read again.
+func (r *ApiVersionReconciler) deleteApiVersion(ctx context.Context, apiVersion apimv1alpha1.ApiVersion, azureApi apim.APIClientGetResponse, getErr error) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Deleting APIVersion")
-	_, err := r.apimClient.DeleteApi(ctx, getApiVersionName(apiVersion), "*", nil)
-	if azure.IgnoreNotFound(err) != nil {
-		logger.Error(err, "Failed to delete APIVersion")
-		return ctrl.Result{}, err
+	if azure.IgnoreNotFound(getErr) != nil {
+		logger.Error(getErr, "Failed to get API")
+		return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, getErr)
 	}
-	_, err = r.apimClient.DeleteApiPolicy(ctx, getApiVersionName(apiVersion), "*", nil)
-	if azure.IgnoreNotFound(err) != nil {
-		logger.Error(err, "Failed to delete policy")
-		return ctrl.Result{}, err
+	if getErr == nil {
+		azurePolicy, err := r.apimClient.GetApiPolicy(ctx, getApiVersionName(apiVersion), nil)
+		if err == nil {
+			_, err = r.apimClient.DeleteApiPolicy(ctx, getApiVersionName(apiVersion), stringValue(azurePolicy.ETag), nil)
+		}
+		if azure.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to delete policy")
+			r.Recorder.Event(&apiVersion, corev1.EventTypeWarning, ReasonDeleteFailed, eventMessage("Failed to delete policy", err))
+			return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
+		}
+		_, err = r.apimClient.DeleteApi(ctx, getApiVersionName(apiVersion), stringValue(azureApi.ETag), nil)
+		if azure.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to delete APIVersion")
+			r.Recorder.Event(&apiVersion, corev1.EventTypeWarning, ReasonDeleteFailed, eventMessage("Failed to delete API", err))
+			return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
+		}
 	}
+	r.Recorder.Event(&apiVersion, corev1.EventTypeNormal, ReasonDeleted, "Deleted API "+getApiVersionName(apiVersion))
 	controllerutil.RemoveFinalizer(&apiVersion, "apiversion.finalizers.stilas.418.cloud")
-	err = r.Update(ctx, &apiVersion)
-	if err != nil {
+	if err := r.Update(ctx, &apiVersion); err != nil {
 		logger.Error(err, "Failed to remove finalizer")
 		return ctrl.Result{}, err
 	}
@@ -18,67 +18,1259 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
 
+	azruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+	"github.com/tjololo/stilas-az/internal/azure/apimfake"
+	"github.com/tjololo/stilas-az/internal/azure/mock"
+	"github.com/tjololo/stilas-az/internal/utils"
 )
 
 var _ = Describe("ApiVersion Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
+		const azureName = "default-" + resourceName
+		const content = `{"openapi":"3.0.1"}`
+		const wsdl = `<definitions xmlns="http://schemas.xmlsoap.org/wsdl/"/>`
+		const metadata = `<edmx:Edmx Version="4.0" xmlns:edmx="http://docs.oasis-open.org/odata/ns/edmx"/>`
 
 		ctx := context.Background()
 
 		typeNamespacedName := types.NamespacedName{
 			Name:      resourceName,
-			Namespace: "default", // TODO(user):Modify as needed
+			Namespace: "default",
 		}
-		apiversion := &apimv1alpha1.ApiVersion{}
+		var (
+			k8s        client.Client
+			apimClient *mock.MockClient
+			reconciler *ApiVersionReconciler
+			// fixture is the ApiVersion stored before each spec, each Context shapes it for the feature it covers
+			fixture *apimv1alpha1.ApiVersion
+			// objects are stored next to the fixture
+			objects []client.Object
+		)
 
 		BeforeEach(func() {
-			By("creating the custom resource for the Kind ApiVersion")
-			err := k8sClient.Get(ctx, typeNamespacedName, apiversion)
-			if err != nil && errors.IsNotFound(err) {
-				resource := &apimv1alpha1.ApiVersion{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      resourceName,
-						Namespace: "default",
+			fixture = &apimv1alpha1.ApiVersion{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       resourceName,
+					Namespace:  "default",
+					Generation: 1,
+					UID:        "apiversion-uid",
+					Finalizers: []string{"apiversion.finalizers.stilas.418.cloud"},
+				},
+				Spec: apimv1alpha1.ApiVersionSpec{
+					ApiVersionSetId: "/apiVersionSets/default-test",
+					Path:            "test",
+					APIType:         toPointer(apimv1alpha1.APITypeHTTP),
+					ApiVersionSubSpec: apimv1alpha1.ApiVersionSubSpec{
+						Name:          toPointer("v1"),
+						DisplayName:   "Test API v1",
+						ContentFormat: toPointer(apimv1alpha1.ContentFormatOpenapi),
+						Content:       toPointer(content),
 					},
-					// TODO(user): Specify other spec details if needed.
-				}
-				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+				},
+			}
+			objects = nil
+		})
+
+		JustBeforeEach(func() {
+			setAzureEnv()
+			By("creating the custom resource for the Kind ApiVersion")
+			k8s = newFakeClient(append([]client.Object{fixture}, objects...)...)
+			apimClient = mock.NewMockClient(gomock.NewController(GinkgoT()))
+			reconciler = &ApiVersionReconciler{
+				Client:    k8s,
+				Scheme:    k8s.Scheme(),
+				NewClient: newClientFor(apimClient),
+				Recorder:  record.NewFakeRecorder(10),
+			}
+		})
+
+		reconcileApiVersion := func() (reconcile.Result, error) {
+			return reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		}
+		getApiVersion := func() *apimv1alpha1.ApiVersion {
+			apiVersion := &apimv1alpha1.ApiVersion{}
+			Expect(k8s.Get(ctx, typeNamespacedName, apiVersion)).To(Succeed())
+			return apiVersion
+		}
+		existingApi := func() apim.APIClientGetResponse {
+			return apim.APIClientGetResponse{
+				APIContract: apim.APIContract{
+					ID:         toPointer("/apis/" + azureName),
+					Properties: &apim.APIContractProperties{Path: toPointer("test"), APIRevision: toPointer("1")},
+				},
+				ETag: toPointer(`"1"`),
 			}
+		}
+
+		Context("when the API does not exist in Azure", func() {
+			It("should import the API and create a release", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), &apim.APIClientBeginCreateOrUpdateOptions{}).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.Value).To(Equal(content))
+						Expect(*params.Properties.APIVersionSetID).To(Equal("/apiVersionSets/default-test"))
+						return succeededApiPoller(azureName), nil
+					})
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				result, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", lroPollInterval))
+
+				apiVersion := getApiVersion()
+				Expect(apiVersion.Status.ProvisioningState).To(Equal("Succeeded"))
+				Expect(apiVersion.Status.LastAppliedSpecSha).NotTo(BeEmpty())
+				Expect(apiVersion.Status.ResumeToken).To(BeEmpty())
+			})
+
+			It("should store the resume token while the import is in progress", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					Return(inProgressApiPoller(), nil)
+
+				result, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(lroPollInterval))
+
+				apiVersion := getApiVersion()
+				Expect(apiVersion.Status.ProvisioningState).To(Equal("Provisioning"))
+				Expect(apiVersion.Status.ResumeToken).NotTo(BeEmpty())
+				Expect(apiVersion.Status.LastAppliedSpecSha).To(BeEmpty())
+			})
+
+			It("should record the error when the import fails", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					Return(failedApiPoller("ValidationError", "Parsing error(s): JSON is not valid"), nil)
+
+				result, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(reconcile.Result{}))
+
+				apiVersion := getApiVersion()
+				Expect(apiVersion.Status.ProvisioningState).To(Equal("Failed"))
+				Expect(apiVersion.Status.ResumeToken).To(BeEmpty())
+				Expect(apiVersion.Status.LastOperationError).NotTo(BeNil())
+				Expect(apiVersion.Status.LastOperationError.Code).To(Equal("ValidationError"))
+			})
+
+			It("should stop retrying when Azure rejects the import", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					Return(nil, responseError(http.StatusBadRequest, "ValidationError"))
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				apiVersion := getApiVersion()
+				Expect(apiVersion.Status.ErrorClass).To(Equal(string(azure.ErrorClassPermanent)))
+				Expect(apiVersion.Status.FailedGeneration).To(Equal(apiVersion.Generation))
+			})
+		})
+
+		Context("when an import is in progress", func() {
+			BeforeEach(func() {
+				fixture.Status.ResumeToken = "resume-token"
+				fixture.Status.ProvisioningState = "Provisioning"
+			})
+
+			It("should resume the import when the spec is unchanged", func() {
+				apiVersion := getApiVersion()
+				sha, err := contentSha(ctx, content, apiVersion.Spec)
+				Expect(err).NotTo(HaveOccurred())
+				apiVersion.Status.ResumeSpecSha = sha
+				Expect(k8s.Status().Update(ctx, apiVersion)).To(Succeed())
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), &apim.APIClientBeginCreateOrUpdateOptions{ResumeToken: "resume-token"}).
+					Return(succeededApiPoller(azureName), nil)
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				_, err = reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+
+				apiVersion = getApiVersion()
+				Expect(apiVersion.Status.LastAppliedSpecSha).To(Equal(sha))
+				Expect(apiVersion.Status.ResumeToken).To(BeEmpty())
+				Expect(apiVersion.Status.ResumeSpecSha).To(BeEmpty())
+			})
+
+			It("should start a new import when the spec changed", func() {
+				apiVersion := getApiVersion()
+				apiVersion.Status.ResumeSpecSha = "previous-spec"
+				Expect(k8s.Status().Update(ctx, apiVersion)).To(Succeed())
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), &apim.APIClientBeginCreateOrUpdateOptions{}).
+					Return(inProgressApiPoller(), nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+
+				apiVersion = getApiVersion()
+				sha, err := contentSha(ctx, content, apiVersion.Spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(apiVersion.Status.ResumeToken).NotTo(Equal("resume-token"))
+				Expect(apiVersion.Status.ResumeSpecSha).To(Equal(sha))
+				Expect(apiVersion.Status.LastAppliedSpecSha).To(BeEmpty())
+			})
 		})
 
-		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
-			resource := &apimv1alpha1.ApiVersion{}
-			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+		It("should apply the API again when the versioning scheme changes", func() {
+			apiVersion := getApiVersion()
+			sha, err := contentSha(ctx, content, apiVersion.Spec)
 			Expect(err).NotTo(HaveOccurred())
+			apiVersion.Spec.ApiVersionScheme = apimv1alpha1.APIVersionSetContractDetailsVersioningSchemeHeader
+			changed, err := contentSha(ctx, content, apiVersion.Spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).NotTo(Equal(sha))
+		})
+
+		Context("when changes are staged as revisions", func() {
+			var revision *apimv1alpha1.ApiRevisionSpec
+
+			BeforeEach(func() {
+				revision = &apimv1alpha1.ApiRevisionSpec{Enabled: true, Promotion: apimv1alpha1.RevisionPromotionManual}
+				sha, err := utils.Sha256FromContent(ctx, content)
+				Expect(err).NotTo(HaveOccurred())
+				fixture.Status.LastAppliedSpecSha = sha
+				fixture.Status.ProvisioningState = "Succeeded"
+				fixture.Status.CurrentRevision = "1"
+				fixture.Status.PendingRevision = "2"
+			})
+
+			// stageRevisions enables revisions on the ApiVersion and applies the annotations
+			stageRevisions := func(annotations map[string]string) {
+				apiVersion := getApiVersion()
+				apiVersion.Spec.Revision = revision
+				apiVersion.Annotations = annotations
+				Expect(k8s.Update(ctx, apiVersion)).To(Succeed())
+			}
+			expectRelease := func(revision string) {
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					DoAndReturn(func(_ context.Context, _ string, _ string, release apim.APIReleaseContract, _ *apim.APIReleaseClientCreateOrUpdateOptions) (apim.APIReleaseClientCreateOrUpdateResponse, error) {
+						Expect(*release.Properties.APIID).To(Equal("/apis/" + azureName + ";rev=" + revision))
+						return apim.APIReleaseClientCreateOrUpdateResponse{}, nil
+					})
+			}
+
+			It("should stage changed content in the next revision", func() {
+				apiVersion := getApiVersion()
+				apiVersion.Status.LastAppliedSpecSha = "previous-spec"
+				apiVersion.Status.PendingRevision = ""
+				Expect(k8s.Status().Update(ctx, apiVersion)).To(Succeed())
+				stageRevisions(nil)
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName+";rev=2", gomock.Any(), &apim.APIClientBeginCreateOrUpdateOptions{}).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.SourceAPIID).To(Equal("/apis/" + azureName))
+						return succeededApiPoller(azureName + ";rev=2"), nil
+					})
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.PendingRevision).To(Equal("2"))
+			})
+
+			It("should keep the revision pending until it is approved", func() {
+				stageRevisions(nil)
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.PendingRevision).To(Equal("2"))
+				Expect(getApiVersion().Status.CurrentRevision).To(Equal("1"))
+			})
+
+			It("should promote a revision approved in the spec", func() {
+				revision.ApprovedRevision = toPointer("2")
+				stageRevisions(nil)
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				expectRelease("2")
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				apiVersion := getApiVersion()
+				Expect(apiVersion.Status.CurrentRevision).To(Equal("2"))
+				Expect(apiVersion.Status.PreviousRevision).To(Equal("1"))
+				Expect(apiVersion.Status.PendingRevision).To(BeEmpty())
+			})
+
+			It("should promote a revision approved with the annotation", func() {
+				stageRevisions(map[string]string{apimv1alpha1.ApproveRevisionAnnotation: "2"})
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				expectRelease("2")
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.CurrentRevision).To(Equal("2"))
+			})
+
+			It("should promote a revision automatically when the health check succeeds", func() {
+				var checked string
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					checked = r.URL.Path
+				}))
+				defer server.Close()
+				revision.Promotion = apimv1alpha1.RevisionPromotionAutomatic
+				revision.HealthCheckUrl = toPointer(server.URL + "/health/{revision}")
+				stageRevisions(nil)
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				expectRelease("2")
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(checked).To(Equal("/health/2"))
+				Expect(getApiVersion().Status.CurrentRevision).To(Equal("2"))
+			})
 
-			By("Cleanup the specific resource instance ApiVersion")
-			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			It("should not promote a revision automatically when the health check fails", func() {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}))
+				defer server.Close()
+				revision.Promotion = apimv1alpha1.RevisionPromotionAutomatic
+				revision.HealthCheckUrl = toPointer(server.URL + "/health/{revision}")
+				stageRevisions(nil)
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.PendingRevision).To(Equal("2"))
+			})
+
+			It("should not follow redirects of the health check", func() {
+				var redirected bool
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/internal" {
+						redirected = true
+						return
+					}
+					http.Redirect(w, r, "/internal", http.StatusFound)
+				}))
+				defer server.Close()
+				revision.Promotion = apimv1alpha1.RevisionPromotionAutomatic
+				revision.HealthCheckUrl = toPointer(server.URL + "/health/{revision}")
+				stageRevisions(nil)
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(redirected).To(BeFalse())
+				Expect(getApiVersion().Status.PendingRevision).To(Equal("2"))
+			})
+
+			It("should not promote a revision with a health check that is not http or https", func() {
+				revision.Promotion = apimv1alpha1.RevisionPromotionAutomatic
+				revision.HealthCheckUrl = toPointer("file:///etc/passwd")
+				stageRevisions(nil)
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.PendingRevision).To(Equal("2"))
+			})
+
+			It("should roll back to the previous revision and remove the annotation", func() {
+				apiVersion := getApiVersion()
+				apiVersion.Status.CurrentRevision = "2"
+				apiVersion.Status.PreviousRevision = "1"
+				apiVersion.Status.PendingRevision = ""
+				Expect(k8s.Status().Update(ctx, apiVersion)).To(Succeed())
+				stageRevisions(map[string]string{apimv1alpha1.RollbackRevisionAnnotation: "true"})
+				current := existingApi()
+				current.Properties.APIRevision = toPointer("2")
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(current, nil)
+				expectRelease("1")
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				apiVersion = getApiVersion()
+				Expect(apiVersion.Status.CurrentRevision).To(Equal("1"))
+				Expect(apiVersion.Status.PreviousRevision).To(Equal("2"))
+				Expect(apiVersion.Annotations).NotTo(HaveKey(apimv1alpha1.RollbackRevisionAnnotation))
+			})
+
+			It("should pick the next revision after a non-numeric revision", func() {
+				Expect(nextRevision(apimv1alpha1.ApiVersion{}, "1")).To(Equal("2"))
+				Expect(nextRevision(apimv1alpha1.ApiVersion{}, "current")).To(Equal("2"))
+				Expect(nextRevision(apimv1alpha1.ApiVersion{}, "")).To(Equal("2"))
+				pending := apimv1alpha1.ApiVersion{Status: apimv1alpha1.ApiVersionStatus{PendingRevision: "5"}}
+				Expect(nextRevision(pending, "3")).To(Equal("5"))
+			})
 		})
-		It("should successfully reconcile the resource", func() {
-			By("Reconciling the created resource")
-			controllerReconciler := &ApiVersionReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+
+		Context("when the ApiVersion is standalone", func() {
+			BeforeEach(func() {
+				fixture.Spec.ApiVersionSetId = ""
+				fixture.Spec.Name = nil
+			})
+
+			It("should import the API without a version set", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), &apim.APIClientBeginCreateOrUpdateOptions{}).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(params.Properties.APIVersionSetID).To(BeNil())
+						Expect(params.Properties.APIVersion).To(BeNil())
+						Expect(*params.Properties.Path).To(Equal("test"))
+						return succeededApiPoller(azureName), nil
+					})
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.ProvisioningState).To(Equal("Succeeded"))
+			})
+		})
+
+		Context("when the ApiVersion is deprecated", func() {
+			var deprecatedAt, sunsetAt time.Time
+
+			BeforeEach(func() {
+				deprecatedAt = time.Now().Add(-time.Hour).Truncate(time.Second)
+				sunsetAt = deprecatedAt.Add(30 * 24 * time.Hour)
+				fixture.Spec.ApiVersionScheme = apimv1alpha1.APIVersionSetContractDetailsVersioningSchemeSegment
+				fixture.Spec.Deprecation = &apimv1alpha1.ApiDeprecationSpec{
+					DeprecatedAt:     metav1.NewTime(deprecatedAt),
+					SunsetAt:         toPointer(metav1.NewTime(sunsetAt)),
+					SuccessorVersion: toPointer("v2"),
+					AfterSunset:      apimv1alpha1.SunsetActionKeep,
+				}
+			})
+			markApplied := func() {
+				sha, err := contentSha(ctx, content, fixture.Spec)
+				Expect(err).NotTo(HaveOccurred())
+				setAppliedSpec(&fixture.Status, sha)
+				fixture.Status.ProvisioningState = "Succeeded"
 			}
 
-			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
-				NamespacedName: typeNamespacedName,
+			It("should mark the version description as deprecated", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.APIVersionDescription).To(HavePrefix("Deprecated as of " + deprecatedAt.UTC().Format(time.DateOnly) + "."))
+						Expect(*params.Properties.APIVersionDescription).To(ContainSubstring("Use version v2 instead."))
+						return succeededApiPoller(azureName), nil
+					})
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("when the content is already applied", func() {
+				BeforeEach(markApplied)
+
+				It("should add the deprecation headers with a policy", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().GetApiPolicy(gomock.Any(), azureName, nil).
+						Return(apim.APIPolicyClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+					apimClient.EXPECT().CreateUpdateApiPolicy(gomock.Any(), azureName, gomock.Any(), nil).
+						DoAndReturn(func(_ context.Context, _ string, policy apim.PolicyContract, _ *apim.APIPolicyClientCreateOrUpdateOptions) (apim.APIPolicyClientCreateOrUpdateResponse, error) {
+							Expect(*policy.Properties.Format).To(Equal(apim.PolicyContentFormatXML))
+							Expect(*policy.Properties.Value).To(ContainSubstring(fmt.Sprintf(`<set-header name="Deprecation" exists-action="override"><value>@(&#34;@%d&#34;)</value></set-header>`, deprecatedAt.Unix())))
+							Expect(*policy.Properties.Value).To(ContainSubstring(`<set-header name="Sunset" exists-action="override"><value>` + sunsetAt.UTC().Format(http.TimeFormat) + `</value></set-header>`))
+							Expect(*policy.Properties.Value).To(ContainSubstring(`<value>&lt;/test/v2&gt;; rel=&#34;successor-version&#34;</value>`))
+							Expect(*policy.Properties.Value).NotTo(ContainSubstring("return-response"))
+							return apim.APIPolicyClientCreateOrUpdateResponse{ETag: toPointer(`"p1"`)}, nil
+						})
+
+					result, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.RequeueAfter).To(BeNumerically("<=", 16*24*time.Hour+time.Second))
+
+					apiVersion := getApiVersion()
+					Expect(apiVersion.Status.DeprecationStage).To(Equal(apimv1alpha1.DeprecationStageDeprecated))
+					Expect(apiVersion.Status.LastAppliedPolicySha).NotTo(BeEmpty())
+					events := reconciler.Recorder.(*record.FakeRecorder).Events
+					Expect(events).To(Receive(ContainSubstring(ReasonPolicyApplied)))
+					Expect(events).To(Receive(ContainSubstring(ReasonDeprecated)))
+
+					By("Deleting the policy when the version is no longer deprecated")
+					apiVersion.Spec.Deprecation = nil
+					Expect(k8s.Update(ctx, apiVersion)).To(Succeed())
+					sha, err := contentSha(ctx, content, apiVersion.Spec)
+					Expect(err).NotTo(HaveOccurred())
+					setAppliedSpec(&apiVersion.Status, sha)
+					Expect(k8s.Status().Update(ctx, apiVersion)).To(Succeed())
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().GetApiPolicy(gomock.Any(), azureName, nil).
+						Return(apim.APIPolicyClientGetResponse{ETag: toPointer(`"p1"`)}, nil)
+					apimClient.EXPECT().DeleteApiPolicy(gomock.Any(), azureName, `"p1"`, nil).
+						Return(apim.APIPolicyClientDeleteResponse{}, nil)
+
+					_, err = reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					apiVersion = getApiVersion()
+					Expect(apiVersion.Status.LastAppliedPolicySha).To(BeEmpty())
+					Expect(apiVersion.Status.DeprecationStage).To(BeEmpty())
+				})
+			})
+
+			Context("when the sunset has passed and the version is gone", func() {
+				BeforeEach(func() {
+					fixture.Spec.Deprecation.DeprecatedAt = metav1.NewTime(deprecatedAt.Add(-60 * 24 * time.Hour))
+					fixture.Spec.Deprecation.SunsetAt = toPointer(metav1.NewTime(deprecatedAt))
+					fixture.Spec.Deprecation.AfterSunset = apimv1alpha1.SunsetActionGone
+					markApplied()
+				})
+
+				It("should respond with 410 Gone", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().GetApiPolicy(gomock.Any(), azureName, nil).
+						Return(apim.APIPolicyClientGetResponse{ETag: toPointer(`"p1"`)}, nil)
+					apimClient.EXPECT().CreateUpdateApiPolicy(gomock.Any(), azureName, gomock.Any(), &apim.APIPolicyClientCreateOrUpdateOptions{IfMatch: toPointer(`"p1"`)}).
+						DoAndReturn(func(_ context.Context, _ string, policy apim.PolicyContract, _ *apim.APIPolicyClientCreateOrUpdateOptions) (apim.APIPolicyClientCreateOrUpdateResponse, error) {
+							Expect(*policy.Properties.Value).To(ContainSubstring(`<inbound><return-response><set-status code="410" reason="Gone" /><set-header name="Deprecation"`))
+							return apim.APIPolicyClientCreateOrUpdateResponse{ETag: toPointer(`"p2"`)}, nil
+						})
+
+					_, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(getApiVersion().Status.DeprecationStage).To(Equal(apimv1alpha1.DeprecationStageSunset))
+				})
+			})
+
+			Context("when the sunset has passed and the version is removed", func() {
+				BeforeEach(func() {
+					fixture.Spec.Deprecation.DeprecatedAt = metav1.NewTime(deprecatedAt.Add(-60 * 24 * time.Hour))
+					fixture.Spec.Deprecation.SunsetAt = toPointer(metav1.NewTime(deprecatedAt))
+					fixture.Spec.Deprecation.AfterSunset = apimv1alpha1.SunsetActionRemove
+					markApplied()
+				})
+
+				It("should delete the API in Azure and keep the ApiVersion", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().DeleteApi(gomock.Any(), azureName, `"1"`, nil).Return(apim.APIClientDeleteResponse{}, nil)
+
+					result, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(reconcile.Result{}))
+
+					apiVersion := getApiVersion()
+					Expect(apiVersion.Status.ProvisioningState).To(Equal("Sunset"))
+					Expect(apiVersion.Status.DeprecationStage).To(Equal(apimv1alpha1.DeprecationStageSunset))
+					Expect(apiVersion.Status.LastAppliedSpecSha).To(BeEmpty())
+					Expect(apiVersion.Finalizers).NotTo(BeEmpty())
+
+					By("Not creating the API again")
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+						Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+					_, err = reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when the API exists in Azure", func() {
+			It("should update the API with If-Match when the content changed", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), &apim.APIClientBeginCreateOrUpdateOptions{IfMatch: toPointer(`"1"`)}).
+					Return(succeededApiPoller(azureName), nil)
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.ProvisioningState).To(Equal("Succeeded"))
+			})
+		})
+
+		Context("when the OpenAPI document has breaking changes", func() {
+			const appliedOpenAPI = `{"openapi":"3.0.1","paths":{"/orders":{"get":{}}}}`
+
+			BeforeEach(func() {
+				objects = append(objects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            resourceName + "-applied-openapi",
+						Namespace:       "default",
+						OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(fixture, apimv1alpha1.GroupVersion.WithKind("ApiVersion"))},
+					},
+					Data: map[string]string{"openapi": appliedOpenAPI},
+				})
+			})
+
+			Context("when breaking changes are allowed with a warning", func() {
+				BeforeEach(func() {
+					fixture.Spec.BreakingChangePolicy = toPointer(apimv1alpha1.BreakingChangePolicyWarn)
+				})
+
+				It("should import the API and report the breaking changes", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+						Return(succeededApiPoller(azureName), nil)
+					apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+						Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+					_, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(<-reconciler.Recorder.(*record.FakeRecorder).Events).To(ContainSubstring(ReasonBreakingChanges))
+
+					apiVersion := getApiVersion()
+					Expect(apiVersion.Status.ProvisioningState).To(Equal("Succeeded"))
+					Expect(apiVersion.Status.BreakingChanges).To(Equal([]apimv1alpha1.BreakingChangeStatus{
+						{Type: utils.BreakingChangeRemovedPath, Location: "/orders", Message: "path /orders was removed"},
+					}))
+
+					By("storing the imported document for the next import")
+					configMap := &corev1.ConfigMap{}
+					Expect(k8s.Get(ctx, types.NamespacedName{Name: resourceName + "-applied-openapi", Namespace: "default"}, configMap)).To(Succeed())
+					Expect(configMap.Data["openapi"]).To(Equal(content))
+					Expect(configMap.OwnerReferences).To(HaveLen(1))
+				})
+			})
+
+			Context("when breaking changes are blocked", func() {
+				BeforeEach(func() {
+					fixture.Spec.BreakingChangePolicy = toPointer(apimv1alpha1.BreakingChangePolicyBlock)
+				})
+
+				It("should not import the API", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+					result, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(reconcile.Result{}))
+					Expect(<-reconciler.Recorder.(*record.FakeRecorder).Events).To(ContainSubstring(ReasonImportBlocked))
+
+					apiVersion := getApiVersion()
+					Expect(apiVersion.Status.ProvisioningState).To(Equal("Blocked"))
+					Expect(apiVersion.Status.ErrorMessage).To(ContainSubstring("/orders"))
+					Expect(apiVersion.Status.BreakingChanges).To(HaveLen(1))
+					Expect(apiVersion.Status.LastAppliedSpecSha).To(BeEmpty())
+				})
+
+				It("should download a linked document once", func() {
+					var downloads int
+					server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						downloads++
+						_, _ = w.Write([]byte(`{"openapi":"3.0.1","paths":{"/orders":{"get":{}},"/invoices":{"get":{}}}}`))
+					}))
+					defer server.Close()
+					apiVersion := getApiVersion()
+					apiVersion.Spec.ContentFormat = toPointer(apimv1alpha1.ContentFormatOpenapiJSONLink)
+					apiVersion.Spec.Content = toPointer(server.URL)
+					Expect(k8s.Update(ctx, apiVersion)).To(Succeed())
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+							Expect(*params.Properties.Value).To(Equal(server.URL))
+							return succeededApiPoller(azureName), nil
+						})
+					apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+						Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+					_, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(downloads).To(Equal(1))
+
+					configMap := &corev1.ConfigMap{}
+					Expect(k8s.Get(ctx, types.NamespacedName{Name: resourceName + "-applied-openapi", Namespace: "default"}, configMap)).To(Succeed())
+					Expect(configMap.Data["openapi"]).To(ContainSubstring("/invoices"))
+				})
+
+				It("should not use a ConfigMap it does not control", func() {
+					configMap := &corev1.ConfigMap{}
+					Expect(k8s.Get(ctx, types.NamespacedName{Name: resourceName + "-applied-openapi", Namespace: "default"}, configMap)).To(Succeed())
+					configMap.OwnerReferences = nil
+					Expect(k8s.Update(ctx, configMap)).To(Succeed())
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+					_, err := reconcileApiVersion()
+					Expect(err).To(HaveOccurred())
+					Expect(getApiVersion().Status.ErrorMessage).To(ContainSubstring("not controlled"))
+
+					Expect(k8s.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)).To(Succeed())
+					Expect(configMap.Data["openapi"]).To(Equal(appliedOpenAPI))
+				})
+			})
+		})
+
+		Context("when the OpenAPI document is linted", func() {
+			BeforeEach(func() {
+				fixture.Spec.Lint = &apimv1alpha1.ApiLintSpec{RuleSets: []string{"team-rules"}}
+				objects = append(objects, &apimv1alpha1.LintRuleSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "team-rules", Namespace: "default"},
+					Spec: apimv1alpha1.LintRuleSetSpec{Rules: []apimv1alpha1.LintRule{{
+						Name:     "operationid-prefix",
+						Severity: apimv1alpha1.LintSeverityWarning,
+						Target:   "$.paths.*.*.operationId",
+						Pattern:  toPointer("^orders"),
+					}}},
+				})
+			})
+
+			Context("when the document has lint errors", func() {
+				BeforeEach(func() {
+					fixture.Spec.Content = toPointer(`{"openapi":"3.0.1","paths":{"/orders":{"get":{"summary":"List orders"}}}}`)
+				})
+
+				It("should not import the API", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+					result, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(reconcile.Result{}))
+					Expect(<-reconciler.Recorder.(*record.FakeRecorder).Events).To(ContainSubstring(ReasonImportBlocked))
+
+					apiVersion := getApiVersion()
+					Expect(apiVersion.Status.ProvisioningState).To(Equal("Blocked"))
+					Expect(apiVersion.Status.ErrorMessage).To(ContainSubstring(utils.LintRuleOperationId))
+					Expect(apiVersion.Status.LintFindings).To(Equal([]apimv1alpha1.LintFindingStatus{{
+						Rule:     utils.LintRuleOperationId,
+						Severity: apimv1alpha1.LintSeverityError,
+						Location: "$.paths['/orders'].get",
+						Message:  "GET /orders has no operationId",
+					}}))
+				})
+			})
+
+			Context("when the document has lint warnings", func() {
+				BeforeEach(func() {
+					fixture.Spec.Content = toPointer(`{"openapi":"3.0.1","paths":{"/orders":{"get":{"operationId":"listOrders"}}}}`)
+				})
+
+				It("should import the API and report the warnings in a condition", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+						Return(succeededApiPoller(azureName), nil)
+					apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+						Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+					_, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+
+					apiVersion := getApiVersion()
+					Expect(apiVersion.Status.ProvisioningState).To(Equal("Succeeded"))
+					Expect(apiVersion.Status.LintFindings).To(HaveLen(2))
+					Expect(apiVersion.Status.LintFindings[0].Rule).To(Equal(utils.LintRuleOperationDescription))
+					Expect(apiVersion.Status.LintFindings[1].Rule).To(Equal("operationid-prefix"))
+					condition := meta.FindStatusCondition(apiVersion.Status.Conditions, apimv1alpha1.ConditionTypeLintWarnings)
+					Expect(condition).NotTo(BeNil())
+					Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+					Expect(condition.Message).To(ContainSubstring("2 lint warnings"))
+				})
+			})
+		})
+
+		Context("when the ApiVersion is a synthetic GraphQL API", func() {
+			const sdl = "type Query { book(id: ID!): Book }\ntype Book { id: ID! }"
+
+			BeforeEach(func() {
+				fixture.Spec.APIType = toPointer(apimv1alpha1.APITypeGraphql)
+				fixture.Spec.Content = nil
+				fixture.Spec.GraphQLSchema = &apimv1alpha1.GraphQLSchemaSource{Sdl: toPointer(sdl)}
+			})
+
+			It("should create the API without content and upload the schema", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.APIType).To(Equal(apim.APITypeGraphql))
+						Expect(params.Properties.Format).To(BeNil())
+						Expect(params.Properties.Value).To(BeNil())
+						return succeededApiPoller(azureName), nil
+					})
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				result, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeTrue())
+				Expect(getApiVersion().Status.LastAppliedSchemaSha).To(BeEmpty())
+
+				By("Uploading the schema once the API exists")
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				apimClient.EXPECT().CreateUpdateApiSchema(gomock.Any(), azureName, "graphql", gomock.Any(), &apim.APISchemaClientBeginCreateOrUpdateOptions{}).
+					DoAndReturn(func(_ context.Context, _ string, _ string, params apim.SchemaContract, _ *apim.APISchemaClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APISchemaClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.ContentType).To(Equal("application/vnd.ms-azure-apim.graphql.schema"))
+						Expect(*params.Properties.Document.Value).To(Equal(sdl))
+						return succeededSchemaPoller(), nil
+					})
+				_, err = reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.LastAppliedSchemaSha).NotTo(BeEmpty())
+
+				By("Not uploading an unchanged schema again")
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				_, err = reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("when the API exists", func() {
+				BeforeEach(func() {
+					sha, err := contentSha(ctx, "", fixture.Spec)
+					Expect(err).NotTo(HaveOccurred())
+					setAppliedSpec(&fixture.Status, sha)
+				})
+
+				It("should resume a schema upload that is in progress", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil).Times(2)
+					apimClient.EXPECT().CreateUpdateApiSchema(gomock.Any(), azureName, "graphql", gomock.Any(), &apim.APISchemaClientBeginCreateOrUpdateOptions{}).
+						Return(inProgressSchemaPoller(), nil)
+
+					result, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.RequeueAfter).To(Equal(lroPollInterval))
+					apiVersion := getApiVersion()
+					Expect(apiVersion.Status.SchemaResumeToken).NotTo(BeEmpty())
+					Expect(apiVersion.Status.SchemaResumeSha).NotTo(BeEmpty())
+					Expect(apiVersion.Status.LastAppliedSchemaSha).To(BeEmpty())
+
+					By("Resuming the upload with the stored token")
+					apimClient.EXPECT().CreateUpdateApiSchema(gomock.Any(), azureName, "graphql", gomock.Any(), &apim.APISchemaClientBeginCreateOrUpdateOptions{ResumeToken: apiVersion.Status.SchemaResumeToken}).
+						Return(succeededSchemaPoller(), nil)
+					_, err = reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					apiVersion = getApiVersion()
+					Expect(apiVersion.Status.SchemaResumeToken).To(BeEmpty())
+					Expect(apiVersion.Status.SchemaResumeSha).To(BeEmpty())
+					Expect(apiVersion.Status.LastAppliedSchemaSha).NotTo(BeEmpty())
+				})
+			})
+
+			Context("when the schema is invalid", func() {
+				BeforeEach(func() {
+					fixture.Spec.GraphQLSchema = &apimv1alpha1.GraphQLSchemaSource{Sdl: toPointer("type Query { book: Book")}
+					sha, err := utils.Sha256FromContent(ctx, "")
+					Expect(err).NotTo(HaveOccurred())
+					setAppliedSpec(&fixture.Status, sha)
+				})
+
+				It("should not upload the schema", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+					_, err := reconcileApiVersion()
+					Expect(err).To(HaveOccurred())
+					Expect(getApiVersion().Status.ErrorClass).To(Equal(string(azure.ErrorClassTransient)))
+				})
+			})
+		})
+
+		Context("when the ApiVersion is imported from a WSDL document", func() {
+			BeforeEach(func() {
+				fixture.Spec.APIType = toPointer(apimv1alpha1.APITypeSoap)
+				fixture.Spec.ContentFormat = toPointer(apimv1alpha1.ContentFormatWsdl)
+				fixture.Spec.Content = toPointer(wsdl)
+				fixture.Spec.SoapMode = toPointer(apimv1alpha1.SoapModePassthrough)
+				fixture.Spec.WsdlSelector = &apimv1alpha1.WsdlSelector{ServiceName: toPointer("LegacyService"), EndpointName: toPointer("LegacySoap")}
+			})
+
+			It("should import the WSDL as a SOAP pass-through API", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.Format).To(Equal(apim.ContentFormatWsdl))
+						Expect(*params.Properties.Value).To(Equal(wsdl))
+						Expect(*params.Properties.SoapAPIType).To(Equal(apim.SoapAPITypeSoapPassThrough))
+						Expect(*params.Properties.WsdlSelector.WsdlServiceName).To(Equal("LegacyService"))
+						Expect(*params.Properties.WsdlSelector.WsdlEndpointName).To(Equal("LegacySoap"))
+						return succeededApiPoller(azureName), nil
+					})
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+
+				apiVersion := getApiVersion()
+				contentOnlySha, err := utils.Sha256FromContent(ctx, wsdl)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(apiVersion.Status.LastAppliedSpecSha).NotTo(BeEmpty())
+				Expect(apiVersion.Status.LastAppliedSpecSha).NotTo(Equal(contentOnlySha))
+
+				By("Importing the WSDL again when the SOAP mode changes")
+				apiVersion.Spec.SoapMode = toPointer(apimv1alpha1.SoapModeToRest)
+				Expect(k8s.Update(ctx, apiVersion)).To(Succeed())
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.SoapAPIType).To(Equal(apim.SoapAPITypeSoapToRest))
+						return succeededApiPoller(azureName), nil
+					})
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+				_, err = reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the ApiVersion is an OData API reading its metadata from a ConfigMap", func() {
+			BeforeEach(func() {
+				fixture.Spec.APIType = toPointer(apimv1alpha1.APITypeOdata)
+				fixture.Spec.ContentFormat = toPointer(apimv1alpha1.ContentFormatOdata)
+				fixture.Spec.Content = nil
+				fixture.Spec.ContentFrom = &apimv1alpha1.ContentSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "reporting-metadata"},
+					Key:                  "metadata.xml",
+				}}
+				objects = append(objects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "reporting-metadata", Namespace: "default"},
+					Data:       map[string]string{"metadata.xml": metadata},
+				})
+			})
+
+			It("should import the metadata document as an OData API", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.APIType).To(Equal(azure.APITypeOdata))
+						Expect(*params.Properties.SoapAPIType).To(Equal(azure.SoapAPITypeOdata))
+						Expect(string(*params.Properties.Format)).To(Equal("odata"))
+						Expect(*params.Properties.Value).To(Equal(metadata))
+						return succeededApiPoller(azureName), nil
+					})
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				sha, err := utils.Sha256FromContent(ctx, metadata)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.LastAppliedSpecSha).To(Equal(sha))
+			})
+
+			Context("when the ConfigMap does not exist", func() {
+				BeforeEach(func() {
+					fixture.Spec.ContentFrom.ConfigMapKeyRef.Name = "missing"
+				})
+
+				It("should not import the API", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+						Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+
+					_, err := reconcileApiVersion()
+					Expect(err).To(HaveOccurred())
+					Expect(getApiVersion().Status.ErrorClass).To(Equal(string(azure.ErrorClassTransient)))
+				})
+			})
+		})
+
+		Context("when the ApiVersion is a WebSocket API", func() {
+			const policy = "<policies><inbound><base /></inbound></policies>"
+
+			BeforeEach(func() {
+				fixture.Spec.APIType = toPointer(apimv1alpha1.APITypeWebsocket)
+				fixture.Spec.Content = nil
+				fixture.Spec.ContentFormat = nil
+				fixture.Spec.ServiceUrl = toPointer("wss://chat.example.com")
+				fixture.Spec.Protocols = []apimv1alpha1.Protocol{apimv1alpha1.ProtocolWss}
+				fixture.Spec.WebSocket = &apimv1alpha1.WebSocketSpec{
+					OnHandshakePolicy: &apimv1alpha1.ApiPolicySpec{PolicyContent: toPointer(policy)},
+				}
+			})
+
+			It("should create the API without content", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).
+					Return(apim.APIClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.APIType).To(Equal(apim.APITypeWebsocket))
+						Expect(*params.Properties.ServiceURL).To(Equal("wss://chat.example.com"))
+						Expect(params.Properties.Format).To(BeNil())
+						Expect(params.Properties.Value).To(BeNil())
+						return succeededApiPoller(azureName), nil
+					})
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.ProvisioningState).To(Equal("Succeeded"))
+			})
+
+			Context("when the API exists in Azure", func() {
+				BeforeEach(func() {
+					sha, err := utils.Sha256FromContent(ctx, "")
+					Expect(err).NotTo(HaveOccurred())
+					setAppliedSpec(&fixture.Status, sha)
+				})
+
+				It("should apply the onHandshake policy", func() {
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().GetApiOperationPolicy(gomock.Any(), azureName, "onHandshake", nil).
+						Return(apim.APIOperationPolicyClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+					apimClient.EXPECT().CreateUpdateApiOperationPolicy(gomock.Any(), azureName, "onHandshake", gomock.Any(), nil).
+						DoAndReturn(func(_ context.Context, _ string, _ string, params apim.PolicyContract, _ *apim.APIOperationPolicyClientCreateOrUpdateOptions) (apim.APIOperationPolicyClientCreateOrUpdateResponse, error) {
+							Expect(*params.Properties.Value).To(Equal(policy))
+							return apim.APIOperationPolicyClientCreateOrUpdateResponse{}, nil
+						})
+
+					_, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(getApiVersion().Status.LastAppliedOnHandshakePolicySha).NotTo(BeEmpty())
+
+					By("Not applying an unchanged policy again")
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().GetApiOperationPolicy(gomock.Any(), azureName, "onHandshake", nil).
+						Return(apim.APIOperationPolicyClientGetResponse{ETag: toPointer(`"p1"`)}, nil)
+					_, err = reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should delete the onHandshake policy when it is removed from the spec", func() {
+					apiVersion := getApiVersion()
+					apiVersion.Status.LastAppliedOnHandshakePolicySha = "applied"
+					Expect(k8s.Status().Update(ctx, apiVersion)).To(Succeed())
+					apiVersion.Spec.WebSocket.OnHandshakePolicy = nil
+					Expect(k8s.Update(ctx, apiVersion)).To(Succeed())
+					apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+					apimClient.EXPECT().GetApiOperationPolicy(gomock.Any(), azureName, "onHandshake", nil).
+						Return(apim.APIOperationPolicyClientGetResponse{ETag: toPointer(`"p1"`)}, nil)
+					apimClient.EXPECT().DeleteApiOperationPolicy(gomock.Any(), azureName, "onHandshake", `"p1"`, nil).
+						Return(apim.APIOperationPolicyClientDeleteResponse{}, nil)
+
+					_, err := reconcileApiVersion()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(<-reconciler.Recorder.(*record.FakeRecorder).Events).To(ContainSubstring(ReasonPolicyDeleted))
+					Expect(getApiVersion().Status.LastAppliedOnHandshakePolicySha).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("with the fake APIM server", func() {
+			var server *apimfake.Server
+
+			BeforeEach(func() {
+				server = apimfake.NewServer()
+				DeferCleanup(server.Close)
+			})
+
+			JustBeforeEach(func() {
+				reconciler.NewClient = server.NewClient
+			})
+
+			It("should import the API through a long-running operation and release it", func() {
+				server.LROPolls = 1
+
+				result, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(lroPollInterval))
+				apiVersion := getApiVersion()
+				Expect(apiVersion.Status.ProvisioningState).To(Equal("Provisioning"))
+				Expect(apiVersion.Status.ResumeToken).NotTo(BeEmpty())
+				_, found := server.Resource("apis/" + azureName)
+				Expect(found).To(BeFalse())
+
+				By("Resuming the import until it completes")
+				_, err = reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				apiVersion = getApiVersion()
+				Expect(apiVersion.Status.ProvisioningState).To(Equal("Succeeded"))
+				Expect(apiVersion.Status.ResumeToken).To(BeEmpty())
+				api, found := server.Resource("apis/" + azureName)
+				Expect(found).To(BeTrue())
+				Expect(api["properties"]).To(HaveKeyWithValue("path", "test"))
+				Expect(api["properties"]).To(HaveKeyWithValue("apiVersionSetId", "/apiVersionSets/default-test"))
+				Expect(api["properties"]).To(HaveKeyWithValue("isCurrent", true))
+				Expect(server.Requests()).To(ContainElement(HavePrefix("PUT apis/" + azureName + "/releases/release-")))
+			})
+
+			It("should retry the import when Azure does not find the API it writes", func() {
+				server.AddFault(apimfake.Fault{Method: http.MethodPut, Path: "apis/" + azureName, StatusCode: http.StatusNotFound, Code: "ResourceNotFound", Times: 1})
+
+				_, err := reconcileApiVersion()
+				Expect(err).To(HaveOccurred())
+				Expect(server.Requests()).To(Equal([]string{"GET apis/" + azureName, "PUT apis/" + azureName}))
+				Expect(getApiVersion().Status.ErrorClass).To(Equal(string(azure.ErrorClassTransient)))
+
+				_, err = reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.ProvisioningState).To(Equal("Succeeded"))
+			})
+
+			It("should requeue after the delay requested by Azure when it throttles the import", func() {
+				server.AddFault(apimfake.Fault{Method: http.MethodPut, Path: "apis/" + azureName, StatusCode: http.StatusTooManyRequests, RetryAfter: 10 * time.Second, Times: 1})
+
+				result, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+				apiVersion := getApiVersion()
+				Expect(apiVersion.Status.ErrorClass).To(Equal(string(azure.ErrorClassThrottled)))
+				Expect(apiVersion.Status.LastAppliedSpecSha).To(BeEmpty())
+
+				_, err = reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				apiVersion = getApiVersion()
+				Expect(apiVersion.Status.ErrorClass).To(BeEmpty())
+				Expect(apiVersion.Status.LastAppliedSpecSha).NotTo(BeEmpty())
+			})
+
+			It("should requeue when the API was modified concurrently", func() {
+				server.AddFault(apimfake.Fault{Method: http.MethodPut, Path: "apis/" + azureName, StatusCode: http.StatusConflict, Code: "Conflict", Times: 1})
+
+				result, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeTrue())
+				Expect(getApiVersion().Status.ConflictCount).To(Equal(int32(1)))
+
+				_, err = reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.ConflictCount).To(BeZero())
+			})
+		})
+
+		Context("when the content is already applied", func() {
+			BeforeEach(func() {
+				sha, err := utils.Sha256FromContent(ctx, content)
+				Expect(err).NotTo(HaveOccurred())
+				setAppliedSpec(&fixture.Status, sha)
+				fixture.Status.ProvisioningState = "Succeeded"
+			})
+
+			It("should not import the API again", func() {
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.ETag).To(Equal(`"1"`))
+			})
+
+			It("should retry a release that failed after the import", func() {
+				apiVersion := getApiVersion()
+				apiVersion.Status.LastReleaseId = ""
+				Expect(k8s.Status().Update(ctx, apiVersion)).To(Succeed())
+				releaseId := "release-" + apiVersion.Status.LastAppliedSpecSha[:12]
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, releaseId, gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, responseError(http.StatusInternalServerError, "InternalServerError"))
+
+				_, err := reconcileApiVersion()
+				Expect(err).To(HaveOccurred())
+				Expect(getApiVersion().Status.LastReleaseId).To(BeEmpty())
+
+				By("Creating the release on the next reconcile")
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, releaseId, gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				_, err = reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.LastReleaseId).To(Equal(releaseId))
+			})
+
+			It("should update the API when properties outside the content change", func() {
+				apiVersion := getApiVersion()
+				apiVersion.Spec.License = &apimv1alpha1.APILicenseInformation{Name: toPointer("MIT"), URL: toPointer("https://opensource.org/licenses/MIT")}
+				apiVersion.Spec.TermsOfServiceUrl = toPointer("https://example.com/terms")
+				apiVersion.Spec.AuthenticationSettings = &apimv1alpha1.AuthenticationSettings{
+					OpenId: &apimv1alpha1.OpenIdAuthenticationSettings{
+						OpenIdProviderId:          "entra",
+						BearerTokenSendingMethods: []apimv1alpha1.BearerTokenSendingMethod{apimv1alpha1.BearerTokenSendingMethodAuthorizationHeader},
+					},
+				}
+				apiVersion.Spec.SubscriptionKeyParameterNames = &apimv1alpha1.SubscriptionKeyParameterNames{Header: "X-Api-Key", Query: "api-key"}
+				apiVersion.Spec.VersionDescription = toPointer("First version")
+				Expect(k8s.Update(ctx, apiVersion)).To(Succeed())
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				apimClient.EXPECT().CreateUpdateApi(gomock.Any(), azureName, gomock.Any(), &apim.APIClientBeginCreateOrUpdateOptions{IfMatch: toPointer(`"1"`)}).
+					DoAndReturn(func(_ context.Context, _ string, params apim.APICreateOrUpdateParameter, _ *apim.APIClientBeginCreateOrUpdateOptions) (*azruntime.Poller[apim.APIClientCreateOrUpdateResponse], error) {
+						Expect(*params.Properties.License.Name).To(Equal("MIT"))
+						Expect(*params.Properties.TermsOfServiceURL).To(Equal("https://example.com/terms"))
+						Expect(*params.Properties.AuthenticationSettings.Openid.OpenidProviderID).To(Equal("entra"))
+						Expect(*params.Properties.AuthenticationSettings.Openid.BearerTokenSendingMethods[0]).To(Equal(apim.BearerTokenSendingMethodsAuthorizationHeader))
+						Expect(*params.Properties.SubscriptionKeyParameterNames.Header).To(Equal("X-Api-Key"))
+						Expect(*params.Properties.SubscriptionKeyParameterNames.Query).To(Equal("api-key"))
+						Expect(*params.Properties.APIVersionDescription).To(Equal("First version"))
+						return succeededApiPoller(azureName), nil
+					})
+				apimClient.EXPECT().CreateUpdateApiRelease(gomock.Any(), azureName, gomock.Any(), gomock.Any(), nil).
+					Return(apim.APIReleaseClientCreateOrUpdateResponse{}, nil)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getApiVersion().Status.LastAppliedSpecSha).NotTo(Equal(fixture.Status.LastAppliedSpecSha))
+			})
+
+			It("should not retry a policy template that fails to render", func() {
+				apiVersion := getApiVersion()
+				apiVersion.Spec.Policy = &apimv1alpha1.ApiPolicySpec{
+					PolicyContent: toPointer(`<policies>[[ .Parameters.calls ]]</policies>`),
+					Templated:     toPointer(true),
+				}
+				Expect(k8s.Update(ctx, apiVersion)).To(Succeed())
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+
+				result, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(reconcile.Result{}))
+				Expect(<-reconciler.Recorder.(*record.FakeRecorder).Events).To(ContainSubstring(ReasonPolicyFailed))
+
+				apiVersion = getApiVersion()
+				Expect(apiVersion.Status.ErrorClass).To(Equal(string(azure.ErrorClassPermanent)))
+				Expect(apiVersion.Status.ErrorMessage).To(ContainSubstring("calls"))
+				Expect(apiVersion.Status.FailedGeneration).To(Equal(apiVersion.Generation))
+			})
+
+			It("should delete the policy and the API before removing the finalizer", func() {
+				Expect(k8s.Delete(ctx, getApiVersion())).To(Succeed())
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				apimClient.EXPECT().GetApiPolicy(gomock.Any(), azureName, nil).
+					Return(apim.APIPolicyClientGetResponse{ETag: toPointer(`"p1"`)}, nil)
+				gomock.InOrder(
+					apimClient.EXPECT().DeleteApiPolicy(gomock.Any(), azureName, `"p1"`, nil).Return(apim.APIPolicyClientDeleteResponse{}, nil),
+					apimClient.EXPECT().DeleteApi(gomock.Any(), azureName, `"1"`, nil).Return(apim.APIClientDeleteResponse{}, nil),
+				)
+
+				_, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				err = k8s.Get(ctx, typeNamespacedName, &apimv1alpha1.ApiVersion{})
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should keep the finalizer when the API can not be deleted", func() {
+				Expect(k8s.Delete(ctx, getApiVersion())).To(Succeed())
+				apimClient.EXPECT().GetApi(gomock.Any(), azureName, nil).Return(existingApi(), nil)
+				apimClient.EXPECT().GetApiPolicy(gomock.Any(), azureName, nil).
+					Return(apim.APIPolicyClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+				apimClient.EXPECT().DeleteApi(gomock.Any(), azureName, `"1"`, nil).
+					Return(apim.APIClientDeleteResponse{}, responseError(http.StatusPreconditionFailed, "PreconditionFailed"))
+
+				result, err := reconcileApiVersion()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeTrue())
+				Expect(getApiVersion().Finalizers).To(ContainElement("apiversion.finalizers.stilas.418.cloud"))
 			})
-			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
 })
+
+// setAppliedSpec records the sha as applied and released in the status
+func setAppliedSpec(status *apimv1alpha1.ApiVersionStatus, sha string) {
+	status.LastAppliedSpecSha = sha
+	status.LastReleaseId = "release-" + sha[:12]
+}
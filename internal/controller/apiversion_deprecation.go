@@ -0,0 +1,263 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+)
+
+// sunsetWarningPeriod is how long before the sunset of a deprecated version a warning event is emitted
+const sunsetWarningPeriod = 14 * 24 * time.Hour
+
+// inheritedPolicy is the API policy the deprecation headers are added to when the ApiVersion has no policy.
+// It only inherits the policies of the global scope.
+const inheritedPolicy = `<policies>
+	<inbound>
+		<base />
+	</inbound>
+	<backend>
+		<base />
+	</backend>
+	<outbound>
+		<base />
+	</outbound>
+	<on-error>
+		<base />
+	</on-error>
+</policies>`
+
+// apiPolicyContent returns the API policy that should be applied and its format. The policy is empty when the ApiVersion
+// has neither a policy nor a deprecation. Deprecation headers are added to the outbound section of the policy.
+func (r *ApiVersionReconciler) apiPolicyContent(ctx context.Context, apiVersion apimv1alpha1.ApiVersion, now time.Time) (string, *apim.PolicyContentFormat, error) {
+	policy := apiVersion.Spec.Policy
+	content, format := inheritedPolicy, toPointer(apim.PolicyContentFormatXML)
+	if policy != nil {
+		var err error
+		content, err = r.renderPolicyContent(ctx, apiVersion, policy)
+		if err != nil {
+			return "", nil, err
+		}
+		format = policy.PolicyFormat.AzurePolicyFormat()
+	}
+	if apiVersion.Spec.Deprecation == nil {
+		if policy == nil {
+			return "", nil, nil
+		}
+		return content, format, nil
+	}
+	raw := format != nil && *format == apim.PolicyContentFormatRawxml
+	content, err := addDeprecationHeaders(content, raw, apiVersion, now)
+	return content, format, err
+}
+
+// addDeprecationHeaders adds the Deprecation, Sunset and Link headers to the outbound section of the policy. After the
+// sunset of a version that is Gone, every request is answered with 410 Gone in the inbound section instead.
+func addDeprecationHeaders(policy string, raw bool, apiVersion apimv1alpha1.ApiVersion, now time.Time) (string, error) {
+	deprecation := apiVersion.Spec.Deprecation
+	headers := deprecationHeaders(apiVersion, raw)
+	if deprecation.IsSunset(now) && deprecation.AfterSunset == apimv1alpha1.SunsetActionGone {
+		gone := `<return-response><set-status code="410" reason="Gone" />` + headers + `</return-response>`
+		content, ok := insertIntoSection(policy, "inbound", gone)
+		if !ok {
+			return "", fmt.Errorf("policy has no inbound section to return 410 Gone from")
+		}
+		return content, nil
+	}
+	content, ok := insertIntoSection(policy, "outbound", headers)
+	if !ok {
+		return "", fmt.Errorf("policy has no outbound section to add the deprecation headers to")
+	}
+	return content, nil
+}
+
+// deprecationHeaders returns the set-header policies for the deprecation of the ApiVersion. The Deprecation header is
+// the structured date of RFC 9745 and Sunset the HTTP date of RFC 8594.
+func deprecationHeaders(apiVersion apimv1alpha1.ApiVersion, raw bool) string {
+	deprecation := apiVersion.Spec.Deprecation
+	// the Deprecation date starts with @, which APIM would read as a policy expression
+	headers := setHeaderPolicy("Deprecation", fmt.Sprintf(`@("@%d")`, deprecation.DeprecatedAt.Unix()), raw)
+	if deprecation.SunsetAt != nil {
+		headers += setHeaderPolicy("Sunset", deprecation.SunsetAt.UTC().Format(http.TimeFormat), raw)
+	}
+	if successor := successorVersionUrl(apiVersion); successor != "" {
+		headers += setHeaderPolicy("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor), raw)
+	}
+	return headers
+}
+
+func setHeaderPolicy(name string, value string, raw bool) string {
+	if !raw {
+		var escaped bytes.Buffer
+		_ = xml.EscapeText(&escaped, []byte(value))
+		value = escaped.String()
+	}
+	return fmt.Sprintf(`<set-header name="%s" exists-action="override"><value>%s</value></set-header>`, name, value)
+}
+
+// successorVersionUrl returns the relative URL of the successor version. It can only be derived for the Segment
+// versioning scheme, where the version is the path segment following the path of the API.
+func successorVersionUrl(apiVersion apimv1alpha1.ApiVersion) string {
+	successor := apiVersion.Spec.Deprecation.SuccessorVersion
+	if successor == nil || *successor == "" || apiVersion.Spec.ApiVersionScheme != apimv1alpha1.APIVersionSetContractDetailsVersioningSchemeSegment {
+		return ""
+	}
+	path := strings.Trim(apiVersion.Spec.Path, "/")
+	if path == "" {
+		return "/" + *successor
+	}
+	return "/" + path + "/" + *successor
+}
+
+// insertIntoSection inserts the fragment at the start of a section of the policy. It returns false if the policy has no
+// such section.
+func insertIntoSection(policy string, section string, fragment string) (string, bool) {
+	open := regexp.MustCompile(`<` + section + `\s*>`)
+	if location := open.FindStringIndex(policy); location != nil {
+		return policy[:location[1]] + fragment + policy[location[1]:], true
+	}
+	empty := regexp.MustCompile(`<` + section + `\s*/>`)
+	if location := empty.FindStringIndex(policy); location != nil {
+		return policy[:location[0]] + "<" + section + ">" + fragment + "</" + section + ">" + policy[location[1]:], true
+	}
+	return policy, false
+}
+
+// deprecationDescription returns the version description with a note on the deprecation of the version prepended
+func deprecationDescription(spec apimv1alpha1.ApiVersionSubSpec) *string {
+	deprecation := spec.Deprecation
+	if deprecation == nil {
+		return spec.VersionDescription
+	}
+	note := "Deprecated as of " + deprecation.DeprecatedAt.UTC().Format(time.DateOnly) + "."
+	if deprecation.SunsetAt != nil {
+		note += " Sunset on " + deprecation.SunsetAt.UTC().Format(time.DateOnly) + "."
+	}
+	if deprecation.SuccessorVersion != nil && *deprecation.SuccessorVersion != "" {
+		note += " Use version " + *deprecation.SuccessorVersion + " instead."
+	}
+	if description := stringValue(spec.VersionDescription); description != "" {
+		note += " " + description
+	}
+	return &note
+}
+
+// deprecationStage returns how far the deprecation has progressed at the given time
+func deprecationStage(deprecation *apimv1alpha1.ApiDeprecationSpec, now time.Time) apimv1alpha1.DeprecationStage {
+	switch {
+	case deprecation == nil || now.Before(deprecation.DeprecatedAt.Time):
+		return ""
+	case deprecation.IsSunset(now):
+		return apimv1alpha1.DeprecationStageSunset
+	case deprecation.SunsetAt != nil && !now.Before(deprecation.SunsetAt.Add(-sunsetWarningPeriod)):
+		return apimv1alpha1.DeprecationStageSunsetApproaching
+	default:
+		return apimv1alpha1.DeprecationStageDeprecated
+	}
+}
+
+// recordDeprecationStage updates the deprecation stage in status and emits an event when the stage changed. The status
+// is updated by the caller.
+func (r *ApiVersionReconciler) recordDeprecationStage(apiVersion *apimv1alpha1.ApiVersion, now time.Time) {
+	stage := deprecationStage(apiVersion.Spec.Deprecation, now)
+	if stage == apiVersion.Status.DeprecationStage {
+		return
+	}
+	apiVersion.Status.DeprecationStage = stage
+	deprecation := apiVersion.Spec.Deprecation
+	switch stage {
+	case apimv1alpha1.DeprecationStageDeprecated:
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonDeprecated, "API version is deprecated as of "+deprecation.DeprecatedAt.UTC().Format(time.RFC3339))
+	case apimv1alpha1.DeprecationStageSunsetApproaching:
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonSunsetApproaching, fmt.Sprintf("API version is sunset at %s, in %s", deprecation.SunsetAt.UTC().Format(time.RFC3339), deprecation.SunsetAt.Sub(now).Round(time.Hour)))
+	case apimv1alpha1.DeprecationStageSunset:
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonSunset, fmt.Sprintf("API version was sunset at %s, after sunset it is %s", deprecation.SunsetAt.UTC().Format(time.RFC3339), deprecation.AfterSunset))
+	}
+}
+
+// deprecationRequeue shortens the requeue of the result to the next change of the deprecation stage, so the policy and
+// events follow the dates without waiting for the resync.
+func deprecationRequeue(result ctrl.Result, deprecation *apimv1alpha1.ApiDeprecationSpec, now time.Time) ctrl.Result {
+	if deprecation == nil {
+		return result
+	}
+	transitions := []time.Time{deprecation.DeprecatedAt.Time}
+	if deprecation.SunsetAt != nil {
+		transitions = append(transitions, deprecation.SunsetAt.Add(-sunsetWarningPeriod), deprecation.SunsetAt.Time)
+	}
+	for _, transition := range transitions {
+		if until := transition.Sub(now); until > 0 {
+			if result.RequeueAfter == 0 || until < result.RequeueAfter {
+				// requeued slightly after the transition, so the reconcile sees the new stage
+				result.RequeueAfter = until + time.Second
+			}
+			break
+		}
+	}
+	return result
+}
+
+// removeSunsetApi deletes the API in APIM after the sunset of a version that is removed. The ApiVersion is kept and its
+// status is reset, so the API is created again if the sunset is moved.
+func (r *ApiVersionReconciler) removeSunsetApi(ctx context.Context, apiVersion apimv1alpha1.ApiVersion, azureApi apim.APIClientGetResponse, getErr error, now time.Time) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if azure.IgnoreNotFound(getErr) != nil {
+		logger.Error(getErr, "Failed to get API")
+		return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, getErr)
+	}
+	if getErr == nil {
+		logger.Info("Deleting API after its sunset")
+		_, err := r.apimClient.DeleteApi(ctx, getApiVersionName(apiVersion), stringValue(azureApi.ETag), nil)
+		if azure.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to delete API after its sunset")
+			r.Recorder.Event(&apiVersion, corev1.EventTypeWarning, ReasonDeleteFailed, eventMessage("Failed to delete API after its sunset", err))
+			return azureErrorResult(ctx, r, &apiVersion, &apiVersion.Status.AzureResourceStatus, err)
+		}
+		r.Recorder.Event(&apiVersion, corev1.EventTypeNormal, ReasonDeleted, "Deleted API "+getApiVersionName(apiVersion)+" after its sunset")
+	}
+	previousStatus := apiVersion.Status.DeepCopy()
+	r.recordDeprecationStage(&apiVersion, now)
+	apiVersion.Status = apimv1alpha1.ApiVersionStatus{
+		ProvisioningState:   "Sunset",
+		DeprecationStage:    apiVersion.Status.DeprecationStage,
+		AzureResourceStatus: apiVersion.Status.AzureResourceStatus,
+	}
+	apiVersion.Status.ETag = ""
+	clearAzureError(&apiVersion.Status.AzureResourceStatus, apiVersion.Generation)
+	if !reflect.DeepEqual(*previousStatus, apiVersion.Status) {
+		if err := r.Status().Update(ctx, &apiVersion); err != nil {
+			logger.Error(err, "Failed to update status")
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/utils"
+)
+
+// maxLintFindings is the number of lint findings kept in status
+const maxLintFindings = 100
+
+// lintOpenAPI lints the OpenAPI document that is about to be imported with the built-in rules and the rules of the
+// LintRuleSets of the ApiVersion. The findings are recorded in status and warnings in the LintWarnings condition.
+// It returns a message when errors, or an invalid rule, block the import. Documents that can not be parsed are not
+// linted, the import reports invalid documents.
+func (r *ApiVersionReconciler) lintOpenAPI(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion, content string) (string, error) {
+	logger := log.FromContext(ctx)
+	lint := apiVersion.Spec.Lint
+	apiVersion.Status.LintFindings = nil
+	if lint == nil {
+		meta.RemoveStatusCondition(&apiVersion.Status.Conditions, apimv1alpha1.ConditionTypeLintWarnings)
+		return "", nil
+	}
+	var rules []utils.LintRule
+	for _, name := range lint.RuleSets {
+		var ruleSet apimv1alpha1.LintRuleSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: apiVersion.Namespace, Name: name}, &ruleSet); err != nil {
+			return "", err
+		}
+		for _, rule := range ruleSet.Spec.Rules {
+			lintRule := toLintRule(rule)
+			if err := lintRule.Validate(); err != nil {
+				return fmt.Sprintf("Blocked import since LintRuleSet %s is invalid: %v", name, err), nil
+			}
+			rules = append(rules, lintRule)
+		}
+	}
+	findings, err := utils.LintOpenAPI(content, rules, lint.DisabledRules)
+	if err != nil {
+		logger.Error(err, "Failed to lint OpenAPI document")
+		return "", nil
+	}
+	var lintErrors, lintWarnings []utils.LintFinding
+	for _, finding := range findings {
+		if len(apiVersion.Status.LintFindings) < maxLintFindings {
+			apiVersion.Status.LintFindings = append(apiVersion.Status.LintFindings, apimv1alpha1.LintFindingStatus{
+				Rule:     finding.Rule,
+				Severity: apimv1alpha1.LintSeverity(finding.Severity),
+				Location: finding.Location,
+				Message:  finding.Message,
+			})
+		}
+		if finding.Severity == utils.LintSeverityError {
+			lintErrors = append(lintErrors, finding)
+		} else {
+			lintWarnings = append(lintWarnings, finding)
+		}
+	}
+	condition := metav1.Condition{
+		Type:               apimv1alpha1.ConditionTypeLintWarnings,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoViolations",
+		ObservedGeneration: apiVersion.Generation,
+	}
+	if len(lintWarnings) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "RuleViolations"
+		condition.Message = lintFindingsMessage("lint warnings", lintWarnings)
+	}
+	meta.SetStatusCondition(&apiVersion.Status.Conditions, condition)
+	logger.Info("Linted OpenAPI document", "errors", len(lintErrors), "warnings", len(lintWarnings))
+	if len(lintErrors) > 0 {
+		return "Blocked import of " + lintFindingsMessage("lint errors", lintErrors), nil
+	}
+	return "", nil
+}
+
+// toLintRule converts a rule of a LintRuleSet. Rules without a severity are warnings.
+func toLintRule(rule apimv1alpha1.LintRule) utils.LintRule {
+	severity := utils.LintSeverityWarning
+	if rule.Severity == apimv1alpha1.LintSeverityError {
+		severity = utils.LintSeverityError
+	}
+	return utils.LintRule{
+		Name:     rule.Name,
+		Severity: severity,
+		Target:   rule.Target,
+		Field:    stringValue(rule.Field),
+		Pattern:  stringValue(rule.Pattern),
+		Message:  stringValue(rule.Message),
+	}
+}
+
+// lintFindingsMessage summarizes the findings with the first few findings
+func lintFindingsMessage(kind string, findings []utils.LintFinding) string {
+	var details []string
+	for _, finding := range findings[:min(len(findings), 3)] {
+		details = append(details, fmt.Sprintf("%s: %s", finding.Rule, finding.Message))
+	}
+	if len(findings) > len(details) {
+		details = append(details, fmt.Sprintf("and %d more", len(findings)-len(details)))
+	}
+	return fmt.Sprintf("OpenAPI document with %d %s: %s", len(findings), kind, strings.Join(details, "; "))
+}
+
+// apiVersionsForLintRuleSet maps a LintRuleSet to the ApiVersions in its namespace linting with it
+func (r *ApiVersionReconciler) apiVersionsForLintRuleSet(ctx context.Context, obj client.Object) []reconcile.Request {
+	var apiVersions apimv1alpha1.ApiVersionList
+	if err := r.List(ctx, &apiVersions, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list ApiVersions for LintRuleSet", "lintRuleSet", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, apiVersion := range apiVersions.Items {
+		if apiVersion.Spec.Lint != nil && slices.Contains(apiVersion.Spec.Lint.RuleSets, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&apiVersion)})
+		}
+	}
+	return requests
+}
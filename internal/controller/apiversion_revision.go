@@ -0,0 +1,192 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+)
+
+var healthCheckClient = &http.Client{
+	Timeout: 10 * time.Second,
+	// redirects are not followed, so a health check can not be redirected to a host other than the one in the spec
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func getApiRevisionName(apiVersion apimv1alpha1.ApiVersion, revision string) string {
+	return fmt.Sprintf("%s;rev=%s", getApiVersionName(apiVersion), revision)
+}
+
+func currentRevision(azureApi apim.APIClientGetResponse) string {
+	if azureApi.Properties == nil || azureApi.Properties.APIRevision == nil {
+		return ""
+	}
+	return *azureApi.Properties.APIRevision
+}
+
+// nextRevision returns the revision that staged changes should be written to.
+// An existing pending revision is reused so repeated changes do not create a new revision each time.
+func nextRevision(apiVersion apimv1alpha1.ApiVersion, current string) string {
+	if apiVersion.Status.PendingRevision != "" {
+		return apiVersion.Status.PendingRevision
+	}
+	revision, err := strconv.Atoi(current)
+	if err != nil {
+		revision = 1
+	}
+	return strconv.Itoa(revision + 1)
+}
+
+// reconcileRevision promotes an approved pending revision or rolls back to the previous revision when requested
+func (r *ApiVersionReconciler) reconcileRevision(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion, current string) error {
+	logger := log.FromContext(ctx)
+	status := apiVersion.Status
+	status.CurrentRevision = current
+	if status.PendingRevision == current {
+		status.PendingRevision = ""
+	}
+	rollback := apiVersion.Annotations[apimv1alpha1.RollbackRevisionAnnotation] == "true"
+	switch {
+	case rollback && status.PreviousRevision != "":
+		logger.Info("Rolling back to previous revision", "revision", status.PreviousRevision)
+		if err := r.releaseRevision(ctx, *apiVersion, status.PreviousRevision); err != nil {
+			return err
+		}
+		r.Recorder.Event(apiVersion, corev1.EventTypeNormal, ReasonRevisionRolledBack, fmt.Sprintf("Rolled back from revision %s to revision %s", status.CurrentRevision, status.PreviousRevision))
+		status.CurrentRevision, status.PreviousRevision = status.PreviousRevision, status.CurrentRevision
+	case status.PendingRevision != "" && r.revisionApproved(ctx, *apiVersion, status.PendingRevision):
+		logger.Info("Promoting revision", "revision", status.PendingRevision)
+		if err := r.releaseRevision(ctx, *apiVersion, status.PendingRevision); err != nil {
+			return err
+		}
+		r.Recorder.Event(apiVersion, corev1.EventTypeNormal, ReasonRevisionPromoted, fmt.Sprintf("Promoted revision %s to current", status.PendingRevision))
+		status.PreviousRevision = status.CurrentRevision
+		status.CurrentRevision = status.PendingRevision
+		status.PendingRevision = ""
+	}
+	if !reflect.DeepEqual(status, apiVersion.Status) {
+		apiVersion.Status = status
+		if err := r.Status().Update(ctx, apiVersion); err != nil {
+			return fmt.Errorf("failed to update revision status: %w", err)
+		}
+	}
+	if rollback {
+		delete(apiVersion.Annotations, apimv1alpha1.RollbackRevisionAnnotation)
+		if err := r.Update(ctx, apiVersion); err != nil {
+			return fmt.Errorf("failed to remove rollback annotation: %w", err)
+		}
+	}
+	return nil
+}
+
+// revisionApproved returns true if the revision is approved with ApprovedRevision or the approve annotation. With
+// Automatic promotion the revision is approved when the health check succeeds, or right away without a health check.
+func (r *ApiVersionReconciler) revisionApproved(ctx context.Context, apiVersion apimv1alpha1.ApiVersion, revision string) bool {
+	logger := log.FromContext(ctx)
+	spec := apiVersion.Spec.Revision
+	if spec.ApprovedRevision != nil && *spec.ApprovedRevision == revision {
+		return true
+	}
+	if apiVersion.Annotations[apimv1alpha1.ApproveRevisionAnnotation] == revision {
+		return true
+	}
+	if spec.Promotion != apimv1alpha1.RevisionPromotionAutomatic {
+		return false
+	}
+	if spec.HealthCheckUrl == nil {
+		return true
+	}
+	url := strings.ReplaceAll(*spec.HealthCheckUrl, "{revision}", revision)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		logger.Info("Health check of pending revision failed", "revision", revision, "error", err.Error())
+		return false
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		logger.Info("Health check of pending revision failed", "revision", revision, "error", "scheme "+req.URL.Scheme+" is not http or https")
+		return false
+	}
+	resp, err := healthCheckClient.Do(req)
+	if err != nil {
+		logger.Info("Health check of pending revision failed", "revision", revision, "error", err.Error())
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		logger.Info("Health check of pending revision failed", "revision", revision, "statusCode", resp.StatusCode)
+		return false
+	}
+	return true
+}
+
+// releaseRevision makes the revision the current revision of the API by creating a release
+func (r *ApiVersionReconciler) releaseRevision(ctx context.Context, apiVersion apimv1alpha1.ApiVersion, revision string) error {
+	releaseId := fmt.Sprintf("rev-%s-%d", revision, time.Now().Unix())
+	if err := r.createRelease(ctx, apiVersion, getApiRevisionName(apiVersion, revision), releaseId); err != nil {
+		return fmt.Errorf("failed to release revision %s: %w", revision, err)
+	}
+	return nil
+}
+
+// releaseAppliedSpec creates a release for the applied spec unless it was already released. The status is updated by the caller.
+func (r *ApiVersionReconciler) releaseAppliedSpec(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion) error {
+	if apiVersion.Status.LastAppliedSpecSha == "" {
+		return nil
+	}
+	releaseId := fmt.Sprintf("release-%s", apiVersion.Status.LastAppliedSpecSha[:12])
+	if apiVersion.Status.LastReleaseId == releaseId {
+		return nil
+	}
+	if err := r.createRelease(ctx, *apiVersion, getApiVersionName(*apiVersion), releaseId); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to create release")
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonReleaseFailed, eventMessage("Failed to create release", err))
+		return err
+	}
+	apiVersion.Status.LastReleaseId = releaseId
+	return nil
+}
+
+// createRelease creates an API release for the given API revision with the release notes of the ApiVersion.
+// Releases are listed in the developer portal change log.
+func (r *ApiVersionReconciler) createRelease(ctx context.Context, apiVersion apimv1alpha1.ApiVersion, apiId string, releaseId string) error {
+	_, err := r.apimClient.CreateUpdateApiRelease(
+		ctx,
+		getApiVersionName(apiVersion),
+		releaseId,
+		apim.APIReleaseContract{
+			Properties: &apim.APIReleaseContractProperties{
+				APIID: toPointer("/apis/" + apiId),
+				Notes: apiVersion.Spec.ReleaseNotes,
+			},
+		},
+		nil,
+	)
+	return err
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+	"github.com/tjololo/stilas-az/internal/utils"
+)
+
+// webSocketHandshakeOperation is the operation APIM creates for WebSocket APIs. It handles the upgrade request of a
+// client and is the only operation of a WebSocket API that policies can be applied to.
+const webSocketHandshakeOperation = "onHandshake"
+
+// applyOnHandshakePolicy applies the onHandshake policy of a WebSocket API when it changed since it was last applied or
+// is missing in APIM. The status is updated by the caller.
+func (r *ApiVersionReconciler) applyOnHandshakePolicy(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion) error {
+	logger := log.FromContext(ctx)
+	policy := apiVersion.Spec.WebSocket.OnHandshakePolicy
+	policyContent, err := r.renderPolicyContent(ctx, *apiVersion, policy)
+	if err != nil {
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonPolicyFailed, eventMessage("Failed to render onHandshake policy", err))
+		return err
+	}
+	policySha, err := utils.Sha256FromContent(ctx, policyContent)
+	if err != nil {
+		return err
+	}
+	apiId := getApiVersionName(*apiVersion)
+	azurePolicy, err := r.apimClient.GetApiOperationPolicy(ctx, apiId, webSocketHandshakeOperation, nil)
+	if azure.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if apiVersion.Status.LastAppliedOnHandshakePolicySha == policySha && err == nil {
+		return nil
+	}
+	logger.Info("Creating or updating onHandshake policy")
+	var options *apim.APIOperationPolicyClientCreateOrUpdateOptions
+	if azurePolicy.ETag != nil {
+		options = &apim.APIOperationPolicyClientCreateOrUpdateOptions{IfMatch: azurePolicy.ETag}
+	}
+	_, err = r.apimClient.CreateUpdateApiOperationPolicy(ctx, apiId, webSocketHandshakeOperation, apim.PolicyContract{
+		Properties: &apim.PolicyContractProperties{
+			Value:  &policyContent,
+			Format: policy.PolicyFormat.AzurePolicyFormat(),
+		},
+	}, options)
+	if err != nil {
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonPolicyFailed, eventMessage("Failed to apply onHandshake policy", err))
+		return err
+	}
+	r.Recorder.Event(apiVersion, corev1.EventTypeNormal, ReasonPolicyApplied, "Applied onHandshake policy")
+	apiVersion.Status.LastAppliedOnHandshakePolicySha = policySha
+	return nil
+}
+
+// deleteOnHandshakePolicy removes the onHandshake policy from APIM after it was removed from the spec. The status is
+// updated by the caller.
+func (r *ApiVersionReconciler) deleteOnHandshakePolicy(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion) error {
+	log.FromContext(ctx).Info("Deleting onHandshake policy")
+	apiId := getApiVersionName(*apiVersion)
+	azurePolicy, err := r.apimClient.GetApiOperationPolicy(ctx, apiId, webSocketHandshakeOperation, nil)
+	if err == nil {
+		_, err = r.apimClient.DeleteApiOperationPolicy(ctx, apiId, webSocketHandshakeOperation, stringValue(azurePolicy.ETag), nil)
+	}
+	if azure.IgnoreNotFound(err) != nil {
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonPolicyFailed, eventMessage("Failed to delete onHandshake policy", err))
+		return err
+	}
+	r.Recorder.Event(apiVersion, corev1.EventTypeNormal, ReasonPolicyDeleted, "Deleted onHandshake policy")
+	apiVersion.Status.LastAppliedOnHandshakePolicySha = ""
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+)
+
+// conflictThreshold is the number of consecutive conflicts before the Conflict condition is set
+const conflictThreshold = 3
+
+// azureErrorResult records the class of an Azure error in the status of obj and returns the result the reconciler
+// should return. Throttled errors are requeued after the delay requested by Azure, conflicts are requeued immediately,
+// transient errors are returned to get the default backoff and permanent errors are not retried until the generation changes.
+// Conflicts are requeued so the resource is read and compared again before the write is retried.
+func azureErrorResult(ctx context.Context, c client.StatusClient, obj client.Object, status *apimv1alpha1.AzureResourceStatus, err error) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	classification := azure.ClassifyError(err)
+	status.ErrorClass = string(classification.Class)
+	status.ErrorMessage = err.Error()
+	status.FailedGeneration = 0
+	if classification.Class == azure.ErrorClassPermanent {
+		status.FailedGeneration = obj.GetGeneration()
+	}
+	if classification.Class == azure.ErrorClassConflict {
+		status.ConflictCount++
+		if status.ConflictCount >= conflictThreshold {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               apimv1alpha1.ConditionTypeConflict,
+				Status:             metav1.ConditionTrue,
+				Reason:             "RepeatedConflicts",
+				Message:            fmt.Sprintf("The resource was modified in Azure during %d consecutive updates", status.ConflictCount),
+				ObservedGeneration: obj.GetGeneration(),
+			})
+		}
+	}
+	if updateErr := c.Status().Update(ctx, obj); updateErr != nil {
+		logger.Error(updateErr, "Failed to record Azure error in status")
+		return ctrl.Result{}, err
+	}
+	switch classification.Class {
+	case azure.ErrorClassThrottled:
+		logger.Info("Azure throttled the request", "retryAfter", classification.RetryAfter)
+		return ctrl.Result{RequeueAfter: classification.RetryAfter}, nil
+	case azure.ErrorClassConflict:
+		return ctrl.Result{Requeue: true}, nil
+	case azure.ErrorClassPermanent:
+		logger.Info("Azure rejected the request, not retrying until the spec changes", "generation", obj.GetGeneration())
+		return ctrl.Result{}, nil
+	default:
+		return ctrl.Result{}, err
+	}
+}
+
+// clearAzureError resets the recorded Azure error after a successful reconcile. The Conflict condition is only set to
+// False when it was raised before, resources that never had repeated conflicts do not get the condition.
+func clearAzureError(status *apimv1alpha1.AzureResourceStatus, generation int64) {
+	status.ErrorClass = ""
+	status.ErrorMessage = ""
+	status.FailedGeneration = 0
+	status.ConflictCount = 0
+	if meta.FindStatusCondition(status.Conditions, apimv1alpha1.ConditionTypeConflict) == nil {
+		return
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               apimv1alpha1.ConditionTypeConflict,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoConflict",
+		ObservedGeneration: generation,
+	})
+}
+
+// toOperationErrorStatus converts the error of a failed long-running operation to its status representation
+func toOperationErrorStatus(opErr *azure.OperationError) *apimv1alpha1.OperationErrorStatus {
+	status := &apimv1alpha1.OperationErrorStatus{
+		Code:    opErr.Code,
+		Message: opErr.Message,
+		Target:  opErr.Target,
+	}
+	for _, detail := range opErr.Details {
+		status.Details = append(status.Details, apimv1alpha1.OperationErrorDetailStatus{
+			Code:    detail.Code,
+			Message: detail.Message,
+			Target:  detail.Target,
+		})
+	}
+	return status
+}
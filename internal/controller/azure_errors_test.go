@@ -0,0 +1,55 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+)
+
+var _ = Describe("Azure errors", func() {
+	It("should not add a Conflict condition when clearing an error without conflicts", func() {
+		status := apimv1alpha1.AzureResourceStatus{ErrorClass: "Transient", ErrorMessage: "connection reset"}
+
+		clearAzureError(&status, 2)
+		Expect(status.ErrorClass).To(BeEmpty())
+		Expect(status.ErrorMessage).To(BeEmpty())
+		Expect(status.Conditions).To(BeEmpty())
+	})
+
+	It("should set a raised Conflict condition to False when clearing the error", func() {
+		status := apimv1alpha1.AzureResourceStatus{ConflictCount: conflictThreshold}
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:   apimv1alpha1.ConditionTypeConflict,
+			Status: metav1.ConditionTrue,
+			Reason: "RepeatedConflicts",
+		})
+
+		clearAzureError(&status, 2)
+		Expect(status.ConflictCount).To(BeZero())
+		condition := meta.FindStatusCondition(status.Conditions, apimv1alpha1.ConditionTypeConflict)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("NoConflict"))
+		Expect(condition.ObservedGeneration).To(Equal(int64(2)))
+	})
+})
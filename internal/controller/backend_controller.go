@@ -21,13 +21,18 @@ import (
 	"fmt"
 	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
 	"github.com/tjololo/stilas-az/internal/azure"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
 )
@@ -35,14 +40,17 @@ import (
 // BackendReconciler reconciles a Backend object
 type BackendReconciler struct {
 	client.Client
-	Scheme     *runtime.Scheme
-	NewClient  newApimCLient
-	apimClient *azure.APIMClient
+	Scheme       *runtime.Scheme
+	NewClient    newApimCLient
+	Recorder     record.EventRecorder
+	ResyncPeriod time.Duration
+	apimClient   azure.Client
 }
 
 // +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=backends,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=backends/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=backends/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -54,6 +62,8 @@ type BackendReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.19.0/pkg/reconcile
 func (r *BackendReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "Backend", req)
+	defer span.End()
 	logger := log.FromContext(ctx)
 
 	// get the backend
@@ -68,6 +78,10 @@ func (r *BackendReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			return ctrl.Result{}, err
 		}
 	}
+	if backend.DeletionTimestamp == nil && backend.Status.PermanentlyFailed(backend.Generation) {
+		logger.Info("Generation failed permanently, waiting for spec change", "generation", backend.Generation)
+		return ctrl.Result{}, nil
+	}
 	subscriptionID, resourcesGroup, apimName, err := getConfigFromEnv()
 	if err != nil {
 		logger.Error(err, "Failed to get configuration. No reason to requeue")
@@ -88,21 +102,22 @@ func (r *BackendReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			createdBackend, err := r.apimClient.CreateUpdateBackend(ctx, getBackendName(backend), toAzureBackend(&backend), nil)
 			if err != nil {
 				logger.Error(err, "Failed to create backend")
+				r.Recorder.Event(&backend, corev1.EventTypeWarning, ReasonBackendFailed, eventMessage("Failed to create backend", err))
 				backend.Status.ProvisioningState = "Failed"
-				if errUpdate := r.Status().Update(ctx, &backend); errUpdate != nil {
-					logger.Error(err, "Failed to update status")
-				}
-				return ctrl.Result{}, err
+				return azureErrorResult(ctx, r, &backend, &backend.Status.AzureResourceStatus, err)
 			}
+			r.Recorder.Event(&backend, corev1.EventTypeNormal, ReasonBackendCreated, "Created backend "+getBackendName(backend))
 			backend.Status.BackendID = *createdBackend.ID
+			backend.Status.ETag = stringValue(createdBackend.ETag)
 			backend.Status.ProvisioningState = "Succeeded"
+			clearAzureError(&backend.Status.AzureResourceStatus, backend.Generation)
 			if errUpdate := r.Status().Update(ctx, &backend); errUpdate != nil {
 				logger.Error(err, "Failed to update status")
 			}
-			return ctrl.Result{}, nil
+			return resyncAfter(r.ResyncPeriod), nil
 		} else {
 			logger.Error(err, "Failed to get backend")
-			return ctrl.Result{}, err
+			return azureErrorResult(ctx, r, &backend, &backend.Status.AzureResourceStatus, err)
 		}
 	}
 	if backend.DeletionTimestamp != nil {
@@ -110,8 +125,10 @@ func (r *BackendReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		_, err := r.apimClient.DeleteBackend(ctx, getBackendName(backend), *azureBackend.ETag, nil)
 		if azure.IgnoreNotFound(err) != nil {
 			logger.Error(err, "Failed to delete backend")
-			return ctrl.Result{}, err
+			r.Recorder.Event(&backend, corev1.EventTypeWarning, ReasonDeleteFailed, eventMessage("Failed to delete backend", err))
+			return azureErrorResult(ctx, r, &backend, &backend.Status.AzureResourceStatus, err)
 		}
+		r.Recorder.Event(&backend, corev1.EventTypeNormal, ReasonDeleted, "Deleted backend "+getBackendName(backend))
 		controllerutil.RemoveFinalizer(&backend, "backend.finalizers.stilas.418.cloud")
 		if err := r.Update(ctx, &backend); err != nil {
 			logger.Error(err, "Failed to remove finalizer")
@@ -119,30 +136,36 @@ func (r *BackendReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 		return ctrl.Result{}, nil
 	}
+	previousStatus := backend.Status.DeepCopy()
+	backend.Status.ETag = stringValue(azureBackend.ETag)
 	if *azureBackend.Properties.URL != backend.Spec.Url {
 		logger.Info("Updating backend")
-		updatedBackend, err := r.apimClient.CreateUpdateBackend(ctx, getBackendName(backend), toAzureBackend(&backend), nil)
+		updatedBackend, err := r.apimClient.CreateUpdateBackend(ctx, getBackendName(backend), toAzureBackend(&backend), &apim.BackendClientCreateOrUpdateOptions{IfMatch: azureBackend.ETag})
 		if err != nil {
 			logger.Error(err, "Failed to update backend")
+			r.Recorder.Event(&backend, corev1.EventTypeWarning, ReasonBackendFailed, eventMessage("Failed to update backend", err))
 			backend.Status.ProvisioningState = "Failed"
-			if errUpdate := r.Status().Update(ctx, &backend); errUpdate != nil {
-				logger.Error(err, "Failed to update status")
-			}
-			return ctrl.Result{}, err
+			return azureErrorResult(ctx, r, &backend, &backend.Status.AzureResourceStatus, err)
 		}
+		r.Recorder.Event(&backend, corev1.EventTypeNormal, ReasonBackendUpdated, "Updated backend "+getBackendName(backend))
 		backend.Status.BackendID = *updatedBackend.ID
+		backend.Status.ETag = stringValue(updatedBackend.ETag)
 		backend.Status.ProvisioningState = "Succeeded"
-		if errUpdate := r.Status().Update(ctx, &backend); errUpdate != nil {
+	}
+	clearAzureError(&backend.Status.AzureResourceStatus, backend.Generation)
+	if !reflect.DeepEqual(*previousStatus, backend.Status) {
+		if err := r.Status().Update(ctx, &backend); err != nil {
 			logger.Error(err, "Failed to update status")
+			return ctrl.Result{}, err
 		}
 	}
-	return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+	return resyncAfter(r.ResyncPeriod), nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *BackendReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&apimv1alpha1.Backend{}).
+		For(&apimv1alpha1.Backend{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
 
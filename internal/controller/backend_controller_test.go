@@ -18,67 +18,199 @@ package controller
 
 import (
 	"context"
+	"net/http"
+	"time"
 
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+	"github.com/tjololo/stilas-az/internal/azure/apimfake"
+	"github.com/tjololo/stilas-az/internal/azure/mock"
 )
 
 var _ = Describe("Backend Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
+		const azureName = "default-" + resourceName
 
 		ctx := context.Background()
 
 		typeNamespacedName := types.NamespacedName{
 			Name:      resourceName,
-			Namespace: "default", // TODO(user):Modify as needed
+			Namespace: "default",
 		}
-		backend := &apimv1alpha1.Backend{}
+		var (
+			k8s        client.Client
+			apimClient *mock.MockClient
+			reconciler *BackendReconciler
+		)
 
 		BeforeEach(func() {
+			setAzureEnv()
 			By("creating the custom resource for the Kind Backend")
-			err := k8sClient.Get(ctx, typeNamespacedName, backend)
-			if err != nil && errors.IsNotFound(err) {
-				resource := &apimv1alpha1.Backend{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      resourceName,
-						Namespace: "default",
-					},
-					// TODO(user): Specify other spec details if needed.
-				}
-				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			k8s = newFakeClient(&apimv1alpha1.Backend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       resourceName,
+					Namespace:  "default",
+					Generation: 1,
+					Finalizers: []string{"backend.finalizers.stilas.418.cloud"},
+				},
+				Spec: apimv1alpha1.BackendSpec{
+					Title: "Test backend",
+					Url:   "https://backend.example.com",
+				},
+			})
+			apimClient = mock.NewMockClient(gomock.NewController(GinkgoT()))
+			reconciler = &BackendReconciler{
+				Client:    k8s,
+				Scheme:    k8s.Scheme(),
+				NewClient: newClientFor(apimClient),
+				Recorder:  record.NewFakeRecorder(10),
 			}
 		})
 
-		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
-			resource := &apimv1alpha1.Backend{}
-			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+		reconcileBackend := func() (reconcile.Result, error) {
+			return reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		}
+		getBackend := func() *apimv1alpha1.Backend {
+			backend := &apimv1alpha1.Backend{}
+			Expect(k8s.Get(ctx, typeNamespacedName, backend)).To(Succeed())
+			return backend
+		}
+
+		It("should create the backend when it does not exist in Azure", func() {
+			apimClient.EXPECT().GetBackend(gomock.Any(), azureName, nil).
+				Return(apim.BackendClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+			apimClient.EXPECT().CreateUpdateBackend(gomock.Any(), azureName, gomock.Any(), nil).
+				DoAndReturn(func(_ context.Context, _ string, backend apim.BackendContract, _ *apim.BackendClientCreateOrUpdateOptions) (apim.BackendClientCreateOrUpdateResponse, error) {
+					Expect(*backend.Properties.URL).To(Equal("https://backend.example.com"))
+					return apim.BackendClientCreateOrUpdateResponse{
+						BackendContract: apim.BackendContract{ID: toPointer("/backends/" + azureName)},
+						ETag:            toPointer(`"1"`),
+					}, nil
+				})
+
+			result, err := reconcileBackend()
 			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">=", DefaultResyncPeriod))
 
-			By("Cleanup the specific resource instance Backend")
-			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			backend := getBackend()
+			Expect(backend.Status.ProvisioningState).To(Equal("Succeeded"))
+			Expect(backend.Status.BackendID).To(Equal("/backends/" + azureName))
+			Expect(backend.Status.ETag).To(Equal(`"1"`))
 		})
-		It("should successfully reconcile the resource", func() {
-			By("Reconciling the created resource")
-			controllerReconciler := &BackendReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
-			}
 
-			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
-				NamespacedName: typeNamespacedName,
-			})
+		It("should update the backend with If-Match when the url changed", func() {
+			apimClient.EXPECT().GetBackend(gomock.Any(), azureName, nil).Return(apim.BackendClientGetResponse{
+				BackendContract: apim.BackendContract{
+					ID:         toPointer("/backends/" + azureName),
+					Properties: &apim.BackendContractProperties{URL: toPointer("https://old.example.com")},
+				},
+				ETag: toPointer(`"1"`),
+			}, nil)
+			apimClient.EXPECT().CreateUpdateBackend(gomock.Any(), azureName, gomock.Any(), &apim.BackendClientCreateOrUpdateOptions{IfMatch: toPointer(`"1"`)}).
+				Return(apim.BackendClientCreateOrUpdateResponse{
+					BackendContract: apim.BackendContract{ID: toPointer("/backends/" + azureName)},
+					ETag:            toPointer(`"2"`),
+				}, nil)
+
+			_, err := reconcileBackend()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getBackend().Status.ETag).To(Equal(`"2"`))
+		})
+
+		It("should not update the backend when it is up to date", func() {
+			apimClient.EXPECT().GetBackend(gomock.Any(), azureName, nil).Return(apim.BackendClientGetResponse{
+				BackendContract: apim.BackendContract{
+					Properties: &apim.BackendContractProperties{URL: toPointer("https://backend.example.com")},
+				},
+				ETag: toPointer(`"1"`),
+			}, nil)
+
+			_, err := reconcileBackend()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should resync after the configured period with jitter", func() {
+			reconciler.ResyncPeriod = time.Hour
+			apimClient.EXPECT().GetBackend(gomock.Any(), azureName, nil).Return(apim.BackendClientGetResponse{
+				BackendContract: apim.BackendContract{
+					Properties: &apim.BackendContractProperties{URL: toPointer("https://backend.example.com")},
+				},
+				ETag: toPointer(`"1"`),
+			}, nil)
+
+			result, err := reconcileBackend()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">=", time.Hour))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", time.Hour+time.Duration(resyncJitter*float64(time.Hour))))
+		})
+
+		It("should stop retrying when Azure rejects the backend", func() {
+			apimClient.EXPECT().GetBackend(gomock.Any(), azureName, nil).
+				Return(apim.BackendClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+			apimClient.EXPECT().CreateUpdateBackend(gomock.Any(), azureName, gomock.Any(), nil).
+				Return(apim.BackendClientCreateOrUpdateResponse{}, responseError(http.StatusBadRequest, "ValidationError"))
+
+			result, err := reconcileBackend()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+
+			backend := getBackend()
+			Expect(backend.Status.ProvisioningState).To(Equal("Failed"))
+			Expect(backend.Status.ErrorClass).To(Equal(string(azure.ErrorClassPermanent)))
+			Expect(backend.Status.FailedGeneration).To(Equal(backend.Generation))
+
+			By("Skipping the generation that failed permanently")
+			_, err = reconcileBackend()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should delete the backend in Azure before removing the finalizer", func() {
+			Expect(k8s.Delete(ctx, getBackend())).To(Succeed())
+			apimClient.EXPECT().GetBackend(gomock.Any(), azureName, nil).Return(apim.BackendClientGetResponse{
+				BackendContract: apim.BackendContract{
+					Properties: &apim.BackendContractProperties{URL: toPointer("https://backend.example.com")},
+				},
+				ETag: toPointer(`"3"`),
+			}, nil)
+			apimClient.EXPECT().DeleteBackend(gomock.Any(), azureName, `"3"`, nil).Return(apim.BackendClientDeleteResponse{}, nil)
+
+			_, err := reconcileBackend()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8s.Get(ctx, typeNamespacedName, &apimv1alpha1.Backend{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should create and delete the backend in the fake APIM server", func() {
+			server := apimfake.NewServer()
+			DeferCleanup(server.Close)
+			reconciler.NewClient = server.NewClient
+
+			_, err := reconcileBackend()
+			Expect(err).NotTo(HaveOccurred())
+			azureBackend, found := server.Resource("backends/" + azureName)
+			Expect(found).To(BeTrue())
+			Expect(azureBackend["properties"]).To(HaveKeyWithValue("url", "https://backend.example.com"))
+			Expect(getBackend().Status.ETag).NotTo(BeEmpty())
+
+			By("Deleting the backend")
+			Expect(k8s.Delete(ctx, getBackend())).To(Succeed())
+			_, err = reconcileBackend()
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+			_, found = server.Resource("backends/" + azureName)
+			Expect(found).To(BeFalse())
 		})
 	})
 })
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+
+	"github.com/tjololo/stilas-az/internal/azure"
+)
+
+// Event reasons emitted by the reconcilers
+const (
+	ReasonVersionSetCreated  = "VersionSetCreated"
+	ReasonVersionSetUpdated  = "VersionSetUpdated"
+	ReasonVersionSetFailed   = "VersionSetFailed"
+	ReasonImportStarted      = "ImportStarted"
+	ReasonImportCompleted    = "ImportCompleted"
+	ReasonImportFailed       = "ImportFailed"
+	ReasonImportBlocked      = "ImportBlocked"
+	ReasonBreakingChanges    = "BreakingChanges"
+	ReasonPolicyApplied      = "PolicyApplied"
+	ReasonPolicyFailed       = "PolicyFailed"
+	ReasonPolicyDeleted      = "PolicyDeleted"
+	ReasonRevisionPromoted   = "RevisionPromoted"
+	ReasonRevisionRolledBack = "RevisionRolledBack"
+	ReasonReleaseFailed      = "ReleaseFailed"
+	ReasonBackendCreated     = "BackendCreated"
+	ReasonBackendUpdated     = "BackendUpdated"
+	ReasonBackendFailed      = "BackendFailed"
+	ReasonSchemaApplied      = "SchemaApplied"
+	ReasonSchemaFailed       = "SchemaFailed"
+	ReasonResolverCreated    = "ResolverCreated"
+	ReasonResolverUpdated    = "ResolverUpdated"
+	ReasonResolverFailed     = "ResolverFailed"
+	ReasonResolverInvalid    = "ResolverInvalid"
+	ReasonOperationCreated   = "OperationCreated"
+	ReasonOperationUpdated   = "OperationUpdated"
+	ReasonOperationFailed    = "OperationFailed"
+	ReasonOperationInvalid   = "OperationInvalid"
+	ReasonDeprecated         = "Deprecated"
+	ReasonSunsetApproaching  = "SunsetApproaching"
+	ReasonSunset             = "Sunset"
+	ReasonDeleted            = "Deleted"
+	ReasonDeleteFailed       = "DeleteFailed"
+)
+
+// eventMessage formats a failure message for an event, including the Azure error code when err is an Azure response error
+func eventMessage(message string, err error) string {
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return fmt.Sprintf("%s: Azure returned %s", message, azure.ErrorCode(err))
+	}
+	return fmt.Sprintf("%s: %v", message, err)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+	"github.com/tjololo/stilas-az/internal/utils"
+)
+
+const (
+	// graphQLSchemaId is the id of the schema of a synthetic GraphQL API. APIM only supports one schema per GraphQL API.
+	graphQLSchemaId = "graphql"
+	// graphQLSchemaContentType is the content type APIM expects for GraphQL SDL schemas
+	graphQLSchemaContentType = "application/vnd.ms-azure-apim.graphql.schema"
+)
+
+// graphQLSchemaSDL returns the schema of the synthetic GraphQL API served by the ApiVersion, read inline or from the
+// referenced ConfigMap
+func graphQLSchemaSDL(ctx context.Context, c client.Reader, apiVersion apimv1alpha1.ApiVersion) (string, error) {
+	source := apiVersion.Spec.GraphQLSchema
+	if source == nil {
+		return "", fmt.Errorf("ApiVersion %s has no GraphQL schema", apiVersion.Name)
+	}
+	if source.ConfigMapKeyRef == nil {
+		return stringValue(source.Sdl), nil
+	}
+	return configMapKeyValue(ctx, c, apiVersion.Namespace, *source.ConfigMapKeyRef)
+}
+
+// applyGraphQLSchema uploads the schema of a synthetic GraphQL API when it changed since it was last applied. It returns
+// false while the upload is still running, the upload is resumed on the next reconcile unless the schema changed in the
+// meantime. The status is updated by the caller.
+func (r *ApiVersionReconciler) applyGraphQLSchema(ctx context.Context, apiVersion *apimv1alpha1.ApiVersion) (bool, error) {
+	logger := log.FromContext(ctx)
+	sdl, err := graphQLSchemaSDL(ctx, r.Client, *apiVersion)
+	if err != nil {
+		return false, err
+	}
+	if _, err := utils.ParseGraphQLSchema(sdl); err != nil {
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonSchemaFailed, eventMessage("Invalid GraphQL schema", err))
+		return false, fmt.Errorf("invalid GraphQL schema: %w", err)
+	}
+	schemaSha, err := utils.Sha256FromContent(ctx, sdl)
+	if err != nil {
+		return false, err
+	}
+	if apiVersion.Status.LastAppliedSchemaSha == schemaSha {
+		return true, nil
+	}
+	options := &apim.APISchemaClientBeginCreateOrUpdateOptions{}
+	if apiVersion.Status.SchemaResumeToken != "" && apiVersion.Status.SchemaResumeSha == schemaSha {
+		logger.Info("Resuming GraphQL schema upload")
+		options.ResumeToken = apiVersion.Status.SchemaResumeToken
+	} else {
+		logger.Info("Applying GraphQL schema")
+	}
+	apiVersion.Status.SchemaResumeToken = ""
+	apiVersion.Status.SchemaResumeSha = ""
+	poller, err := r.apimClient.CreateUpdateApiSchema(ctx, getApiVersionName(*apiVersion), graphQLSchemaId, apim.SchemaContract{
+		Properties: &apim.SchemaContractProperties{
+			ContentType: toPointer(graphQLSchemaContentType),
+			Document:    &apim.SchemaDocumentProperties{Value: &sdl},
+		},
+	}, options)
+	if err != nil {
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonSchemaFailed, eventMessage("Failed to apply GraphQL schema", err))
+		return false, err
+	}
+	op, err := azure.StartResumeOperation(ctx, poller)
+	if err != nil {
+		if op.ResumeToken != "" {
+			apiVersion.Status.SchemaResumeToken = op.ResumeToken
+			apiVersion.Status.SchemaResumeSha = schemaSha
+		}
+		return false, err
+	}
+	switch op.Status {
+	case azure.OperationStatusFailed:
+		r.Recorder.Event(apiVersion, corev1.EventTypeWarning, ReasonSchemaFailed, "Failed to apply GraphQL schema: "+op.Error.Error())
+		apiVersion.Status.LastOperationError = toOperationErrorStatus(op.Error)
+		return false, op.Error
+	case azure.OperationStatusInProgress:
+		apiVersion.Status.SchemaResumeToken = op.ResumeToken
+		apiVersion.Status.SchemaResumeSha = schemaSha
+		return false, nil
+	}
+	r.Recorder.Event(apiVersion, corev1.EventTypeNormal, ReasonSchemaApplied, "Applied GraphQL schema")
+	apiVersion.Status.LastAppliedSchemaSha = schemaSha
+	apiVersion.Status.LastOperationError = nil
+	return true, nil
+}
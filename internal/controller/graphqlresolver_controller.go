@@ -0,0 +1,383 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+	"github.com/tjololo/stilas-az/internal/utils"
+)
+
+// GraphQLResolverReconciler reconciles a GraphQLResolver object
+type GraphQLResolverReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	NewClient    newApimCLient
+	Recorder     record.EventRecorder
+	ResyncPeriod time.Duration
+	apimClient   azure.Client
+}
+
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=graphqlresolvers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=graphqlresolvers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=graphqlresolvers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apim.azure.stilas.418.cloud,resources=apiversions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile creates the resolver and its policy under the synthetic GraphQL API of the referenced ApiVersion once the
+// schema of the API is applied and contains the resolved field.
+func (r *GraphQLResolverReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "GraphQLResolver", req)
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	var resolver apimv1alpha1.GraphQLResolver
+	if err := r.Get(ctx, req.NamespacedName, &resolver); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !controllerutil.ContainsFinalizer(&resolver, "graphqlresolver.finalizers.stilas.418.cloud") {
+		controllerutil.AddFinalizer(&resolver, "graphqlresolver.finalizers.stilas.418.cloud")
+		if err := r.Update(ctx, &resolver); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+	if resolver.DeletionTimestamp == nil && resolver.Status.PermanentlyFailed(resolver.Generation) {
+		logger.Info("Generation failed permanently, waiting for spec change", "generation", resolver.Generation)
+		return ctrl.Result{}, nil
+	}
+	var apiVersion apimv1alpha1.ApiVersion
+	apiVersionErr := r.Get(ctx, types.NamespacedName{Namespace: resolver.Namespace, Name: resolver.Spec.ApiVersionRef}, &apiVersion)
+	if client.IgnoreNotFound(apiVersionErr) != nil {
+		logger.Error(apiVersionErr, "Failed to get ApiVersion")
+		return ctrl.Result{}, apiVersionErr
+	}
+	if apierrors.IsNotFound(apiVersionErr) {
+		if resolver.DeletionTimestamp != nil {
+			// the resolver was deleted in APIM together with the API
+			return r.removeFinalizer(ctx, &resolver)
+		}
+		logger.Info("Waiting for ApiVersion", "apiVersion", resolver.Spec.ApiVersionRef)
+		return r.updatePendingStatus(ctx, &resolver)
+	}
+	subscriptionID, resourcesGroup, apimName, err := getConfigFromEnv()
+	if err != nil {
+		logger.Error(err, "Failed to get configuration. No reason to requeue")
+		return ctrl.Result{}, nil
+	}
+	r.apimClient, err = r.NewClient(azure.ApimClientConfig{
+		SubscriptionId:  subscriptionID,
+		ResourceGroup:   resourcesGroup,
+		ApimServiceName: apimName,
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	apiId := getApiVersionName(apiVersion)
+	resolverId := getGraphQLResolverName(resolver)
+	azureResolver, err := r.apimClient.GetGraphQLResolver(ctx, apiId, resolverId, nil)
+	if resolver.DeletionTimestamp != nil {
+		return r.deleteGraphQLResolver(ctx, &resolver, apiId, azureResolver, err)
+	}
+	if azure.IgnoreNotFound(err) != nil {
+		logger.Error(err, "Failed to get resolver")
+		return azureErrorResult(ctx, r, &resolver, &resolver.Status.AzureResourceStatus, err)
+	}
+	resolverExists := err == nil
+	if !metav1.IsControlledBy(&resolver, &apiVersion) {
+		if err := controllerutil.SetControllerReference(&apiVersion, &resolver, r.Scheme); err != nil {
+			logger.Error(err, "Failed to set owner reference")
+			return ctrl.Result{}, err
+		}
+		if err := r.Update(ctx, &resolver); err != nil {
+			logger.Error(err, "Failed to set owner reference")
+			return ctrl.Result{}, err
+		}
+	}
+	if apiVersion.Spec.GraphQLSchema == nil {
+		return r.invalidResolverResult(ctx, &resolver, fmt.Sprintf("ApiVersion %s is not a synthetic GraphQL API", apiVersion.Name))
+	}
+	if apiVersion.Status.LastAppliedSchemaSha == "" {
+		logger.Info("Waiting for the GraphQL schema to be applied", "apiVersion", apiVersion.Name)
+		return r.updatePendingStatus(ctx, &resolver)
+	}
+	sdl, err := graphQLSchemaSDL(ctx, r.Client, apiVersion)
+	if err != nil {
+		logger.Error(err, "Failed to get GraphQL schema")
+		return ctrl.Result{}, err
+	}
+	schema, err := utils.ParseGraphQLSchema(sdl)
+	if err != nil {
+		return r.invalidResolverResult(ctx, &resolver, fmt.Sprintf("The GraphQL schema of ApiVersion %s is invalid: %v", apiVersion.Name, err))
+	}
+	if !schema.HasField(resolver.Spec.Type, resolver.Spec.Field) {
+		return r.invalidResolverResult(ctx, &resolver, fmt.Sprintf("Field %s is not defined in the GraphQL schema of ApiVersion %s", graphQLResolverPath(resolver), apiVersion.Name))
+	}
+
+	previousStatus := resolver.Status.DeepCopy()
+	resolver.Status.ETag = stringValue(azureResolver.ETag)
+	desired := toAzureResolver(resolver)
+	if !resolverExists || resolverRequiresUpdate(azureResolver.Properties, desired.Properties) {
+		logger.Info("Creating or updating resolver")
+		var options *apim.GraphQLAPIResolverClientCreateOrUpdateOptions
+		if resolverExists {
+			options = &apim.GraphQLAPIResolverClientCreateOrUpdateOptions{IfMatch: azureResolver.ETag}
+		}
+		result, err := r.apimClient.CreateUpdateGraphQLResolver(ctx, apiId, resolverId, desired, options)
+		if err != nil {
+			logger.Error(err, "Failed to create/update resolver")
+			r.Recorder.Event(&resolver, corev1.EventTypeWarning, ReasonResolverFailed, eventMessage("Failed to apply resolver", err))
+			resolver.Status.ProvisioningState = "Failed"
+			return azureErrorResult(ctx, r, &resolver, &resolver.Status.AzureResourceStatus, err)
+		}
+		if resolverExists {
+			r.Recorder.Event(&resolver, corev1.EventTypeNormal, ReasonResolverUpdated, "Updated resolver "+resolverId)
+		} else {
+			r.Recorder.Event(&resolver, corev1.EventTypeNormal, ReasonResolverCreated, "Created resolver "+resolverId)
+		}
+		resolver.Status.ResolverID = stringValue(result.ID)
+		resolver.Status.ETag = stringValue(result.ETag)
+	}
+	if err := r.applyResolverPolicy(ctx, &resolver, apiId, resolverId); err != nil {
+		logger.Error(err, "Failed to apply resolver policy")
+		resolver.Status.ProvisioningState = "Failed"
+		return azureErrorResult(ctx, r, &resolver, &resolver.Status.AzureResourceStatus, err)
+	}
+	resolver.Status.ProvisioningState = "Succeeded"
+	clearAzureError(&resolver.Status.AzureResourceStatus, resolver.Generation)
+	if !reflect.DeepEqual(*previousStatus, resolver.Status) {
+		if err := r.Status().Update(ctx, &resolver); err != nil {
+			logger.Error(err, "Failed to update status")
+			return ctrl.Result{}, err
+		}
+	}
+	return resyncAfter(r.ResyncPeriod), nil
+}
+
+// applyResolverPolicy applies the resolver policy when it changed since it was last applied or is missing in APIM.
+// The status is updated by the caller.
+func (r *GraphQLResolverReconciler) applyResolverPolicy(ctx context.Context, resolver *apimv1alpha1.GraphQLResolver, apiId string, resolverId string) error {
+	policyContent := graphQLResolverPolicy(*resolver)
+	policySha, err := utils.Sha256FromContent(ctx, policyContent)
+	if err != nil {
+		return err
+	}
+	azurePolicy, err := r.apimClient.GetGraphQLResolverPolicy(ctx, apiId, resolverId, nil)
+	if azure.IgnoreNotFound(err) != nil {
+		return err
+	}
+	resolver.Status.PolicyETag = stringValue(azurePolicy.ETag)
+	if resolver.Status.LastAppliedPolicySha == policySha && err == nil {
+		return nil
+	}
+	var options *apim.GraphQLAPIResolverPolicyClientCreateOrUpdateOptions
+	if resolver.Status.PolicyETag != "" {
+		options = &apim.GraphQLAPIResolverPolicyClientCreateOrUpdateOptions{IfMatch: toPointer(resolver.Status.PolicyETag)}
+	}
+	result, err := r.apimClient.CreateUpdateGraphQLResolverPolicy(ctx, apiId, resolverId, apim.PolicyContract{
+		Properties: &apim.PolicyContractProperties{
+			Value:  &policyContent,
+			Format: toPointer(apim.PolicyContentFormatXML),
+		},
+	}, options)
+	if err != nil {
+		r.Recorder.Event(resolver, corev1.EventTypeWarning, ReasonPolicyFailed, eventMessage("Failed to apply resolver policy", err))
+		return err
+	}
+	r.Recorder.Event(resolver, corev1.EventTypeNormal, ReasonPolicyApplied, "Applied resolver policy")
+	resolver.Status.LastAppliedPolicySha = policySha
+	resolver.Status.PolicyETag = stringValue(result.ETag)
+	return nil
+}
+
+// deleteGraphQLResolver deletes the resolver in APIM using the ETag read from Azure as If-Match. The resolver policy is
+// deleted with the resolver.
+func (r *GraphQLResolverReconciler) deleteGraphQLResolver(ctx context.Context, resolver *apimv1alpha1.GraphQLResolver, apiId string, azureResolver apim.GraphQLAPIResolverClientGetResponse, getErr error) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Deleting resolver")
+	if azure.IgnoreNotFound(getErr) != nil {
+		logger.Error(getErr, "Failed to get resolver")
+		return azureErrorResult(ctx, r, resolver, &resolver.Status.AzureResourceStatus, getErr)
+	}
+	if getErr == nil {
+		_, err := r.apimClient.DeleteGraphQLResolver(ctx, apiId, getGraphQLResolverName(*resolver), stringValue(azureResolver.ETag), nil)
+		if azure.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to delete resolver")
+			r.Recorder.Event(resolver, corev1.EventTypeWarning, ReasonDeleteFailed, eventMessage("Failed to delete resolver", err))
+			return azureErrorResult(ctx, r, resolver, &resolver.Status.AzureResourceStatus, err)
+		}
+	}
+	r.Recorder.Event(resolver, corev1.EventTypeNormal, ReasonDeleted, "Deleted resolver "+getGraphQLResolverName(*resolver))
+	return r.removeFinalizer(ctx, resolver)
+}
+
+func (r *GraphQLResolverReconciler) removeFinalizer(ctx context.Context, resolver *apimv1alpha1.GraphQLResolver) (ctrl.Result, error) {
+	controllerutil.RemoveFinalizer(resolver, "graphqlresolver.finalizers.stilas.418.cloud")
+	if err := r.Update(ctx, resolver); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to remove finalizer")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// updatePendingStatus marks the resolver as waiting for its ApiVersion. The resolver is reconciled again through the
+// ApiVersion watch once the ApiVersion is created or its schema is applied.
+func (r *GraphQLResolverReconciler) updatePendingStatus(ctx context.Context, resolver *apimv1alpha1.GraphQLResolver) (ctrl.Result, error) {
+	if resolver.Status.ProvisioningState == "Pending" {
+		return ctrl.Result{}, nil
+	}
+	resolver.Status.ProvisioningState = "Pending"
+	if err := r.Status().Update(ctx, resolver); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// invalidResolverResult records that the resolver does not match the schema of its ApiVersion. It is not retried until
+// the resolver or the ApiVersion changes, both trigger a reconcile through the watches.
+func (r *GraphQLResolverReconciler) invalidResolverResult(ctx context.Context, resolver *apimv1alpha1.GraphQLResolver, message string) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("Resolver is invalid", "reason", message)
+	if resolver.Status.ProvisioningState == "Invalid" && resolver.Status.ErrorMessage == message {
+		return ctrl.Result{}, nil
+	}
+	r.Recorder.Event(resolver, corev1.EventTypeWarning, ReasonResolverInvalid, message)
+	resolver.Status.ProvisioningState = "Invalid"
+	resolver.Status.ErrorMessage = message
+	if err := r.Status().Update(ctx, resolver); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GraphQLResolverReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// ApiVersions are watched without predicates since resolvers wait for the schema recorded in the ApiVersion status
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apimv1alpha1.GraphQLResolver{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&apimv1alpha1.ApiVersion{}, handler.EnqueueRequestsFromMapFunc(r.resolversForApiVersion)).
+		Complete(r)
+}
+
+// resolversForApiVersion maps an ApiVersion to the GraphQLResolvers referencing it
+func (r *GraphQLResolverReconciler) resolversForApiVersion(ctx context.Context, obj client.Object) []reconcile.Request {
+	var resolvers apimv1alpha1.GraphQLResolverList
+	if err := r.List(ctx, &resolvers, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list GraphQLResolvers for ApiVersion", "apiVersion", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, resolver := range resolvers.Items {
+		if resolver.Spec.ApiVersionRef == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&resolver)})
+		}
+	}
+	return requests
+}
+
+func getGraphQLResolverName(resolver apimv1alpha1.GraphQLResolver) string {
+	return fmt.Sprintf("%s-%s", resolver.Namespace, resolver.Name)
+}
+
+// graphQLResolverPath returns the Type/field path APIM uses to identify the resolved field
+func graphQLResolverPath(resolver apimv1alpha1.GraphQLResolver) string {
+	return resolver.Spec.Type + "/" + resolver.Spec.Field
+}
+
+func toAzureResolver(resolver apimv1alpha1.GraphQLResolver) apim.ResolverContract {
+	displayName := resolver.Spec.DisplayName
+	if displayName == nil {
+		displayName = toPointer(resolver.Spec.Type + "." + resolver.Spec.Field)
+	}
+	return apim.ResolverContract{
+		Properties: &apim.ResolverEntityBaseContract{
+			DisplayName: displayName,
+			Path:        toPointer(graphQLResolverPath(resolver)),
+			Description: resolver.Spec.Description,
+		},
+	}
+}
+
+func resolverRequiresUpdate(current *apim.ResolverEntityBaseContract, desired *apim.ResolverEntityBaseContract) bool {
+	if current == nil {
+		return true
+	}
+	return stringValue(current.Path) != stringValue(desired.Path) ||
+		stringValue(current.DisplayName) != stringValue(desired.DisplayName) ||
+		stringValue(current.Description) != stringValue(desired.Description)
+}
+
+// graphQLResolverPolicy returns the policy of the resolver, rendering the HTTP data source as an http-data-source policy
+func graphQLResolverPolicy(resolver apimv1alpha1.GraphQLResolver) string {
+	source := resolver.Spec.HttpDataSource
+	if source == nil {
+		return stringValue(resolver.Spec.PolicyContent)
+	}
+	method := source.Method
+	if method == "" {
+		method = "GET"
+	}
+	var policy strings.Builder
+	policy.WriteString("<http-data-source>\n\t<http-request>\n")
+	fmt.Fprintf(&policy, "\t\t<set-method>%s</set-method>\n", escapeXML(method))
+	fmt.Fprintf(&policy, "\t\t<set-url>%s</set-url>\n", escapeXML(source.Url))
+	headers := make([]string, 0, len(source.Headers))
+	for name := range source.Headers {
+		headers = append(headers, name)
+	}
+	slices.Sort(headers)
+	for _, name := range headers {
+		fmt.Fprintf(&policy, "\t\t<set-header name=\"%s\" exists-action=\"override\">\n\t\t\t<value>%s</value>\n\t\t</set-header>\n", escapeXML(name), escapeXML(source.Headers[name]))
+	}
+	if source.Body != nil {
+		fmt.Fprintf(&policy, "\t\t<set-body template=\"liquid\">%s</set-body>\n", escapeXML(*source.Body))
+	}
+	policy.WriteString("\t</http-request>\n</http-data-source>")
+	return policy.String()
+}
+
+func escapeXML(s string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(s))
+	return escaped.String()
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure/mock"
+)
+
+var _ = Describe("GraphQLResolver Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-resource"
+		const azureName = "default-" + resourceName
+		const apiId = "default-books"
+		const sdl = "type Query {\n  book(id: ID!): Book\n}\ntype Book {\n  id: ID!\n}"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		var (
+			k8s        client.Client
+			apimClient *mock.MockClient
+			reconciler *GraphQLResolverReconciler
+			field      string
+			schemaSha  string
+			schema     *apimv1alpha1.GraphQLSchemaSource
+		)
+
+		BeforeEach(func() {
+			field = "book"
+			schemaSha = "applied"
+			schema = &apimv1alpha1.GraphQLSchemaSource{Sdl: toPointer(sdl)}
+		})
+
+		JustBeforeEach(func() {
+			setAzureEnv()
+			By("creating the custom resource for the Kind GraphQLResolver")
+			k8s = newFakeClient(
+				&apimv1alpha1.ApiVersion{
+					ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default", UID: "books-uid"},
+					Spec: apimv1alpha1.ApiVersionSpec{
+						Path:              "books",
+						APIType:           toPointer(apimv1alpha1.APITypeGraphql),
+						ApiVersionSubSpec: apimv1alpha1.ApiVersionSubSpec{DisplayName: "Books", GraphQLSchema: schema},
+					},
+					Status: apimv1alpha1.ApiVersionStatus{LastAppliedSchemaSha: schemaSha},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "books-schema", Namespace: "default"},
+					Data:       map[string]string{"schema.graphql": sdl},
+				},
+				&apimv1alpha1.GraphQLResolver{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       resourceName,
+						Namespace:  "default",
+						Generation: 1,
+						Finalizers: []string{"graphqlresolver.finalizers.stilas.418.cloud"},
+					},
+					Spec: apimv1alpha1.GraphQLResolverSpec{
+						ApiVersionRef: "books",
+						Type:          "Query",
+						Field:         field,
+						HttpDataSource: &apimv1alpha1.GraphQLHttpDataSource{
+							Url:     "https://books.example.com/books",
+							Method:  "GET",
+							Headers: map[string]string{"Accept": "application/json"},
+						},
+					},
+				},
+			)
+			apimClient = mock.NewMockClient(gomock.NewController(GinkgoT()))
+			reconciler = &GraphQLResolverReconciler{
+				Client:    k8s,
+				Scheme:    k8s.Scheme(),
+				NewClient: newClientFor(apimClient),
+				Recorder:  record.NewFakeRecorder(10),
+			}
+		})
+
+		reconcileResolver := func() (reconcile.Result, error) {
+			return reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		}
+		getResolver := func() *apimv1alpha1.GraphQLResolver {
+			resolver := &apimv1alpha1.GraphQLResolver{}
+			Expect(k8s.Get(ctx, typeNamespacedName, resolver)).To(Succeed())
+			return resolver
+		}
+		expectCreate := func() {
+			apimClient.EXPECT().GetGraphQLResolver(gomock.Any(), apiId, azureName, nil).
+				Return(apim.GraphQLAPIResolverClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+			apimClient.EXPECT().CreateUpdateGraphQLResolver(gomock.Any(), apiId, azureName, gomock.Any(), nil).
+				DoAndReturn(func(_ context.Context, _ string, _ string, resolver apim.ResolverContract, _ *apim.GraphQLAPIResolverClientCreateOrUpdateOptions) (apim.GraphQLAPIResolverClientCreateOrUpdateResponse, error) {
+					Expect(*resolver.Properties.Path).To(Equal("Query/book"))
+					Expect(*resolver.Properties.DisplayName).To(Equal("Query.book"))
+					return apim.GraphQLAPIResolverClientCreateOrUpdateResponse{
+						ResolverContract: apim.ResolverContract{ID: toPointer("/apis/" + apiId + "/resolvers/" + azureName)},
+						ETag:             toPointer(`"1"`),
+					}, nil
+				})
+			apimClient.EXPECT().GetGraphQLResolverPolicy(gomock.Any(), apiId, azureName, nil).
+				Return(apim.GraphQLAPIResolverPolicyClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+			apimClient.EXPECT().CreateUpdateGraphQLResolverPolicy(gomock.Any(), apiId, azureName, gomock.Any(), nil).
+				DoAndReturn(func(_ context.Context, _ string, _ string, policy apim.PolicyContract, _ *apim.GraphQLAPIResolverPolicyClientCreateOrUpdateOptions) (apim.GraphQLAPIResolverPolicyClientCreateOrUpdateResponse, error) {
+					Expect(*policy.Properties.Value).To(ContainSubstring("<set-url>https://books.example.com/books</set-url>"))
+					Expect(*policy.Properties.Value).To(ContainSubstring(`<set-header name="Accept" exists-action="override">`))
+					return apim.GraphQLAPIResolverPolicyClientCreateOrUpdateResponse{ETag: toPointer(`"p1"`)}, nil
+				})
+		}
+
+		It("should create the resolver and its policy under the ApiVersion", func() {
+			expectCreate()
+
+			result, err := reconcileResolver()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			resolver := getResolver()
+			Expect(resolver.Status.ProvisioningState).To(Equal("Succeeded"))
+			Expect(resolver.Status.ResolverID).To(Equal("/apis/" + apiId + "/resolvers/" + azureName))
+			Expect(resolver.Status.PolicyETag).To(Equal(`"p1"`))
+			Expect(resolver.Status.LastAppliedPolicySha).NotTo(BeEmpty())
+			Expect(resolver.OwnerReferences).To(HaveLen(1))
+			Expect(resolver.OwnerReferences[0].UID).To(Equal(types.UID("books-uid")))
+		})
+
+		Context("when the schema is read from a ConfigMap", func() {
+			BeforeEach(func() {
+				schema = &apimv1alpha1.GraphQLSchemaSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "books-schema"},
+					Key:                  "schema.graphql",
+				}}
+			})
+
+			It("should validate the field against the ConfigMap", func() {
+				expectCreate()
+
+				_, err := reconcileResolver()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getResolver().Status.ProvisioningState).To(Equal("Succeeded"))
+			})
+		})
+
+		Context("when the field is not in the schema", func() {
+			BeforeEach(func() {
+				field = "author"
+			})
+
+			It("should not create the resolver", func() {
+				apimClient.EXPECT().GetGraphQLResolver(gomock.Any(), apiId, azureName, nil).
+					Return(apim.GraphQLAPIResolverClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+
+				result, err := reconcileResolver()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(reconcile.Result{}))
+
+				resolver := getResolver()
+				Expect(resolver.Status.ProvisioningState).To(Equal("Invalid"))
+				Expect(resolver.Status.ErrorMessage).To(ContainSubstring("Query/author"))
+			})
+		})
+
+		Context("when the schema is not applied yet", func() {
+			BeforeEach(func() {
+				schemaSha = ""
+			})
+
+			It("should wait for the schema", func() {
+				apimClient.EXPECT().GetGraphQLResolver(gomock.Any(), apiId, azureName, nil).
+					Return(apim.GraphQLAPIResolverClientGetResponse{}, responseError(http.StatusNotFound, "ResourceNotFound"))
+
+				_, err := reconcileResolver()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getResolver().Status.ProvisioningState).To(Equal("Pending"))
+			})
+		})
+
+		It("should delete the resolver in Azure before removing the finalizer", func() {
+			Expect(k8s.Delete(ctx, getResolver())).To(Succeed())
+			apimClient.EXPECT().GetGraphQLResolver(gomock.Any(), apiId, azureName, nil).
+				Return(apim.GraphQLAPIResolverClientGetResponse{ETag: toPointer(`"2"`)}, nil)
+			apimClient.EXPECT().DeleteGraphQLResolver(gomock.Any(), apiId, azureName, `"2"`, nil).
+				Return(apim.GraphQLAPIResolverClientDeleteResponse{}, nil)
+
+			_, err := reconcileResolver()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8s.Get(ctx, typeNamespacedName, &apimv1alpha1.GraphQLResolver{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should remove the finalizer without calling Azure when the ApiVersion is gone", func() {
+			Expect(k8s.Delete(ctx, &apimv1alpha1.ApiVersion{ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default"}})).To(Succeed())
+			Expect(k8s.Delete(ctx, getResolver())).To(Succeed())
+
+			_, err := reconcileResolver()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8s.Get(ctx, typeNamespacedName, &apimv1alpha1.GraphQLResolver{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	azruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	apim "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+)
+
+const testApiURL = "https://management.azure.com/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ApiManagement/service/apim/apis/api"
+
+// newFakeClient returns a client backed by an in-memory object tracker with the status subresource and the field
+// indexes the reconcilers rely on
+func newFakeClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(apimv1alpha1.AddToScheme(scheme)).To(Succeed())
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&apimv1alpha1.Api{}, &apimv1alpha1.ApiOperation{}, &apimv1alpha1.ApiVersion{}, &apimv1alpha1.Backend{}, &apimv1alpha1.GraphQLResolver{}).
+		WithIndex(&apimv1alpha1.ApiVersion{}, "metadata.ownerReferences.uid", ownerUIDIndex).
+		Build()
+}
+
+// setAzureEnv sets the APIM configuration read by the reconcilers for the duration of the spec
+func setAzureEnv() {
+	GinkgoT().Setenv("STILAS_AZ_SUBSCRIPTION_ID", "sub")
+	GinkgoT().Setenv("STILAS_AZ_RESOURCE_GROUP", "rg")
+	GinkgoT().Setenv("STILAS_AZ_APIM_NAME", "apim")
+}
+
+// newClientFor returns a NewClient func for the reconcilers that always returns apimClient
+func newClientFor(apimClient azure.Client) newApimCLient {
+	return func(azure.ApimClientConfig) (azure.Client, error) {
+		return apimClient, nil
+	}
+}
+
+// responseError returns the error the Azure SDK returns for a response with the status code
+func responseError(statusCode int, code string) error {
+	return &azcore.ResponseError{StatusCode: statusCode, ErrorCode: code}
+}
+
+// lroTransport returns the queued poll responses in order
+type lroTransport struct {
+	responses []*http.Response
+}
+
+func (t *lroTransport) Do(req *http.Request) (*http.Response, error) {
+	if len(t.responses) == 0 {
+		return nil, fmt.Errorf("unexpected request %s %s", req.Method, req.URL)
+	}
+	resp := t.responses[0]
+	t.responses = t.responses[1:]
+	resp.Request = req
+	return resp, nil
+}
+
+func lroResponse(statusCode int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// newApiPoller returns a poller for an API create or update that started with the initial response and is polled
+// with the queued responses
+func newApiPoller(initial *http.Response, polls ...*http.Response) *azruntime.Poller[apim.APIClientCreateOrUpdateResponse] {
+	return newPoller[apim.APIClientCreateOrUpdateResponse](initial, polls...)
+}
+
+// newPoller returns a poller for a long-running operation that started with the initial response and is polled with
+// the queued responses
+func newPoller[T any](initial *http.Response, polls ...*http.Response) *azruntime.Poller[T] {
+	pl := azruntime.NewPipeline("test", "v0.0.1", azruntime.PipelineOptions{}, &policy.ClientOptions{
+		Transport: &lroTransport{responses: polls},
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+	req, err := http.NewRequest(http.MethodPut, testApiURL, nil)
+	Expect(err).NotTo(HaveOccurred())
+	initial.Request = req
+	poller, err := azruntime.NewPoller[T](initial, pl, nil)
+	Expect(err).NotTo(HaveOccurred())
+	return poller
+}
+
+// succeededApiPoller returns a poller for an API import that completed synchronously
+func succeededApiPoller(apiId string) *azruntime.Poller[apim.APIClientCreateOrUpdateResponse] {
+	return newApiPoller(lroResponse(http.StatusOK, fmt.Sprintf(`{"id":"/apis/%[1]s","name":"%[1]s","properties":{"isCurrent":true}}`, apiId), nil))
+}
+
+// inProgressApiPoller returns a poller for an API import that is still running after the next poll
+func inProgressApiPoller() *azruntime.Poller[apim.APIClientCreateOrUpdateResponse] {
+	location := http.Header{"Location": []string{testApiURL + "/operations/1"}}
+	return newApiPoller(
+		lroResponse(http.StatusAccepted, "", location.Clone()),
+		lroResponse(http.StatusAccepted, "", location.Clone()),
+	)
+}
+
+// failedApiPoller returns a poller for an API import that fails with the error code on the next poll
+func failedApiPoller(code string, message string) *azruntime.Poller[apim.APIClientCreateOrUpdateResponse] {
+	operation := http.Header{}
+	operation.Set("Azure-AsyncOperation", testApiURL+"/operations/1")
+	return newApiPoller(
+		lroResponse(http.StatusAccepted, "", operation),
+		lroResponse(http.StatusOK, fmt.Sprintf(`{"status":"Failed","error":{"code":%q,"message":%q}}`, code, message), nil),
+	)
+}
+
+// inProgressSchemaPoller returns a poller for a schema upload that is still running after the next poll
+func inProgressSchemaPoller() *azruntime.Poller[apim.APISchemaClientCreateOrUpdateResponse] {
+	location := http.Header{"Location": []string{testApiURL + "/operations/1"}}
+	return newPoller[apim.APISchemaClientCreateOrUpdateResponse](
+		lroResponse(http.StatusAccepted, "", location.Clone()),
+		lroResponse(http.StatusAccepted, "", location.Clone()),
+	)
+}
+
+// succeededSchemaPoller returns a poller for a schema upload that completed synchronously
+func succeededSchemaPoller() *azruntime.Poller[apim.APISchemaClientCreateOrUpdateResponse] {
+	return newPoller[apim.APISchemaClientCreateOrUpdateResponse](lroResponse(http.StatusOK, `{"name":"graphql","properties":{"contentType":"application/vnd.ms-azure-apim.graphql.schema"}}`, nil))
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+)
+
+var resourcesDesc = prometheus.NewDesc(
+	"stilas_az_resources",
+	"Number of resources per kind and provisioning state",
+	[]string{"kind", "provisioning_state"},
+	nil,
+)
+
+// ProvisioningStateCollector reports the number of Api, ApiVersion, ApiOperation, Backend and GraphQLResolver resources per
+// provisioning state. The resources are counted from the manager cache when metrics are scraped.
+type ProvisioningStateCollector struct {
+	Reader client.Reader
+}
+
+// NewProvisioningStateCollector creates a ProvisioningStateCollector reading resources from reader
+func NewProvisioningStateCollector(reader client.Reader) *ProvisioningStateCollector {
+	return &ProvisioningStateCollector{Reader: reader}
+}
+
+// Describe implements prometheus.Collector
+func (c *ProvisioningStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- resourcesDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *ProvisioningStateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var apis apimv1alpha1.ApiList
+	if err := c.Reader.List(ctx, &apis); err == nil {
+		states := make(map[string]int)
+		for _, api := range apis.Items {
+			states[provisioningStateLabel(api.Status.ProvisioningState)]++
+		}
+		collectStates(ch, "Api", states)
+	}
+	var versions apimv1alpha1.ApiVersionList
+	if err := c.Reader.List(ctx, &versions); err == nil {
+		states := make(map[string]int)
+		for _, version := range versions.Items {
+			states[provisioningStateLabel(version.Status.ProvisioningState)]++
+		}
+		collectStates(ch, "ApiVersion", states)
+	}
+	var operations apimv1alpha1.ApiOperationList
+	if err := c.Reader.List(ctx, &operations); err == nil {
+		states := make(map[string]int)
+		for _, operation := range operations.Items {
+			states[provisioningStateLabel(operation.Status.ProvisioningState)]++
+		}
+		collectStates(ch, "ApiOperation", states)
+	}
+	var backends apimv1alpha1.BackendList
+	if err := c.Reader.List(ctx, &backends); err == nil {
+		states := make(map[string]int)
+		for _, backend := range backends.Items {
+			states[provisioningStateLabel(backend.Status.ProvisioningState)]++
+		}
+		collectStates(ch, "Backend", states)
+	}
+	var resolvers apimv1alpha1.GraphQLResolverList
+	if err := c.Reader.List(ctx, &resolvers); err == nil {
+		states := make(map[string]int)
+		for _, resolver := range resolvers.Items {
+			states[provisioningStateLabel(resolver.Status.ProvisioningState)]++
+		}
+		collectStates(ch, "GraphQLResolver", states)
+	}
+}
+
+func collectStates(ch chan<- prometheus.Metric, kind string, states map[string]int) {
+	for state, count := range states {
+		ch <- prometheus.MustNewConstMetric(resourcesDesc, prometheus.GaugeValue, float64(count), kind, state)
+	}
+}
+
+func provisioningStateLabel(state string) string {
+	if state == "" {
+		return "Unknown"
+	}
+	return state
+}
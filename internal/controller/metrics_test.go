@@ -0,0 +1,81 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure/apimfake"
+)
+
+var _ = Describe("Provisioning state metrics", func() {
+	const header = `
+# HELP stilas_az_resources Number of resources per kind and provisioning state
+# TYPE stilas_az_resources gauge
+`
+
+	It("should count the resources per kind and provisioning state", func() {
+		k8s := newFakeClient(
+			&apimv1alpha1.Api{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"}, Status: apimv1alpha1.ApiStatus{ProvisioningState: "Succeeded"}},
+			&apimv1alpha1.Api{ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "default"}, Status: apimv1alpha1.ApiStatus{ProvisioningState: "Succeeded"}},
+			&apimv1alpha1.Api{ObjectMeta: metav1.ObjectMeta{Name: "invoices", Namespace: "default"}},
+			&apimv1alpha1.ApiVersion{ObjectMeta: metav1.ObjectMeta{Name: "orders-v1", Namespace: "default"}, Status: apimv1alpha1.ApiVersionStatus{ProvisioningState: "Failed"}},
+			&apimv1alpha1.ApiVersion{ObjectMeta: metav1.ObjectMeta{Name: "orders-v2", Namespace: "default"}, Status: apimv1alpha1.ApiVersionStatus{ProvisioningState: "Blocked"}},
+		)
+
+		Expect(testutil.CollectAndCompare(NewProvisioningStateCollector(k8s), strings.NewReader(header+`
+stilas_az_resources{kind="Api",provisioning_state="Succeeded"} 2
+stilas_az_resources{kind="Api",provisioning_state="Unknown"} 1
+stilas_az_resources{kind="ApiVersion",provisioning_state="Blocked"} 1
+stilas_az_resources{kind="ApiVersion",provisioning_state="Failed"} 1
+`))).To(Succeed())
+	})
+
+	It("should report the provisioning state written by a reconcile", func() {
+		ctx := context.Background()
+		setAzureEnv()
+		server := apimfake.NewServer()
+		DeferCleanup(server.Close)
+		k8s := newFakeClient(&apimv1alpha1.Backend{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default", Generation: 1, Finalizers: []string{"backend.finalizers.stilas.418.cloud"}},
+			Spec:       apimv1alpha1.BackendSpec{Title: "Orders", Url: "https://orders.example.com"},
+		})
+		collector := NewProvisioningStateCollector(k8s)
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(header+`
+stilas_az_resources{kind="Backend",provisioning_state="Unknown"} 1
+`))).To(Succeed())
+
+		reconciler := &BackendReconciler{Client: k8s, Scheme: k8s.Scheme(), NewClient: server.NewClient, Recorder: record.NewFakeRecorder(10)}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "orders", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(header+`
+stilas_az_resources{kind="Backend",provisioning_state="Succeeded"} 1
+`))).To(Succeed())
+	})
+})
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+)
+
+// policyTemplateData is the data context available when rendering a templated policy
+type policyTemplateData struct {
+	Name        string
+	Namespace   string
+	ApiName     string
+	Labels      map[string]string
+	Annotations map[string]string
+	Spec        apimv1alpha1.ApiVersionSpec
+	Backends    map[string]string
+	Parameters  map[string]string
+}
+
+// renderPolicyContent returns the content of a policy of the ApiVersion that should be applied.
+// Templated policies are rendered with the ApiVersion, referenced Backends and policy parameters as data.
+func (r *ApiVersionReconciler) renderPolicyContent(ctx context.Context, apiVersion apimv1alpha1.ApiVersion, policy *apimv1alpha1.ApiPolicySpec) (string, error) {
+	if !policy.IsTemplated() {
+		return *policy.PolicyContent, nil
+	}
+	backends := make(map[string]string)
+	for _, name := range policy.Backends {
+		var backend apimv1alpha1.Backend
+		if err := r.Get(ctx, client.ObjectKey{Namespace: apiVersion.Namespace, Name: name}, &backend); err != nil {
+			return "", fmt.Errorf("failed to get backend %s referenced by policy: %w", name, err)
+		}
+		backends[name] = getBackendName(backend)
+	}
+	return renderPolicyTemplate(*policy.PolicyContent, policyTemplateData{
+		Name:        apiVersion.Name,
+		Namespace:   apiVersion.Namespace,
+		ApiName:     getApiVersionName(apiVersion),
+		Labels:      apiVersion.Labels,
+		Annotations: apiVersion.Annotations,
+		Spec:        apiVersion.Spec,
+		Backends:    backends,
+		Parameters:  policy.PolicyParameters,
+	})
+}
+
+// renderPolicyTemplate renders a policy template with [[ ]] delimiters, which do not clash with the {{ }} named values
+// of APIM. Parse and execution errors are permanent since the template fails the same way until it is changed.
+func renderPolicyTemplate(content string, data policyTemplateData) (string, error) {
+	tmpl, err := template.New("policy").Delims("[[", "]]").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", azure.WithErrorClass(fmt.Errorf("failed to parse policy template: %w", err), azure.ErrorClassPermanent)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", azure.WithErrorClass(fmt.Errorf("failed to render policy template: %w", err), azure.ErrorClassPermanent)
+	}
+	return buf.String(), nil
+}
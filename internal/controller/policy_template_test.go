@@ -0,0 +1,89 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure"
+)
+
+var _ = Describe("Policy templates", func() {
+	data := policyTemplateData{
+		Name:        "orders-v1",
+		Namespace:   "default",
+		ApiName:     "default-orders-v1",
+		Labels:      map[string]string{"team": "orders"},
+		Annotations: map[string]string{"owner": "orders@example.com"},
+		Spec:        apimv1alpha1.ApiVersionSpec{Path: "orders"},
+		Backends:    map[string]string{"orders": "default-orders"},
+		Parameters:  map[string]string{"calls": "100"},
+	}
+
+	DescribeTable("rendering a policy template",
+		func(content string, want string) {
+			Expect(renderPolicyTemplate(content, data)).To(Equal(want))
+		},
+		Entry("leaves APIM named values untouched", `<set-header name="key"><value>{{orders-key}}</value></set-header>`, `<set-header name="key"><value>{{orders-key}}</value></set-header>`),
+		Entry("renders the ApiVersion", `[[ .ApiName ]] in [[ .Namespace ]] at /[[ .Spec.Path ]]`, `default-orders-v1 in default at /orders`),
+		Entry("renders labels and annotations", `[[ .Labels.team ]] [[ .Annotations.owner ]]`, `orders orders@example.com`),
+		Entry("renders backend ids", `<set-backend-service backend-id="[[ .Backends.orders ]]" />`, `<set-backend-service backend-id="default-orders" />`),
+		Entry("renders parameters", `<rate-limit calls="[[ .Parameters.calls ]]" renewal-period="60" />`, `<rate-limit calls="100" renewal-period="60" />`),
+	)
+
+	DescribeTable("failing to render a policy template",
+		func(content string) {
+			_, err := renderPolicyTemplate(content, data)
+			Expect(err).To(HaveOccurred())
+			Expect(azure.ClassifyError(err).Class).To(Equal(azure.ErrorClassPermanent))
+		},
+		Entry("with a missing parameter", `[[ .Parameters.period ]]`),
+		Entry("with a missing backend", `[[ .Backends.payments ]]`),
+		Entry("with an unknown field", `[[ .Version ]]`),
+		Entry("with an unterminated action", `[[ .ApiName `),
+		Entry("with an unknown function", `[[ lower .ApiName ]]`),
+	)
+
+	It("should look up the APIM ids of the referenced backends", func() {
+		k8s := newFakeClient(&apimv1alpha1.Backend{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"}})
+		reconciler := &ApiVersionReconciler{Client: k8s, Scheme: k8s.Scheme(), Recorder: record.NewFakeRecorder(10)}
+		apiVersion := apimv1alpha1.ApiVersion{ObjectMeta: metav1.ObjectMeta{Name: "orders-v1", Namespace: "default"}}
+
+		content, err := reconciler.renderPolicyContent(context.Background(), apiVersion, &apimv1alpha1.ApiPolicySpec{
+			PolicyContent: toPointer(`[[ .Backends.orders ]]`),
+			Templated:     toPointer(true),
+			Backends:      []string{"orders"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal("default-orders"))
+
+		_, err = reconciler.renderPolicyContent(context.Background(), apiVersion, &apimv1alpha1.ApiPolicySpec{
+			PolicyContent: toPointer(`[[ .Backends.payments ]]`),
+			Templated:     toPointer(true),
+			Backends:      []string{"payments"},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(azure.ClassifyError(err).Class).To(Equal(azure.ErrorClassTransient))
+	})
+})
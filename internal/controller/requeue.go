@@ -0,0 +1,42 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// DefaultResyncPeriod is used when a reconciler has no resync period configured
+	DefaultResyncPeriod = 10 * time.Minute
+	// lroPollInterval is the requeue interval while a long-running operation is pending
+	lroPollInterval = 5 * time.Second
+	// resyncJitter is the maximum fraction added to the resync period so resources do not resync in lockstep
+	resyncJitter = 0.1
+)
+
+// resyncAfter returns a result that requeues the resource after the resync period with jitter.
+// Changes to the spec trigger a reconcile through the watch, so the resync only detects changes made in Azure.
+func resyncAfter(period time.Duration) ctrl.Result {
+	if period <= 0 {
+		period = DefaultResyncPeriod
+	}
+	return ctrl.Result{RequeueAfter: wait.Jitter(period, resyncJitter)}
+}
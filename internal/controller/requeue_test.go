@@ -0,0 +1,81 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+)
+
+var _ = Describe("Requeue", func() {
+	DescribeTable("resyncing after a period with jitter",
+		func(period time.Duration, want time.Duration) {
+			for range 100 {
+				result := resyncAfter(period)
+				Expect(result.Requeue).To(BeFalse())
+				Expect(result.RequeueAfter).To(BeNumerically(">=", want))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", time.Duration(float64(want)*(1+resyncJitter))))
+			}
+		},
+		Entry("with a configured period", time.Hour, time.Hour),
+		Entry("without a period", time.Duration(0), DefaultResyncPeriod),
+		Entry("with a negative period", -time.Minute, DefaultResyncPeriod),
+	)
+
+	It("should spread the resyncs of resources with the same period", func() {
+		periods := map[time.Duration]bool{}
+		for range 20 {
+			periods[resyncAfter(time.Hour).RequeueAfter] = true
+		}
+		Expect(len(periods)).To(BeNumerically(">", 1))
+	})
+
+	DescribeTable("filtering ApiVersion updates",
+		func(update func(*apimv1alpha1.ApiVersion), want bool) {
+			old := &apimv1alpha1.ApiVersion{ObjectMeta: metav1.ObjectMeta{
+				Name:        "orders-v1",
+				Namespace:   "default",
+				Generation:  1,
+				Labels:      map[string]string{"team": "orders"},
+				Annotations: map[string]string{"owner": "orders@example.com"},
+			}}
+			updated := old.DeepCopy()
+			update(updated)
+			Expect(apiVersionPredicate.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})).To(Equal(want))
+		},
+		Entry("skips status changes", func(apiVersion *apimv1alpha1.ApiVersion) {
+			apiVersion.Status.ProvisioningState = "Succeeded"
+			apiVersion.Status.ResumeToken = "resume-token"
+		}, false),
+		Entry("reconciles spec changes", func(apiVersion *apimv1alpha1.ApiVersion) {
+			apiVersion.Generation = 2
+		}, true),
+		Entry("reconciles annotation changes", func(apiVersion *apimv1alpha1.ApiVersion) {
+			apiVersion.Annotations[apimv1alpha1.ApproveRevisionAnnotation] = "2"
+		}, true),
+		Entry("reconciles label changes", func(apiVersion *apimv1alpha1.ApiVersion) {
+			apiVersion.Labels["team"] = "payments"
+		}, true),
+	)
+})
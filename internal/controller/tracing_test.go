@@ -0,0 +1,91 @@
+/*
+Copyright 2024 tjololo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apimv1alpha1 "github.com/tjololo/stilas-az/api/v1alpha1"
+	"github.com/tjololo/stilas-az/internal/azure/apimfake"
+)
+
+// spanRecorder records the spans of all specs since the global tracer provider can only be replaced once. Specs look up
+// the spans below their own parent span.
+var spanRecorder = tracetest.NewSpanRecorder()
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+}
+
+var _ = Describe("Tracing", func() {
+	childSpans := func(parent trace.SpanContext, name string) []sdktrace.ReadOnlySpan {
+		var spans []sdktrace.ReadOnlySpan
+		for _, span := range spanRecorder.Ended() {
+			if span.Name() == name && span.Parent().SpanID() == parent.SpanID() {
+				spans = append(spans, span)
+			}
+		}
+		return spans
+	}
+
+	It("should trace a reconcile down to the HTTP requests sent to Azure", func() {
+		setAzureEnv()
+		server := apimfake.NewServer()
+		DeferCleanup(server.Close)
+		k8s := newFakeClient(&apimv1alpha1.Backend{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default", Generation: 1, Finalizers: []string{"backend.finalizers.stilas.418.cloud"}},
+			Spec:       apimv1alpha1.BackendSpec{Title: "Orders", Url: "https://orders.example.com"},
+		})
+		reconciler := &BackendReconciler{Client: k8s, Scheme: k8s.Scheme(), NewClient: server.NewClient, Recorder: record.NewFakeRecorder(10)}
+
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "test")
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "orders", Namespace: "default"}})
+		parent.End()
+		Expect(err).NotTo(HaveOccurred())
+
+		reconciles := childSpans(parent.SpanContext(), "BackendReconciler.Reconcile")
+		Expect(reconciles).To(HaveLen(1))
+		Expect(reconciles[0].Attributes()).To(ContainElements(
+			attribute.String("k8s.namespace.name", "default"),
+			attribute.String("k8s.object.name", "orders"),
+		))
+
+		By("Tracing each APIM call below the reconcile")
+		gets := childSpans(reconciles[0].SpanContext(), "APIMClient.GetBackend")
+		Expect(gets).To(HaveLen(1))
+		Expect(childSpans(reconciles[0].SpanContext(), "APIMClient.CreateUpdateBackend")).To(HaveLen(1))
+
+		By("Tracing the HTTP requests below the APIM call")
+		requests := childSpans(gets[0].SpanContext(), "HTTP GET")
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].SpanKind()).To(Equal(trace.SpanKindClient))
+		Expect(requests[0].Attributes()).To(ContainElement(attribute.Int("http.response.status_code", 404)))
+	})
+})
@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GraphQLSchema holds the fields of the object and interface types of a GraphQL schema
+type GraphQLSchema struct {
+	fields map[string]map[string]bool
+}
+
+// HasField returns true if the object or interface type typeName has the field
+func (s *GraphQLSchema) HasField(typeName string, field string) bool {
+	return s.fields[typeName][field]
+}
+
+// HasType returns true if the schema defines the object or interface type typeName
+func (s *GraphQLSchema) HasType(typeName string) bool {
+	_, ok := s.fields[typeName]
+	return ok
+}
+
+// ParseGraphQLSchema reads the fields of the object and interface types, including type extensions, from a schema
+// in GraphQL schema definition language. Descriptions, arguments and directives are skipped and the SDL is otherwise
+// only checked for balanced brackets and terminated strings.
+func ParseGraphQLSchema(sdl string) (*GraphQLSchema, error) {
+	tokens, err := graphQLTokens(sdl)
+	if err != nil {
+		return nil, err
+	}
+	schema := &GraphQLSchema{fields: make(map[string]map[string]bool)}
+	for i := 0; i < len(tokens); {
+		switch tokens[i] {
+		case "type", "interface":
+			if i+1 >= len(tokens) || !isGraphQLName(tokens[i+1]) {
+				return nil, fmt.Errorf("expected a type name after %q", tokens[i])
+			}
+			typeName := tokens[i+1]
+			if schema.fields[typeName] == nil {
+				schema.fields[typeName] = make(map[string]bool)
+			}
+			i, err = parseGraphQLTypeBody(tokens, i+2, schema.fields[typeName])
+			if err != nil {
+				return nil, fmt.Errorf("type %s: %w", typeName, err)
+			}
+		case "{", "(", "[":
+			i, err = skipGraphQLBlock(tokens, i)
+			if err != nil {
+				return nil, err
+			}
+		case "}", ")", "]":
+			return nil, fmt.Errorf("unexpected %q", tokens[i])
+		default:
+			i++
+		}
+	}
+	return schema, nil
+}
+
+// parseGraphQLTypeBody adds the fields of the type definition starting after the type name at i and returns the index
+// of the token after the definition. A definition without a field list, such as an extension adding directives, ends
+// at the next definition.
+func parseGraphQLTypeBody(tokens []string, i int, fields map[string]bool) (int, error) {
+	var err error
+	for i < len(tokens) && tokens[i] != "{" {
+		switch tokens[i] {
+		case "(", "[":
+			if i, err = skipGraphQLBlock(tokens, i); err != nil {
+				return i, err
+			}
+		case "type", "interface", "input", "enum", "scalar", "union", "directive", "schema", "extend":
+			return i, nil
+		default:
+			i++
+		}
+	}
+	if i == len(tokens) {
+		return i, nil
+	}
+	for i++; i < len(tokens); {
+		switch tokens[i] {
+		case "}":
+			return i + 1, nil
+		case "(", "[":
+			if i, err = skipGraphQLBlock(tokens, i); err != nil {
+				return i, err
+			}
+		case "@":
+			// skip the directive name so a directive with arguments is not read as a field
+			i += 2
+		case "{", ")", "]":
+			return i, fmt.Errorf("unexpected %q in field list", tokens[i])
+		default:
+			if isGraphQLName(tokens[i]) && i+1 < len(tokens) && (tokens[i+1] == ":" || tokens[i+1] == "(") {
+				fields[tokens[i]] = true
+			}
+			i++
+		}
+	}
+	return i, fmt.Errorf("unterminated field list")
+}
+
+// skipGraphQLBlock returns the index of the token after the bracket opened at i and closed by its matching bracket
+func skipGraphQLBlock(tokens []string, i int) (int, error) {
+	closing := map[string]string{"{": "}", "(": ")", "[": "]"}
+	var stack []string
+	for ; i < len(tokens); i++ {
+		token := tokens[i]
+		if c, ok := closing[token]; ok {
+			stack = append(stack, c)
+			continue
+		}
+		if token == "}" || token == ")" || token == "]" {
+			if len(stack) == 0 || stack[len(stack)-1] != token {
+				return i, fmt.Errorf("unexpected %q", token)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return i, fmt.Errorf("missing %q", stack[len(stack)-1])
+}
+
+// graphQLTokens splits the SDL into names, numbers and punctuators. Comments, commas and strings are dropped and any
+// other character is an error.
+func graphQLTokens(sdl string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(sdl); {
+		c := sdl[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(sdl) && sdl[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(sdl[i:], `"""`):
+			end := strings.Index(sdl[i+3:], `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated block string")
+			}
+			i += end + 6
+		case c == '"':
+			i++
+			for i < len(sdl) && sdl[i] != '"' && sdl[i] != '\n' {
+				if sdl[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(sdl) || sdl[i] != '"' {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+		case isGraphQLNameStart(c):
+			start := i
+			for i < len(sdl) && isGraphQLNameChar(sdl[i]) {
+				i++
+			}
+			tokens = append(tokens, sdl[start:i])
+		case isGraphQLDigit(c) || (c == '-' && i+1 < len(sdl) && isGraphQLDigit(sdl[i+1])):
+			start := i
+			for i++; i < len(sdl) && (isGraphQLNameChar(sdl[i]) || strings.IndexByte(".+-", sdl[i]) >= 0); i++ {
+			}
+			tokens = append(tokens, sdl[start:i])
+		case strings.HasPrefix(sdl[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.IndexByte(graphQLPunctuators, c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+// graphQLPunctuators are the single character punctuators of the GraphQL grammar
+const graphQLPunctuators = "!$&()=:@[]{|}"
+
+// isGraphQLName returns true if the token is a name, matching /[_A-Za-z][_0-9A-Za-z]*/
+func isGraphQLName(token string) bool {
+	return token != "" && isGraphQLNameStart(token[0])
+}
+
+func isGraphQLNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isGraphQLNameChar(c byte) bool {
+	return isGraphQLNameStart(c) || isGraphQLDigit(c)
+}
+
+func isGraphQLDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"testing"
+)
+
+const testSchema = `
+"""
+The root query
+"""
+type Query {
+  "A single book"
+  book(id: ID!, format: String = "{json}"): Book @deprecated(reason: "use books")
+  books(first: Int = 10, minRating: Float = -0.5e1): [Book!]!
+}
+
+# mutations are defined in an extension
+extend type Mutation @tag(name: "write")
+
+type Book implements Node & Entity @key(fields: "id") {
+  id: ID!
+  title: String
+  type: String
+}
+
+interface Node {
+  id: ID!
+}
+
+input BookInput {
+  title: String
+}
+
+enum Format { JSON, XML }
+
+union SearchResult = Book | Author
+
+extend type Mutation {
+  addBook(book: BookInput!): Book
+}
+
+schema {
+  query: Query
+  mutation: Mutation
+}
+`
+
+func TestParseGraphQLSchema(t *testing.T) {
+	schema, err := ParseGraphQLSchema(testSchema)
+	if err != nil {
+		t.Fatalf("ParseGraphQLSchema() error = %v", err)
+	}
+	tests := []struct {
+		typeName string
+		field    string
+		want     bool
+	}{
+		{"Query", "book", true},
+		{"Query", "books", true},
+		{"Query", "reason", false},
+		{"Query", "first", false},
+		{"Mutation", "addBook", true},
+		{"Book", "title", true},
+		{"Book", "type", true},
+		{"Book", "fields", false},
+		{"Node", "id", true},
+		{"BookInput", "title", false},
+		{"Format", "JSON", false},
+		{"Subscription", "onBook", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typeName+"."+tt.field, func(t *testing.T) {
+			if got := schema.HasField(tt.typeName, tt.field); got != tt.want {
+				t.Errorf("HasField(%q, %q) = %v, want %v", tt.typeName, tt.field, got, tt.want)
+			}
+		})
+	}
+	if !schema.HasType("Mutation") || schema.HasType("BookInput") {
+		t.Errorf("HasType() returned types other than object and interface types")
+	}
+}
+
+func TestParseGraphQLSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sdl  string
+	}{
+		{"unterminated field list", "type Query { book: Book"},
+		{"unterminated arguments", "type Query { book(id: ID: Book }"},
+		{"unbalanced bracket", "type Query { books: [Book }"},
+		{"unexpected closing brace", "type Query { book: Book } }"},
+		{"unterminated string", `type Query { "a book book: Book }`},
+		{"unterminated block string", `"""the root type Query { book: Book }`},
+		{"missing type name", "type { book: Book }"},
+		{"dash in field name", "type Query { book-title: Book }"},
+		{"dot in field name", "type Query { book.title: Book }"},
+		{"dash in type name", "type Book-Type { id: ID! }"},
+		{"invalid character", "type Query { book: Book% }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseGraphQLSchema(tt.sdl); err == nil {
+				t.Errorf("ParseGraphQLSchema() expected an error")
+			}
+		})
+	}
+}
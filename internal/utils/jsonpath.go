@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var jsonPathIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$-]*$`)
+
+// JSONPathNode is a value selected by a JSONPath expression and the normalized path of the value
+type JSONPathNode struct {
+	Path  string
+	Value any
+}
+
+// jsonPathSelector selects the children of a node. An empty names and index -1 selects all children.
+type jsonPathSelector struct {
+	recursive bool
+	names     []string
+	index     int
+}
+
+// JSONPath is a compiled JSONPath expression supporting the root $, child .name and ['name'], wildcard .* and [*],
+// index [n], union ['a','b'] and recursive descent ..name and ..* selectors. Filters and slices are not supported.
+//
+// k8s.io/client-go/util/jsonpath is not used since it only returns the selected values. Lint findings report the
+// location of each value, which needs the normalized path of every node. It also parses kubectl templates, where a
+// plain $.paths.* is printed as text unless it is wrapped in braces, and does not guarantee the order of object keys.
+type JSONPath struct {
+	selectors []jsonPathSelector
+}
+
+// CompileJSONPath parses a JSONPath expression
+func CompileJSONPath(expression string) (*JSONPath, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return nil, fmt.Errorf("JSONPath %q must start with $", expression)
+	}
+	path := &JSONPath{}
+	rest := expression[1:]
+	for rest != "" {
+		selector := jsonPathSelector{index: -1}
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			selector.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			if name == "" {
+				return nil, fmt.Errorf("JSONPath %q has an empty name", expression)
+			}
+			if strings.ContainsAny(name, `]'"`) {
+				return nil, fmt.Errorf("JSONPath %q has a name %q that must be quoted in brackets", expression, name)
+			}
+			if name != "*" {
+				selector.names = []string{name}
+			}
+			path.selectors = append(path.selectors, selector)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return nil, fmt.Errorf("JSONPath %q has an unexpected %q", expression, rest[:1])
+		}
+		end := strings.Index(rest, "]")
+		if end == -1 {
+			return nil, fmt.Errorf("JSONPath %q has an unterminated [", expression)
+		}
+		if err := selector.parseBracket(rest[1:end]); err != nil {
+			return nil, fmt.Errorf("JSONPath %q: %w", expression, err)
+		}
+		rest = rest[end+1:]
+		path.selectors = append(path.selectors, selector)
+	}
+	return path, nil
+}
+
+// parseBracket parses the content of a bracket selector. Names in brackets can not contain ] or commas.
+func (s *jsonPathSelector) parseBracket(content string) error {
+	content = strings.TrimSpace(content)
+	if content == "*" {
+		return nil
+	}
+	if index, err := strconv.Atoi(content); err == nil {
+		if index < 0 {
+			return fmt.Errorf("negative index %d is not supported", index)
+		}
+		s.index = index
+		return nil
+	}
+	for _, part := range strings.Split(content, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) < 2 || (part[0] != '\'' && part[0] != '"') || part[len(part)-1] != part[0] {
+			return fmt.Errorf("expected a quoted name, an index or * in [%s]", content)
+		}
+		s.names = append(s.names, part[1:len(part)-1])
+	}
+	return nil
+}
+
+// Select returns the nodes of the document selected by the expression ordered by document position, with the keys
+// of objects in sorted order
+func (p *JSONPath) Select(document any) []JSONPathNode {
+	nodes := []JSONPathNode{{Path: "$", Value: document}}
+	for _, selector := range p.selectors {
+		var selected []JSONPathNode
+		for _, node := range nodes {
+			if selector.recursive {
+				for _, descendant := range descendants(node) {
+					selected = append(selected, selector.children(descendant)...)
+				}
+			} else {
+				selected = append(selected, selector.children(node)...)
+			}
+		}
+		nodes = selected
+	}
+	return nodes
+}
+
+func (s jsonPathSelector) children(node JSONPathNode) []JSONPathNode {
+	var children []JSONPathNode
+	switch value := node.Value.(type) {
+	case map[string]any:
+		if s.index != -1 {
+			return nil
+		}
+		names := s.names
+		if names == nil {
+			names = sortedKeys(value)
+		}
+		for _, name := range names {
+			if child, ok := value[name]; ok {
+				children = append(children, JSONPathNode{Path: node.Path + jsonPathName(name), Value: child})
+			}
+		}
+	case []any:
+		if s.names != nil {
+			return nil
+		}
+		for i, child := range value {
+			if s.index == -1 || s.index == i {
+				children = append(children, JSONPathNode{Path: fmt.Sprintf("%s[%d]", node.Path, i), Value: child})
+			}
+		}
+	}
+	return children
+}
+
+// descendants returns the node and all nodes below it
+func descendants(node JSONPathNode) []JSONPathNode {
+	nodes := []JSONPathNode{node}
+	for _, child := range (jsonPathSelector{index: -1}).children(node) {
+		nodes = append(nodes, descendants(child)...)
+	}
+	return nodes
+}
+
+func jsonPathName(name string) string {
+	if jsonPathIdentifier.MatchString(name) {
+		return "." + name
+	}
+	return "['" + strings.ReplaceAll(name, "'", `\'`) + "']"
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
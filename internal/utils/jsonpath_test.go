@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func jsonPathDocument() any {
+	return map[string]any{
+		"info": map[string]any{"title": "Orders"},
+		"paths": map[string]any{
+			"/orders": map[string]any{
+				"get": map[string]any{
+					"operationId": "listOrders",
+					"parameters":  []any{map[string]any{"name": "limit"}, map[string]any{"name": "offset"}},
+				},
+				"post": map[string]any{"operationId": "createOrder"},
+			},
+		},
+		"tags":          []any{map[string]any{"name": "orders"}},
+		"x-it's-quoted": true,
+	}
+}
+
+func TestJSONPathSelect(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       []JSONPathNode
+	}{
+		{"root", "$", []JSONPathNode{{"$", jsonPathDocument()}}},
+		{"child", "$.info.title", []JSONPathNode{{"$.info.title", "Orders"}}},
+		{"bracket child", `$['info']["title"]`, []JSONPathNode{{"$.info.title", "Orders"}}},
+		{"bracket child needing quotes", "$.paths['/orders'].post.operationId", []JSONPathNode{{"$.paths['/orders'].post.operationId", "createOrder"}}},
+		{"quoted name with quote", `$["x-it's-quoted"]`, []JSONPathNode{{`$['x-it\'s-quoted']`, true}}},
+		{"wildcard", "$.paths.*.*.operationId", []JSONPathNode{
+			{"$.paths['/orders'].get.operationId", "listOrders"},
+			{"$.paths['/orders'].post.operationId", "createOrder"},
+		}},
+		{"bracket wildcard", "$.paths[*][ * ].operationId", []JSONPathNode{
+			{"$.paths['/orders'].get.operationId", "listOrders"},
+			{"$.paths['/orders'].post.operationId", "createOrder"},
+		}},
+		{"union in union order", "$.paths['/orders']['post', \"get\"].operationId", []JSONPathNode{
+			{"$.paths['/orders'].post.operationId", "createOrder"},
+			{"$.paths['/orders'].get.operationId", "listOrders"},
+		}},
+		{"union with missing name", "$.info['title','version']", []JSONPathNode{{"$.info.title", "Orders"}}},
+		{"index", "$.paths['/orders'].get.parameters[1].name", []JSONPathNode{{"$.paths['/orders'].get.parameters[1].name", "offset"}}},
+		{"array wildcard", "$.paths['/orders'].get.parameters[*].name", []JSONPathNode{
+			{"$.paths['/orders'].get.parameters[0].name", "limit"},
+			{"$.paths['/orders'].get.parameters[1].name", "offset"},
+		}},
+		{"recursive descent", "$..name", []JSONPathNode{
+			{"$.paths['/orders'].get.parameters[0].name", "limit"},
+			{"$.paths['/orders'].get.parameters[1].name", "offset"},
+			{"$.tags[0].name", "orders"},
+		}},
+		{"recursive descent with bracket", "$..[0].name", []JSONPathNode{
+			{"$.paths['/orders'].get.parameters[0].name", "limit"},
+			{"$.tags[0].name", "orders"},
+		}},
+		{"recursive wildcard", "$.info..*", []JSONPathNode{{"$.info.title", "Orders"}}},
+		{"recursive descent below a selector", "$.paths..operationId", []JSONPathNode{
+			{"$.paths['/orders'].get.operationId", "listOrders"},
+			{"$.paths['/orders'].post.operationId", "createOrder"},
+		}},
+		{"missing name", "$.info.version", nil},
+		{"index out of range", "$.tags[1]", nil},
+		{"index of an object", "$.info[0]", nil},
+		{"name of an array", "$.tags.name", nil},
+		{"child of a value", "$.info.title.length", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := CompileJSONPath(tt.expression)
+			if err != nil {
+				t.Fatalf("CompileJSONPath() error = %v", err)
+			}
+			if got := path.Select(jsonPathDocument()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{"relative path", "paths"},
+		{"empty", ""},
+		{"trailing dot", "$.paths."},
+		{"trailing recursive descent", "$.."},
+		{"missing dot", "$paths"},
+		{"unexpected character", "$.paths]"},
+		{"quote in name", "$.paths.'orders'"},
+		{"unterminated bracket", "$.paths['/orders'"},
+		{"empty bracket", "$.paths[]"},
+		{"negative index", "$.tags[-1]"},
+		{"unquoted name", "$.paths[orders]"},
+		{"mismatched quotes", `$.paths['orders"]`},
+		{"single quote", "$.paths[']"},
+		{"unquoted name in union", "$.paths['get',post]"},
+		{"empty name in union", "$.paths['get',]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if path, err := CompileJSONPath(tt.expression); err == nil {
+				t.Errorf("CompileJSONPath() = %v, want error", path)
+			}
+		})
+	}
+}
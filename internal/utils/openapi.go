@@ -0,0 +1,326 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Kinds of breaking changes found by OpenAPIBreakingChanges
+const (
+	BreakingChangeRemovedPath                  = "RemovedPath"
+	BreakingChangeRemovedOperation             = "RemovedOperation"
+	BreakingChangeRemovedRequiredResponseField = "RemovedRequiredResponseField"
+	BreakingChangeNewRequiredParameter         = "NewRequiredParameter"
+)
+
+// maxSchemaDepth limits how deep nested and recursive schemas are followed when collecting required fields
+const maxSchemaDepth = 16
+
+var (
+	openAPIMethods       = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+	openAPIPathParameter = regexp.MustCompile(`\{[^}]*}`)
+)
+
+// BreakingChange is a change between two versions of an OpenAPI document that breaks existing clients
+type BreakingChange struct {
+	// Type is one of the BreakingChange constants
+	Type string
+	// Location is the path, or the method and path of the operation, that changed
+	Location string
+	Message  string
+}
+
+// OpenAPIBreakingChanges compares two OpenAPI 3 or Swagger 2 documents in YAML or JSON and returns the changes in
+// current that break clients of previous: removed paths and operations, response fields that are no longer required
+// and parameters, request bodies and request body fields that are new or became required. Local references are
+// resolved, paths are matched regardless of the names of their path parameters and the changes are ordered by path and
+// operation.
+func OpenAPIBreakingChanges(previous string, current string) ([]BreakingChange, error) {
+	previousDoc, err := parseOpenAPI(previous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse previous document: %w", err)
+	}
+	currentDoc, err := parseOpenAPI(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse current document: %w", err)
+	}
+	currentPaths := make(map[string]string)
+	for path := range asMap(currentDoc.root["paths"]) {
+		currentPaths[normalizePath(path)] = path
+	}
+	var changes []BreakingChange
+	previousPaths := asMap(previousDoc.root["paths"])
+	for _, path := range sortedKeys(previousPaths) {
+		currentPath, ok := currentPaths[normalizePath(path)]
+		if !ok {
+			changes = append(changes, BreakingChange{
+				Type:     BreakingChangeRemovedPath,
+				Location: path,
+				Message:  fmt.Sprintf("path %s was removed", path),
+			})
+			continue
+		}
+		previousItem := previousDoc.resolve(previousPaths[path])
+		currentItem := currentDoc.resolve(asMap(currentDoc.root["paths"])[currentPath])
+		for _, method := range openAPIMethods {
+			previousOperation, ok := previousItem[method]
+			if !ok {
+				continue
+			}
+			location := strings.ToUpper(method) + " " + currentPath
+			currentOperation, ok := currentItem[method]
+			if !ok {
+				changes = append(changes, BreakingChange{
+					Type:     BreakingChangeRemovedOperation,
+					Location: location,
+					Message:  fmt.Sprintf("operation %s %s was removed", strings.ToUpper(method), path),
+				})
+				continue
+			}
+			previousOp := operation{doc: previousDoc, item: previousItem, op: previousDoc.resolve(previousOperation)}
+			currentOp := operation{doc: currentDoc, item: currentItem, op: currentDoc.resolve(currentOperation)}
+			changes = append(changes, parameterChanges(location, previousOp, currentOp)...)
+			changes = append(changes, responseChanges(location, previousOp, currentOp)...)
+		}
+	}
+	return changes, nil
+}
+
+type openAPIDocument struct {
+	root map[string]any
+}
+
+func parseOpenAPI(document string) (*openAPIDocument, error) {
+	var root map[string]any
+	if err := yaml.Unmarshal([]byte(document), &root); err != nil {
+		return nil, err
+	}
+	if _, ok := root["openapi"]; !ok {
+		if _, ok := root["swagger"]; !ok {
+			return nil, fmt.Errorf("document has neither an openapi nor a swagger version")
+		}
+	}
+	return &openAPIDocument{root: root}, nil
+}
+
+// resolve follows local references of the node. References to other documents and references that can not be
+// resolved are returned as an empty node.
+func (d *openAPIDocument) resolve(node any) map[string]any {
+	m := asMap(node)
+	for i := 0; i < maxSchemaDepth; i++ {
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil
+		}
+		var target any = d.root
+		for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			target = asMap(target)[token]
+		}
+		m = asMap(target)
+	}
+	return nil
+}
+
+type operation struct {
+	doc  *openAPIDocument
+	item map[string]any
+	op   map[string]any
+}
+
+// requiredParameters returns the required parameters of the operation, including the parameters of its path, keyed by
+// location and name. Path parameters are left out since they are matched with the path and body parameters are
+// keyed by location only since their name is not sent.
+func (o operation) requiredParameters() map[string]bool {
+	parameters := make(map[string]bool)
+	for _, list := range []any{o.item["parameters"], o.op["parameters"]} {
+		items, _ := list.([]any)
+		for _, item := range items {
+			parameter := o.doc.resolve(item)
+			in, _ := parameter["in"].(string)
+			name, _ := parameter["name"].(string)
+			if in == "path" {
+				continue
+			}
+			if in == "body" {
+				name = ""
+			}
+			required, _ := parameter["required"].(bool)
+			parameters[in+":"+name] = required
+		}
+	}
+	return parameters
+}
+
+// bodySchemas returns the schemas of the request body by media type. Swagger 2 body parameters are returned with an
+// empty media type.
+func (o operation) bodySchemas() (map[string]any, bool) {
+	if body, ok := o.op["requestBody"]; ok {
+		requestBody := o.doc.resolve(body)
+		required, _ := requestBody["required"].(bool)
+		return mediaTypeSchemas(o.doc, requestBody), required
+	}
+	for _, list := range []any{o.item["parameters"], o.op["parameters"]} {
+		items, _ := list.([]any)
+		for _, item := range items {
+			parameter := o.doc.resolve(item)
+			if parameter["in"] == "body" {
+				required, _ := parameter["required"].(bool)
+				return map[string]any{"": parameter["schema"]}, required
+			}
+		}
+	}
+	return nil, false
+}
+
+func mediaTypeSchemas(doc *openAPIDocument, node map[string]any) map[string]any {
+	if schema, ok := node["schema"]; ok {
+		return map[string]any{"": schema}
+	}
+	schemas := make(map[string]any)
+	for mediaType, content := range asMap(node["content"]) {
+		if schema, ok := doc.resolve(content)["schema"]; ok {
+			schemas[mediaType] = schema
+		}
+	}
+	return schemas
+}
+
+func parameterChanges(location string, previous operation, current operation) []BreakingChange {
+	var changes []BreakingChange
+	previousParameters := previous.requiredParameters()
+	currentParameters := current.requiredParameters()
+	for _, key := range sortedKeys(currentParameters) {
+		if currentParameters[key] && !previousParameters[key] && key != "body:" {
+			in, name, _ := strings.Cut(key, ":")
+			changes = append(changes, BreakingChange{
+				Type:     BreakingChangeNewRequiredParameter,
+				Location: location,
+				Message:  fmt.Sprintf("%s parameter %s is required", in, name),
+			})
+		}
+	}
+	previousBodies, previousRequired := previous.bodySchemas()
+	currentBodies, currentRequired := current.bodySchemas()
+	if currentRequired && !previousRequired {
+		changes = append(changes, BreakingChange{
+			Type:     BreakingChangeNewRequiredParameter,
+			Location: location,
+			Message:  "request body is required",
+		})
+		return changes
+	}
+	for _, mediaType := range sortedKeys(currentBodies) {
+		previousSchema, ok := previousBodies[mediaType]
+		if !ok {
+			continue
+		}
+		previousFields := make(map[string]bool)
+		previous.doc.requiredFields(previousSchema, "", 0, previousFields)
+		currentFields := make(map[string]bool)
+		current.doc.requiredFields(currentBodies[mediaType], "", 0, currentFields)
+		for _, field := range sortedKeys(currentFields) {
+			if !previousFields[field] {
+				changes = append(changes, BreakingChange{
+					Type:     BreakingChangeNewRequiredParameter,
+					Location: location,
+					Message:  fmt.Sprintf("request body field %s is required%s", field, mediaTypeSuffix(mediaType)),
+				})
+			}
+		}
+	}
+	return changes
+}
+
+func responseChanges(location string, previous operation, current operation) []BreakingChange {
+	var changes []BreakingChange
+	previousResponses := asMap(previous.op["responses"])
+	currentResponses := asMap(current.op["responses"])
+	for _, status := range sortedKeys(previousResponses) {
+		currentResponse, ok := currentResponses[status]
+		if !ok {
+			continue
+		}
+		previousSchemas := mediaTypeSchemas(previous.doc, previous.doc.resolve(previousResponses[status]))
+		currentSchemas := mediaTypeSchemas(current.doc, current.doc.resolve(currentResponse))
+		for _, mediaType := range sortedKeys(previousSchemas) {
+			currentSchema, ok := currentSchemas[mediaType]
+			if !ok {
+				continue
+			}
+			previousFields := make(map[string]bool)
+			previous.doc.requiredFields(previousSchemas[mediaType], "", 0, previousFields)
+			currentFields := make(map[string]bool)
+			current.doc.requiredFields(currentSchema, "", 0, currentFields)
+			for _, field := range sortedKeys(previousFields) {
+				if !currentFields[field] {
+					changes = append(changes, BreakingChange{
+						Type:     BreakingChangeRemovedRequiredResponseField,
+						Location: location,
+						Message:  fmt.Sprintf("response %s field %s is no longer required%s", status, field, mediaTypeSuffix(mediaType)),
+					})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// requiredFields adds the paths of the required properties of the schema and its nested schemas to fields. Array items
+// are written as field[] and the schemas of allOf are merged.
+func (d *openAPIDocument) requiredFields(node any, prefix string, depth int, fields map[string]bool) {
+	if depth > maxSchemaDepth {
+		return
+	}
+	schema := d.resolve(node)
+	for _, part := range asSlice(schema["allOf"]) {
+		d.requiredFields(part, prefix, depth+1, fields)
+	}
+	if items, ok := schema["items"]; ok {
+		d.requiredFields(items, prefix+"[]", depth+1, fields)
+	}
+	for _, name := range asSlice(schema["required"]) {
+		if field, ok := name.(string); ok {
+			fields[joinField(prefix, field)] = true
+		}
+	}
+	properties := asMap(schema["properties"])
+	for _, name := range sortedKeys(properties) {
+		d.requiredFields(properties[name], joinField(prefix, name), depth+1, fields)
+	}
+}
+
+func joinField(prefix string, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
+
+func mediaTypeSuffix(mediaType string) string {
+	if mediaType == "" {
+		return ""
+	}
+	return " for " + mediaType
+}
+
+// normalizePath removes the names of the path parameters so renaming a parameter does not remove the path
+func normalizePath(path string) string {
+	return openAPIPathParameter.ReplaceAllString(path, "{}")
+}
+
+func asMap(node any) map[string]any {
+	m, _ := node.(map[string]any)
+	return m
+}
+
+func asSlice(node any) []any {
+	s, _ := node.([]any)
+	return s
+}
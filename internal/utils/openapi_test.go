@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+const previousOpenAPI = `
+openapi: 3.0.1
+info:
+  title: Orders
+  version: v1
+paths:
+  /orders:
+    get:
+      parameters:
+        - name: page
+          in: query
+      responses:
+        "200":
+          description: The orders
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Order'
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order'
+      responses:
+        "201":
+          description: Created
+  /orders/{orderId}:
+    parameters:
+      - name: orderId
+        in: path
+        required: true
+    get:
+      responses:
+        "200":
+          description: The order
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+    delete:
+      responses:
+        "204":
+          description: Deleted
+  /customers:
+    get:
+      responses:
+        "200":
+          description: The customers
+components:
+  schemas:
+    Order:
+      type: object
+      required: [id, total]
+      properties:
+        id:
+          type: string
+        total:
+          type: number
+        customer:
+          type: object
+          required: [name]
+          properties:
+            name:
+              type: string
+`
+
+func TestOpenAPIBreakingChangesUnchanged(t *testing.T) {
+	changes, err := OpenAPIBreakingChanges(previousOpenAPI, previousOpenAPI)
+	if err != nil {
+		t.Fatalf("OpenAPIBreakingChanges() error = %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("OpenAPIBreakingChanges() = %v, want no changes", changes)
+	}
+}
+
+func TestOpenAPIBreakingChanges(t *testing.T) {
+	current := `{
+  "openapi": "3.0.1",
+  "info": {"title": "Orders", "version": "v1"},
+  "paths": {
+    "/orders": {
+      "get": {
+        "parameters": [{"name": "page", "in": "query", "required": true}, {"name": "size", "in": "query"}],
+        "responses": {"200": {"description": "The orders", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Order"}}}}}}
+      },
+      "post": {
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/NewOrder"}}}},
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/orders/{id}": {
+      "get": {
+        "parameters": [{"name": "id", "in": "path", "required": true}],
+        "responses": {"200": {"description": "The order", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Order"}}}}}
+      }
+    },
+    "/products": {
+      "get": {"parameters": [{"name": "category", "in": "query", "required": true}], "responses": {"200": {"description": "The products"}}}
+    }
+  },
+  "components": {
+    "schemas": {
+      "Order": {
+        "type": "object",
+        "required": ["id"],
+        "properties": {"id": {"type": "string"}, "total": {"type": "number"}, "customer": {"type": "object", "properties": {"name": {"type": "string"}}}}
+      },
+      "NewOrder": {
+        "allOf": [{"$ref": "#/components/schemas/Order"}, {"required": ["currency"]}]
+      }
+    }
+  }
+}`
+	changes, err := OpenAPIBreakingChanges(previousOpenAPI, current)
+	if err != nil {
+		t.Fatalf("OpenAPIBreakingChanges() error = %v", err)
+	}
+	want := []BreakingChange{
+		{BreakingChangeRemovedPath, "/customers", "path /customers was removed"},
+		{BreakingChangeNewRequiredParameter, "GET /orders", "query parameter page is required"},
+		{BreakingChangeRemovedRequiredResponseField, "GET /orders", "response 200 field [].customer.name is no longer required for application/json"},
+		{BreakingChangeRemovedRequiredResponseField, "GET /orders", "response 200 field [].total is no longer required for application/json"},
+		{BreakingChangeNewRequiredParameter, "POST /orders", "request body field currency is required for application/json"},
+		{BreakingChangeRemovedRequiredResponseField, "GET /orders/{id}", "response 200 field customer.name is no longer required for application/json"},
+		{BreakingChangeRemovedRequiredResponseField, "GET /orders/{id}", "response 200 field total is no longer required for application/json"},
+		{BreakingChangeRemovedOperation, "DELETE /orders/{id}", "operation DELETE /orders/{orderId} was removed"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("OpenAPIBreakingChanges() = %v, want %v", changes, want)
+	}
+}
+
+func TestOpenAPIBreakingChangesSwagger(t *testing.T) {
+	previous := `
+swagger: "2.0"
+paths:
+  /orders:
+    post:
+      parameters:
+        - name: order
+          in: body
+          schema:
+            type: object
+      responses:
+        "200":
+          description: The order
+          schema:
+            type: object
+            required: [id]
+`
+	current := `
+swagger: "2.0"
+paths:
+  /orders:
+    post:
+      parameters:
+        - name: newOrder
+          in: body
+          required: true
+          schema:
+            type: object
+        - name: X-Tenant
+          in: header
+          required: true
+      responses:
+        "200":
+          description: The order
+          schema:
+            type: object
+`
+	changes, err := OpenAPIBreakingChanges(previous, current)
+	if err != nil {
+		t.Fatalf("OpenAPIBreakingChanges() error = %v", err)
+	}
+	want := []BreakingChange{
+		{BreakingChangeNewRequiredParameter, "POST /orders", "header parameter X-Tenant is required"},
+		{BreakingChangeNewRequiredParameter, "POST /orders", "request body is required"},
+		{BreakingChangeRemovedRequiredResponseField, "POST /orders", "response 200 field id is no longer required"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("OpenAPIBreakingChanges() = %v, want %v", changes, want)
+	}
+}
+
+func TestOpenAPIBreakingChangesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+	}{
+		{"invalid previous document", "paths: [", previousOpenAPI},
+		{"invalid current document", previousOpenAPI, "{"},
+		{"not an OpenAPI document", previousOpenAPI, "paths: {}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := OpenAPIBreakingChanges(tt.previous, tt.current); err == nil {
+				t.Errorf("OpenAPIBreakingChanges() expected an error")
+			}
+		})
+	}
+}
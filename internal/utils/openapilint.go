@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Lint severities
+const (
+	LintSeverityError   = "Error"
+	LintSeverityWarning = "Warning"
+)
+
+// Names of the built-in lint rules
+const (
+	LintRuleOperationId            = "operation-operationid"
+	LintRuleOperationIdUnique      = "operation-operationid-unique"
+	LintRuleOperationDescription   = "operation-description"
+	LintRuleOperationSummaryUnique = "operation-summary-unique"
+)
+
+// LintRule checks the values selected by a JSONPath expression in an OpenAPI document
+type LintRule struct {
+	Name string
+	// Severity is LintSeverityError or LintSeverityWarning
+	Severity string
+	// Target is a JSONPath expression, see CompileJSONPath, selecting the values that are checked
+	Target string
+	// Field is a field the selected objects must have. Selected values that are not objects are skipped when it is set.
+	Field string
+	// Pattern is a regular expression the selected values, or their Field, must match
+	Pattern string
+	// Message replaces the default message of violations of the rule
+	Message string
+}
+
+// LintFinding is a violation of a lint rule
+type LintFinding struct {
+	Rule     string
+	Severity string
+	// Location is the JSONPath of the value violating the rule
+	Location string
+	Message  string
+}
+
+// Validate returns an error if the target or pattern of the rule can not be compiled
+func (r LintRule) Validate() error {
+	_, _, err := r.compile()
+	return err
+}
+
+func (r LintRule) compile() (*JSONPath, *regexp.Regexp, error) {
+	target, err := CompileJSONPath(r.Target)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rule %s: %w", r.Name, err)
+	}
+	if r.Pattern == "" {
+		return target, nil, nil
+	}
+	pattern, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rule %s: %w", r.Name, err)
+	}
+	return target, pattern, nil
+}
+
+// LintOpenAPI checks an OpenAPI 3 or Swagger 2 document in YAML or JSON with the built-in rules followed by rules.
+// Built-in rules report operations without an operationId, without a summary or description and operations sharing an
+// operationId or summary, which APIM uses as the name and display name of the operation. Rules named in disabled are
+// skipped. An error is returned if the document can not be parsed or a rule is invalid.
+func LintOpenAPI(document string, rules []LintRule, disabled []string) ([]LintFinding, error) {
+	doc, err := parseOpenAPI(document)
+	if err != nil {
+		return nil, err
+	}
+	skip := make(map[string]bool)
+	for _, name := range disabled {
+		skip[name] = true
+	}
+	var findings []LintFinding
+	for _, finding := range lintOperations(doc) {
+		if !skip[finding.Rule] {
+			findings = append(findings, finding)
+		}
+	}
+	for _, rule := range rules {
+		if skip[rule.Name] {
+			continue
+		}
+		ruleFindings, err := lintRule(doc, rule)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, ruleFindings...)
+	}
+	return findings, nil
+}
+
+// lintOperations applies the built-in rules to the operations of the document
+func lintOperations(doc *openAPIDocument) []LintFinding {
+	var findings []LintFinding
+	operationIds := make(map[string]string)
+	summaries := make(map[string]string)
+	paths := asMap(doc.root["paths"])
+	for _, path := range sortedKeys(paths) {
+		item := doc.resolve(paths[path])
+		for _, method := range openAPIMethods {
+			node, ok := item[method]
+			if !ok {
+				continue
+			}
+			operation := doc.resolve(node)
+			name := strings.ToUpper(method) + " " + path
+			location := "$.paths" + jsonPathName(path) + "." + method
+			operationId, _ := operation["operationId"].(string)
+			summary, _ := operation["summary"].(string)
+			description, _ := operation["description"].(string)
+			switch first, duplicate := operationIds[operationId]; {
+			case operationId == "":
+				findings = append(findings, LintFinding{
+					Rule:     LintRuleOperationId,
+					Severity: LintSeverityError,
+					Location: location,
+					Message:  name + " has no operationId",
+				})
+			case duplicate:
+				findings = append(findings, LintFinding{
+					Rule:     LintRuleOperationIdUnique,
+					Severity: LintSeverityError,
+					Location: location,
+					Message:  fmt.Sprintf("%s has operationId %s of %s", name, operationId, first),
+				})
+			default:
+				operationIds[operationId] = name
+			}
+			if summary == "" && description == "" {
+				findings = append(findings, LintFinding{
+					Rule:     LintRuleOperationDescription,
+					Severity: LintSeverityWarning,
+					Location: location,
+					Message:  name + " has neither a summary nor a description",
+				})
+			}
+			if summary == "" {
+				continue
+			}
+			if first, duplicate := summaries[summary]; duplicate {
+				findings = append(findings, LintFinding{
+					Rule:     LintRuleOperationSummaryUnique,
+					Severity: LintSeverityWarning,
+					Location: location,
+					Message:  fmt.Sprintf("%s has summary %q of %s", name, summary, first),
+				})
+			} else {
+				summaries[summary] = name
+			}
+		}
+	}
+	return findings
+}
+
+// lintRule applies a rule to the values selected by its target
+func lintRule(doc *openAPIDocument, rule LintRule) ([]LintFinding, error) {
+	target, pattern, err := rule.compile()
+	if err != nil {
+		return nil, err
+	}
+	var findings []LintFinding
+	violation := func(location string, message string) {
+		if rule.Message != "" {
+			message = rule.Message
+		}
+		findings = append(findings, LintFinding{Rule: rule.Name, Severity: rule.Severity, Location: location, Message: message})
+	}
+	for _, node := range target.Select(doc.root) {
+		value, location := node.Value, node.Path
+		if rule.Field != "" {
+			object, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			location += jsonPathName(rule.Field)
+			if value, ok = object[rule.Field]; !ok {
+				violation(location, rule.Field+" is missing")
+				continue
+			}
+		}
+		if pattern == nil {
+			continue
+		}
+		switch value.(type) {
+		case map[string]any, []any, nil:
+			violation(location, "value is not a string, number or boolean")
+		default:
+			if text := fmt.Sprint(value); !pattern.MatchString(text) {
+				violation(location, fmt.Sprintf("%q does not match %s", text, rule.Pattern))
+			}
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+const lintOpenAPI = `
+openapi: 3.0.1
+info:
+  title: Orders
+  version: v1
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      summary: List orders
+      x-owner: team-orders
+    post:
+      operationId: listOrders
+      summary: List orders
+  /orders/{orderId}:
+    get:
+      description: Returns an order
+      x-owner: Orders
+    delete:
+      operationId: deleteOrder
+      summary: Delete order
+`
+
+func TestLintOpenAPI(t *testing.T) {
+	rules := []LintRule{
+		{Name: "owner", Severity: LintSeverityError, Target: "$.paths.*['get','post','delete']", Field: "x-owner"},
+		{Name: "owner-format", Severity: LintSeverityWarning, Target: "$..x-owner", Pattern: "^team-", Message: "owners are teams"},
+	}
+	findings, err := LintOpenAPI(lintOpenAPI, rules, nil)
+	if err != nil {
+		t.Fatalf("LintOpenAPI() error = %v", err)
+	}
+	want := []LintFinding{
+		{LintRuleOperationIdUnique, LintSeverityError, "$.paths['/orders'].post", "POST /orders has operationId listOrders of GET /orders"},
+		{LintRuleOperationSummaryUnique, LintSeverityWarning, "$.paths['/orders'].post", `POST /orders has summary "List orders" of GET /orders`},
+		{LintRuleOperationId, LintSeverityError, "$.paths['/orders/{orderId}'].get", "GET /orders/{orderId} has no operationId"},
+		{"owner", LintSeverityError, "$.paths['/orders'].post.x-owner", "x-owner is missing"},
+		{"owner", LintSeverityError, "$.paths['/orders/{orderId}'].delete.x-owner", "x-owner is missing"},
+		{"owner-format", LintSeverityWarning, "$.paths['/orders/{orderId}'].get.x-owner", "owners are teams"},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("LintOpenAPI() = %v, want %v", findings, want)
+	}
+}
+
+func TestLintOpenAPIDisabledRules(t *testing.T) {
+	rules := []LintRule{{Name: "title", Severity: LintSeverityError, Target: "$.info.title", Pattern: "^[a-z]+$"}}
+	findings, err := LintOpenAPI(lintOpenAPI, rules, []string{LintRuleOperationId, LintRuleOperationIdUnique, LintRuleOperationSummaryUnique})
+	if err != nil {
+		t.Fatalf("LintOpenAPI() error = %v", err)
+	}
+	want := []LintFinding{
+		{"title", LintSeverityError, "$.info.title", `"Orders" does not match ^[a-z]+$`},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("LintOpenAPI() = %v, want %v", findings, want)
+	}
+}
+
+func TestLintRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    LintRule
+		wantErr bool
+	}{
+		{"valid", LintRule{Name: "valid", Target: "$..parameters[*].name", Pattern: "^[a-z]"}, false},
+		{"relative target", LintRule{Name: "relative", Target: "paths"}, true},
+		{"unterminated bracket", LintRule{Name: "bracket", Target: "$.paths['/orders'"}, true},
+		{"unquoted name", LintRule{Name: "unquoted", Target: "$.paths[orders]"}, true},
+		{"invalid pattern", LintRule{Name: "pattern", Target: "$.info", Pattern: "(["}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,36 +1,89 @@
 package utils
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
-func Sha256FromUrlContent(url string) (string, error) {
-	resp, err := http.Get(url)
+var tracer = otel.Tracer("github.com/tjololo/stilas-az/internal/utils")
+
+// contentClient downloads content referenced by URL. The timeout keeps an unresponsive server from blocking a reconcile.
+var contentClient = &http.Client{Timeout: 30 * time.Second}
+
+func Sha256FromUrlContent(ctx context.Context, url string) (string, error) {
+	ctx, span := tracer.Start(ctx, "Sha256FromUrlContent")
+	span.SetAttributes(attribute.String("url.full", url))
+	defer span.End()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+	resp, err := contentClient.Do(req)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return "", err
 	}
 	defer resp.Body.Close()
 
 	h := sha256.New()
 	if _, err := io.Copy(h, resp.Body); err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return "", err
 	}
 	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
-func Sha256FromContent(content string) (string, error) {
+func Sha256FromContent(ctx context.Context, content string) (string, error) {
 	if isUrl(content) {
-		return Sha256FromUrlContent(content)
+		return Sha256FromUrlContent(ctx, content)
 	}
 	h := sha256.New()
 	h.Write([]byte(content))
 	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
+// ReadContent returns the content, downloading it when the content is a URL
+func ReadContent(ctx context.Context, content string) (string, error) {
+	if !isUrl(content) {
+		return content, nil
+	}
+	ctx, span := tracer.Start(ctx, "ReadContent")
+	span.SetAttributes(attribute.String("url.full", content))
+	defer span.End()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, content, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+	resp, err := contentClient.Do(req)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf("failed to download %s: %s", content, resp.Status)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+	return string(body), nil
+}
+
 func isUrl(s string) bool {
 	_, err := url.ParseRequestURI(s)
 	return err == nil